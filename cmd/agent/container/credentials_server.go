@@ -33,8 +33,11 @@ type CredentialsServerCmd struct {
 	ConfigureGitHelper    bool
 	ConfigureDockerHelper bool
 
-	ForwardPorts      bool
-	GitUserSigningKey string
+	ForwardPorts        bool
+	ForwardPortsRange   string
+	ForwardPortsExclude []string
+	ForwardPortsAllow   []string
+	GitUserSigningKey   string
 }
 
 // NewCredentialsServerCmd creates a new command
@@ -58,6 +61,9 @@ func NewCredentialsServerCmd(flags *flags.GlobalFlags) *cobra.Command {
 	credentialsServerCmd.Flags().BoolVar(&cmd.ConfigureGitHelper, "configure-git-helper", false, "If true will configure git helper")
 	credentialsServerCmd.Flags().BoolVar(&cmd.ConfigureDockerHelper, "configure-docker-helper", false, "If true will configure docker helper")
 	credentialsServerCmd.Flags().BoolVar(&cmd.ForwardPorts, "forward-ports", false, "If true will automatically try to forward open ports within the container")
+	credentialsServerCmd.Flags().StringVar(&cmd.ForwardPortsRange, "forward-ports-range", "", "The range of ports to forward automatically, e.g. 1024-12000")
+	credentialsServerCmd.Flags().StringSliceVar(&cmd.ForwardPortsExclude, "forward-ports-exclude", []string{}, "Ports or port ranges that should never be forwarded automatically")
+	credentialsServerCmd.Flags().StringSliceVar(&cmd.ForwardPortsAllow, "forward-ports-allow", []string{}, "If set, only these ports or port ranges are forwarded automatically")
 	credentialsServerCmd.Flags().StringVar(&cmd.GitUserSigningKey, "git-user-signing-key", "", "")
 	credentialsServerCmd.Flags().StringVar(&cmd.User, "user", "", "The user to use")
 	_ = credentialsServerCmd.MarkFlagRequired("user")
@@ -84,9 +90,14 @@ func (cmd *CredentialsServerCmd) Run(ctx context.Context, port int) error {
 
 	// forward ports
 	if cmd.ForwardPorts {
+		filter, err := cmd.portFilter()
+		if err != nil {
+			return err
+		}
+
 		go func() {
 			log.Debugf("Start watching & forwarding open ports")
-			err = forwardPorts(ctx, tunnelClient, log)
+			err = forwardPorts(ctx, tunnelClient, filter, log)
 			if err != nil {
 				log.Errorf("error forwarding ports: %v", err)
 			}
@@ -190,8 +201,31 @@ func configureGitUserLocally(ctx context.Context, userName string, client tunnel
 	return nil
 }
 
-func forwardPorts(ctx context.Context, client tunnel.TunnelClient, log log.Logger) error {
-	return netstat.NewWatcher(&forwarder{ctx: ctx, client: client}, log).Run(ctx)
+func (cmd *CredentialsServerCmd) portFilter() (netstat.PortFilter, error) {
+	filter := netstat.DefaultPortFilter()
+	if cmd.ForwardPortsRange != "" {
+		portRange, err := portpkg.ParseRange(cmd.ForwardPortsRange)
+		if err != nil {
+			return filter, err
+		}
+		filter.Range = portRange
+	}
+
+	var err error
+	filter.Exclude, err = portpkg.ParseRanges(cmd.ForwardPortsExclude)
+	if err != nil {
+		return filter, err
+	}
+	filter.Allow, err = portpkg.ParseRanges(cmd.ForwardPortsAllow)
+	if err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+func forwardPorts(ctx context.Context, client tunnel.TunnelClient, filter netstat.PortFilter, log log.Logger) error {
+	return netstat.NewWatcher(&forwarder{ctx: ctx, client: client}, filter, log).Run(ctx)
 }
 
 type forwarder struct {
@@ -5,9 +5,11 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
 	"os/exec"
 	"os/signal"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
@@ -16,7 +18,9 @@ import (
 	"github.com/loft-sh/devpod/pkg/agent"
 	agentd "github.com/loft-sh/devpod/pkg/daemon/agent"
 	"github.com/loft-sh/devpod/pkg/devcontainer/config"
+	"github.com/loft-sh/devpod/pkg/passwd"
 	"github.com/loft-sh/devpod/pkg/platform/client"
+	devssh "github.com/loft-sh/devpod/pkg/ssh"
 	"github.com/loft-sh/devpod/pkg/ts"
 	"github.com/loft-sh/log"
 	"github.com/pkg/errors"
@@ -27,8 +31,15 @@ import (
 const (
 	RootDir          = "/var/devpod"
 	DaemonConfigPath = "/var/run/secrets/devpod/daemon_config"
+
+	DefaultShutdownGracePeriod = 5 * time.Minute
+
+	// stopContainerTimeout is the time the main process of the container gets to exit after SIGTERM
+	stopContainerTimeout = 30 * time.Second
 )
 
+var errShutdownAction = errors.New("last session disconnected, running shutdown action")
+
 type DaemonCmd struct {
 	Config *agentd.DaemonConfig
 	Log    log.Logger
@@ -47,6 +58,9 @@ func NewDaemonCmd() *cobra.Command {
 		RunE:  cmd.Run,
 	}
 	daemonCmd.Flags().StringVar(&cmd.Config.Timeout, "timeout", "", "The timeout to stop the container after")
+	daemonCmd.Flags().StringVar(&cmd.Config.ShutdownAction, "shutdown-action", "", "The action to run after the last session disconnected. Can be none, stopContainer or stopCompose")
+	daemonCmd.Flags().StringVar(&cmd.Config.ShutdownGracePeriod, "shutdown-grace-period", "", "The time to wait after the last session disconnected before running the shutdown action")
+	daemonCmd.Flags().StringVar(&cmd.Config.ShutdownUser, "shutdown-user", "", "The user the sessions run as")
 	return daemonCmd
 }
 
@@ -74,6 +88,16 @@ func (cmd *DaemonCmd) Run(c *cobra.Command, args []string) error {
 		}
 	}
 
+	// Prepare shutdown action if specified.
+	shutdownGracePeriod := DefaultShutdownGracePeriod
+	if cmd.Config.ShutdownGracePeriod != "" {
+		var err error
+		shutdownGracePeriod, err = time.ParseDuration(cmd.Config.ShutdownGracePeriod)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse shutdown grace period")
+		}
+	}
+
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
@@ -99,6 +123,13 @@ func (cmd *DaemonCmd) Run(c *cobra.Command, args []string) error {
 		go runTimeoutMonitor(ctx, timeoutDuration, errChan, &wg)
 	}
 
+	// Start shutdown action monitor.
+	if cmd.shouldRunShutdownAction() {
+		tasksStarted = true
+		wg.Add(1)
+		go runShutdownActionMonitor(ctx, shutdownGracePeriod, cmd.sessionsUser(), errChan, &wg, cmd.Log)
+	}
+
 	// Start ssh server.
 	if cmd.shouldRunSsh() {
 		tasksStarted = true
@@ -123,7 +154,14 @@ func (cmd *DaemonCmd) Run(c *cobra.Command, args []string) error {
 	cancel()
 	wg.Wait()
 
-	if err != nil {
+	if errors.Is(err, errShutdownAction) {
+		cmd.Log.Infof("Last session disconnected %s ago, running shutdown action %s", shutdownGracePeriod, cmd.Config.ShutdownAction)
+		if err := cmd.runShutdownAction(); err != nil {
+			cmd.Log.Errorf("Error running shutdown action %s: %v", cmd.Config.ShutdownAction, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	} else if err != nil {
 		cmd.Log.Errorf("Daemon error: %v", err)
 		os.Exit(1)
 	}
@@ -132,7 +170,7 @@ func (cmd *DaemonCmd) Run(c *cobra.Command, args []string) error {
 }
 
 // loadConfig loads the daemon configuration from base64-encoded JSON.
-// If a CLI-provided timeout exists, it will override the timeout in the config.
+// If a CLI-provided timeout or shutdown action exists, it will override the value in the config.
 func (cmd *DaemonCmd) loadConfig() error {
 	// check local file
 	encodedCfg := ""
@@ -160,6 +198,15 @@ func (cmd *DaemonCmd) loadConfig() error {
 		if cmd.Config.Timeout != "" {
 			cfg.Timeout = cmd.Config.Timeout
 		}
+		if cmd.Config.ShutdownAction != "" {
+			cfg.ShutdownAction = cmd.Config.ShutdownAction
+		}
+		if cmd.Config.ShutdownGracePeriod != "" {
+			cfg.ShutdownGracePeriod = cmd.Config.ShutdownGracePeriod
+		}
+		if cmd.Config.ShutdownUser != "" {
+			cfg.ShutdownUser = cmd.Config.ShutdownUser
+		}
 		cmd.Config = &cfg
 	}
 
@@ -178,6 +225,31 @@ func (cmd *DaemonCmd) shouldRunSsh() bool {
 	return cmd.Config.Ssh.Workdir != "" || cmd.Config.Ssh.User != ""
 }
 
+// shouldRunShutdownAction returns true if the container or compose project should be stopped after the last
+// session disconnected.
+func (cmd *DaemonCmd) shouldRunShutdownAction() bool {
+	return cmd.Config.ShutdownAction == config.ShutdownActionStopContainer || cmd.Config.ShutdownAction == config.ShutdownActionStopCompose
+}
+
+// sessionsUser returns the user the sessions run as, which needs access to the sessions socket.
+func (cmd *DaemonCmd) sessionsUser() string {
+	if cmd.Config.ShutdownUser != "" {
+		return cmd.Config.ShutdownUser
+	}
+
+	return cmd.Config.Ssh.User
+}
+
+// runShutdownAction stops the container. The other services of a compose project can only be stopped from the
+// host, so for stopCompose the daemon marks the shutdown for the host and exits.
+func (cmd *DaemonCmd) runShutdownAction() error {
+	if cmd.Config.ShutdownAction == config.ShutdownActionStopCompose {
+		return os.WriteFile(agent.ContainerShutdownActionFile, []byte(cmd.Config.ShutdownAction), 0o644)
+	}
+
+	return stopContainer()
+}
+
 // setupActivityFile creates and sets permissions on the container activity file.
 func setupActivityFile() error {
 	if err := os.WriteFile(agent.ContainerActivityFile, nil, 0777); err != nil {
@@ -215,6 +287,96 @@ func runTimeoutMonitor(ctx context.Context, duration time.Duration, errChan chan
 	}
 }
 
+// runShutdownActionMonitor tracks the ssh sessions within the container and signals errShutdownAction once the
+// last session disconnected and no new session was opened within the grace period.
+// The daemon keeps running without the shutdown action if the sessions can't be tracked.
+func runShutdownActionMonitor(ctx context.Context, gracePeriod time.Duration, user string, errChan chan<- error, wg *sync.WaitGroup, log log.Logger) {
+	defer wg.Done()
+
+	_ = os.Remove(agent.ContainerShutdownActionFile)
+	_ = os.Remove(agent.ContainerSessionsSocket)
+	listener, err := net.Listen("unix", agent.ContainerSessionsSocket)
+	if err != nil {
+		log.Warnf("Error listening for sessions, the shutdown action won't run: %v", err)
+		return
+	}
+	defer os.Remove(agent.ContainerSessionsSocket)
+
+	// sessions run as the remote user
+	err = chownSessionsSocket(user)
+	if err != nil {
+		log.Warnf("Error changing the owner of the sessions socket to %s, sessions of the user won't be tracked: %v", user, err)
+	}
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+
+	err = devssh.TrackSessions(ctx, listener, gracePeriod, func() {
+		select {
+		case errChan <- errShutdownAction:
+		default:
+		}
+	}, log)
+	if err != nil {
+		log.Warnf("Error tracking sessions, the shutdown action won't run: %v", err)
+	}
+}
+
+// chownSessionsSocket restricts the sessions socket to root and the given user
+func chownSessionsSocket(user string) error {
+	err := os.Chmod(agent.ContainerSessionsSocket, 0o600)
+	if err != nil {
+		return err
+	} else if user == "" || user == "root" {
+		return nil
+	}
+
+	passwdFile, err := os.Open("/etc/passwd")
+	if err != nil {
+		return err
+	}
+	defer passwdFile.Close()
+
+	uid, gid, ok := passwd.LookupUser(passwdFile, user)
+	if !ok {
+		return fmt.Errorf("user %s not found", user)
+	}
+	uidNumber, err := strconv.Atoi(uid)
+	if err != nil {
+		return err
+	}
+	gidNumber, err := strconv.Atoi(gid)
+	if err != nil {
+		return err
+	}
+
+	return os.Chown(agent.ContainerSessionsSocket, uidNumber, gidNumber)
+}
+
+// stopContainer stops the container by terminating its main process. If the daemon is the main process,
+// exiting is enough. Otherwise the main process only receives SIGTERM if it handles the signal, so stopping
+// fails if the container is still running after stopContainerTimeout.
+func stopContainer() error {
+	if os.Getpid() == 1 {
+		return nil
+	}
+
+	process, err := os.FindProcess(1)
+	if err != nil {
+		return err
+	}
+
+	err = process.Signal(syscall.SIGTERM)
+	if err != nil {
+		return err
+	}
+
+	// the daemon is killed together with the main process
+	time.Sleep(stopContainerTimeout)
+	return fmt.Errorf("the main process of the container ignored SIGTERM, it needs to handle the signal or the devcontainer.json needs to set overrideCommand")
+}
+
 // runNetworkServer starts the network server.
 func runNetworkServer(ctx context.Context, cmd *DaemonCmd, errChan chan<- error, wg *sync.WaitGroup) {
 	defer wg.Done()
@@ -0,0 +1,62 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/loft-sh/devpod/pkg/devcontainer/config"
+	"github.com/loft-sh/devpod/pkg/devcontainer/setup"
+	"github.com/loft-sh/log"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// LifecycleHooksAsyncCmd holds the cmd flags
+type LifecycleHooksAsyncCmd struct{}
+
+// NewLifecycleHooksAsyncCmd creates a new command
+func NewLifecycleHooksAsyncCmd() *cobra.Command {
+	cmd := &LifecycleHooksAsyncCmd{}
+	lifecycleHooksAsyncCmd := &cobra.Command{
+		Use:   "lifecycle-hooks-async",
+		Short: "Runs the lifecycle hooks after waitFor",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			// stop running hooks if the container is stopped
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer cancel()
+
+			return cmd.Run(ctx)
+		},
+	}
+	return lifecycleHooksAsyncCmd
+}
+
+// Run runs the command logic
+func (cmd *LifecycleHooksAsyncCmd) Run(ctx context.Context) error {
+	// the result is written by the container setup
+	rawResult, err := os.ReadFile(setup.ResultLocation)
+	if err != nil {
+		return fmt.Errorf("read setup result: %w", err)
+	}
+
+	setupInfo := &config.Result{}
+	err = json.Unmarshal(rawResult, setupInfo)
+	if err != nil {
+		return err
+	}
+
+	logger := log.NewStreamLogger(os.Stdout, os.Stderr, logrus.InfoLevel)
+	err = setup.RunBackgroundLifecycleHooks(ctx, setupInfo, logger)
+	if err != nil {
+		logger.Errorf("Error running lifecycle hooks: %v", err)
+		return err
+	}
+
+	logger.Done("Successfully ran lifecycle hooks")
+	return nil
+}
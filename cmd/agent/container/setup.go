@@ -180,9 +180,12 @@ func (cmd *SetupContainerCmd) Run(ctx context.Context) error {
 		if err == nil && !workspaceInfo.CLIOptions.Recreate {
 			logger.Debugf("Workspace repository already checked out %s, skipping clone", setupInfo.SubstitutionContext.ContainerWorkspaceFolder)
 		} else {
+			// a mirror inside the container would only be used once
+			agentConfig := workspaceInfo.Agent
+			agentConfig.GitMirror.Disabled = "true"
 			if err := agent.CloneRepositoryForWorkspace(ctx,
 				&workspaceInfo.Source,
-				&workspaceInfo.Agent,
+				&agentConfig,
 				setupInfo.SubstitutionContext.ContainerWorkspaceFolder,
 				"",
 				workspaceInfo.CLIOptions,
@@ -194,8 +197,13 @@ func (cmd *SetupContainerCmd) Run(ctx context.Context) error {
 		}
 	}
 
+	// failed lifecycle hooks of the last start run again
+	if hooksErr := setup.GetBackgroundLifecycleHooksError(); hooksErr != "" {
+		logger.Warnf("Lifecycle hooks that ran in the background failed: %s", hooksErr)
+	}
+
 	// setup container
-	err = setup.SetupContainer(ctx, setupInfo, workspaceInfo.CLIOptions.WorkspaceEnv, cmd.ChownWorkspace, &workspaceInfo.CLIOptions.Platform, tunnelClient, logger)
+	backgroundHooks, err := setup.SetupContainer(ctx, setupInfo, workspaceInfo.CLIOptions.WorkspaceEnv, cmd.ChownWorkspace, &workspaceInfo.CLIOptions.Platform, tunnelClient, logger)
 	if err != nil {
 		return err
 	}
@@ -207,21 +215,48 @@ func (cmd *SetupContainerCmd) Run(ctx context.Context) error {
 	}
 
 	// start container daemon if necessary
-	if !workspaceInfo.CLIOptions.Platform.Enabled && !workspaceInfo.CLIOptions.DisableDaemon && workspaceInfo.ContainerTimeout != "" {
+	shutdownAction := setupInfo.MergedConfig.ShutdownAction
+	if shutdownAction != config.ShutdownActionStopContainer && shutdownAction != config.ShutdownActionStopCompose {
+		shutdownAction = ""
+	}
+
+	// the host stops the compose project once the file exists, so it must not survive a restart
+	_ = os.Remove(agent.ContainerShutdownActionFile)
+	if !workspaceInfo.CLIOptions.Platform.Enabled && !workspaceInfo.CLIOptions.DisableDaemon && (workspaceInfo.ContainerTimeout != "" || shutdownAction != "") {
 		err = single.Single("devpod.daemon.pid", func() (*exec.Cmd, error) {
-			logger.Debugf("Start DevPod Container Daemon with Inactivity Timeout %s", workspaceInfo.ContainerTimeout)
+			logger.Debugf("Start DevPod Container Daemon with Inactivity Timeout %s and Shutdown Action %s", workspaceInfo.ContainerTimeout, shutdownAction)
 			binaryPath, err := os.Executable()
 			if err != nil {
 				return nil, err
 			}
 
-			return exec.Command(binaryPath, "agent", "container", "daemon", "--timeout", workspaceInfo.ContainerTimeout), nil
+			args := []string{"agent", "container", "daemon"}
+			if workspaceInfo.ContainerTimeout != "" {
+				args = append(args, "--timeout", workspaceInfo.ContainerTimeout)
+			}
+			if shutdownAction != "" {
+				args = append(args, "--shutdown-action", shutdownAction, "--shutdown-user", config.GetRemoteUser(setupInfo))
+				gracePeriod := config.GetMergedDevPodCustomizations(setupInfo.MergedConfig).ShutdownGracePeriod
+				if gracePeriod != "" {
+					args = append(args, "--shutdown-grace-period", gracePeriod)
+				}
+			}
+
+			return exec.Command(binaryPath, args...), nil
 		})
 		if err != nil {
 			return err
 		}
 	}
 
+	// run the remaining lifecycle hooks in the background
+	if backgroundHooks {
+		err = runBackgroundLifecycleHooks(logger)
+		if err != nil {
+			return errors.Wrap(err, "run lifecycle hooks in background")
+		}
+	}
+
 	out, err := json.Marshal(setupInfo)
 	if err != nil {
 		return fmt.Errorf("marshal setup info: %w", err)
@@ -235,6 +270,31 @@ func (cmd *SetupContainerCmd) Run(ctx context.Context) error {
 	return nil
 }
 
+func runBackgroundLifecycleHooks(log log.Logger) error {
+	return single.Single("devpod.lifecycle-hooks.pid", func() (*exec.Cmd, error) {
+		log.Infof("Run remaining lifecycle hooks in the background, output is written to %s", setup.LifecycleHooksLogFile)
+		binaryPath, err := os.Executable()
+		if err != nil {
+			return nil, err
+		}
+
+		err = os.MkdirAll(filepath.Dir(setup.LifecycleHooksLogFile), 0o755)
+		if err != nil {
+			return nil, err
+		}
+
+		logFile, err := os.Create(setup.LifecycleHooksLogFile)
+		if err != nil {
+			return nil, err
+		}
+
+		cmd := exec.Command(binaryPath, "agent", "container", "lifecycle-hooks-async")
+		cmd.Stdout = logFile
+		cmd.Stderr = logFile
+		return cmd, nil
+	})
+}
+
 func fillContainerEnv(setupInfo *config.Result) error {
 	// set remote-env
 	if setupInfo.MergedConfig.RemoteEnv == nil {
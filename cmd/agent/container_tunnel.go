@@ -69,6 +69,7 @@ func (cmd *ContainerTunnelCmd) Run(ctx context.Context, log log.Logger) error {
 	if err != nil {
 		return err
 	}
+	defer runner.Close()
 
 	// wait until devcontainer is started
 	err = startDevContainer(ctx, workspaceInfo, runner, log)
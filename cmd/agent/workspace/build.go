@@ -69,6 +69,7 @@ func (cmd *BuildCmd) Run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	defer runner.Close()
 
 	// if there is no platform specified, we use empty to let
 	// the builder find out itself.
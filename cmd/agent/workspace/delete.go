@@ -81,6 +81,7 @@ func removeContainer(ctx context.Context, workspaceInfo *provider2.AgentWorkspac
 	if err != nil {
 		return err
 	}
+	defer runner.Close()
 
 	if workspaceInfo.Workspace.Source.Container != "" {
 		log.Infof("Skipping container deletion, since it was not created by DevPod")
@@ -0,0 +1,61 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/loft-sh/devpod/cmd/flags"
+	"github.com/loft-sh/devpod/pkg/agent"
+	"github.com/loft-sh/devpod/pkg/driver"
+	"github.com/loft-sh/devpod/pkg/driver/drivercreate"
+	"github.com/loft-sh/log"
+	"github.com/spf13/cobra"
+)
+
+// GenerateKubeCmd holds the cmd flags
+type GenerateKubeCmd struct {
+	*flags.GlobalFlags
+
+	WorkspaceInfo string
+}
+
+// NewGenerateKubeCmd creates a new command
+func NewGenerateKubeCmd(flags *flags.GlobalFlags) *cobra.Command {
+	cmd := &GenerateKubeCmd{
+		GlobalFlags: flags,
+	}
+	generateKubeCmd := &cobra.Command{
+		Use:   "generate-kube",
+		Short: "Prints the kubernetes manifests of a workspace on the remote server",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return cmd.Run(context.Background())
+		},
+	}
+	generateKubeCmd.Flags().StringVar(&cmd.WorkspaceInfo, "workspace-info", "", "The workspace info")
+	_ = generateKubeCmd.MarkFlagRequired("workspace-info")
+	return generateKubeCmd
+}
+
+func (cmd *GenerateKubeCmd) Run(ctx context.Context) error {
+	// get workspace
+	shouldExit, workspaceInfo, err := agent.WriteWorkspaceInfo(cmd.WorkspaceInfo, log.Default.ErrorStreamOnly())
+	if err != nil {
+		return fmt.Errorf("error parsing workspace info: %w", err)
+	} else if shouldExit {
+		return nil
+	}
+
+	baseDriver, err := drivercreate.NewDriver(workspaceInfo, log.Default.ErrorStreamOnly())
+	if err != nil {
+		return err
+	}
+	defer driver.Close(baseDriver)
+	kubeExportDriver, ok := baseDriver.(driver.KubeExportDriver)
+	if !ok {
+		return fmt.Errorf("generating kubernetes manifests is not supported by the %s driver", workspaceInfo.Agent.Driver)
+	}
+
+	return kubeExportDriver.GenerateKube(ctx, workspaceInfo.Workspace.ID, os.Stdout)
+}
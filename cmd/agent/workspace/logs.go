@@ -1,15 +1,25 @@
 package workspace
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/loft-sh/devpod/cmd/flags"
 	"github.com/loft-sh/devpod/pkg/agent"
 	"github.com/loft-sh/devpod/pkg/devcontainer"
+	"github.com/loft-sh/devpod/pkg/devcontainer/setup"
+	"github.com/loft-sh/devpod/pkg/driver"
+	devpodlog "github.com/loft-sh/devpod/pkg/log"
 	"github.com/loft-sh/log"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
 )
 
 // LogsCmd holds the cmd flags
@@ -17,6 +27,12 @@ type LogsCmd struct {
 	*flags.GlobalFlags
 
 	ID string
+
+	Follow         bool
+	Since          string
+	Tail           int
+	Timestamps     bool
+	LifecycleHooks bool
 }
 
 // NewLogsCmd creates a new command
@@ -34,6 +50,11 @@ func NewLogsCmd(flags *flags.GlobalFlags) *cobra.Command {
 	}
 	c.Flags().StringVar(&cmd.ID, "id", "", "The workspace id")
 	_ = c.MarkFlagRequired("id")
+	c.Flags().BoolVarP(&cmd.Follow, "follow", "f", false, "Keep streaming new logs")
+	c.Flags().StringVar(&cmd.Since, "since", "", "Only show logs newer than a relative duration such as 10m or a RFC3339 timestamp")
+	c.Flags().IntVar(&cmd.Tail, "tail", -1, "Number of lines to show from the end of the logs, -1 shows all lines")
+	c.Flags().BoolVar(&cmd.Timestamps, "timestamps", false, "Show timestamps of the container logs")
+	c.Flags().BoolVar(&cmd.LifecycleHooks, "lifecycle-hooks", true, "Include the output of lifecycle hooks that run in the background")
 
 	return c
 }
@@ -53,7 +74,109 @@ func (cmd *LogsCmd) Run(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("create runner: %w", err)
 	}
+	defer runner.Close()
+
+	return cmd.Logs(ctx, runner, logger)
+}
+
+// Logs writes the devcontainer logs and the output of the lifecycle hooks of the runner to stdout
+func (cmd *LogsCmd) Logs(ctx context.Context, runner devcontainer.Runner, logger log.Logger) error {
+	logsOptions := &driver.LogsOptions{
+		Follow:     cmd.Follow,
+		Since:      cmd.Since,
+		Tail:       cmd.Tail,
+		Timestamps: cmd.Timestamps,
+	}
+	if cmd.Follow {
+		return cmd.followLogs(ctx, runner, logsOptions, logger)
+	}
 
 	// write devcontainer logs to stdout
-	return runner.Logs(ctx, os.Stdout)
+	err := runner.Logs(ctx, logsOptions, os.Stdout)
+	if err != nil {
+		return err
+	} else if !cmd.LifecycleHooks || !isRunning(ctx, runner) {
+		return nil
+	}
+
+	// write output of lifecycle hooks that ran in the background
+	command := fmt.Sprintf("if [ -f '%s' ]; then echo '--- Lifecycle hooks ---'; %s '%s'; fi", setup.LifecycleHooksLogFile, cmd.tailCommand(false), setup.LifecycleHooksLogFile)
+	err = runner.Command(ctx, "root", command, nil, os.Stdout, os.Stderr)
+	if err != nil {
+		logger.Debugf("Error reading lifecycle hooks log: %v", err)
+	}
+
+	warnLifecycleHooksError(ctx, runner, logger)
+	return nil
+}
+
+// followLogs streams the devcontainer logs and interleaves them line by line with the output of the lifecycle hooks
+func (cmd *LogsCmd) followLogs(ctx context.Context, runner devcontainer.Runner, logsOptions *driver.LogsOptions, logger log.Logger) error {
+	m := &sync.Mutex{}
+	containerWriter := devpodlog.NewLineWriter(os.Stdout, m, "")
+	defer containerWriter.Close()
+
+	if !cmd.LifecycleHooks || !isRunning(ctx, runner) {
+		return runner.Logs(ctx, logsOptions, containerWriter)
+	}
+
+	warnLifecycleHooksError(ctx, runner, logger)
+
+	// stop following the lifecycle hooks once the container logs end
+	cancelCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs, errsCtx := errgroup.WithContext(cancelCtx)
+	errs.Go(func() error {
+		defer cancel()
+		return runner.Logs(errsCtx, logsOptions, containerWriter)
+	})
+	errs.Go(func() error {
+		hooksWriter := devpodlog.NewLineWriter(os.Stdout, m, "[lifecycle] ")
+		defer hooksWriter.Close()
+		stderr := logger.Writer(logrus.DebugLevel, false)
+		defer stderr.Close()
+
+		command := fmt.Sprintf("%s '%s'", cmd.tailCommand(true), setup.LifecycleHooksLogFile)
+		err := runner.Command(errsCtx, "root", command, nil, hooksWriter, stderr)
+		if err != nil && errsCtx.Err() == nil {
+			logger.Debugf("Error following lifecycle hooks log: %v", err)
+		}
+
+		return nil
+	})
+
+	return errs.Wait()
+}
+
+func (cmd *LogsCmd) tailCommand(follow bool) string {
+	lines := "+1"
+	if cmd.Tail >= 0 {
+		lines = strconv.Itoa(cmd.Tail)
+	}
+
+	command := "tail -n " + lines
+	if follow {
+		// -F keeps following the file if it is recreated by the next devpod up
+		command += " -F"
+	}
+
+	return command
+}
+
+// warnLifecycleHooksError prints the error of the lifecycle hooks that ran in the background, if they failed
+func warnLifecycleHooksError(ctx context.Context, runner devcontainer.Runner, logger log.Logger) {
+	stdout := &bytes.Buffer{}
+	command := fmt.Sprintf("if [ -f '%s' ]; then cat '%s'; fi", setup.LifecycleHooksErrorFile, setup.LifecycleHooksErrorFile)
+	err := runner.Command(ctx, "root", command, nil, stdout, io.Discard)
+	if err != nil {
+		logger.Debugf("Error reading lifecycle hooks error: %v", err)
+	} else if hooksErr := strings.TrimSpace(stdout.String()); hooksErr != "" {
+		logger.Warnf("Lifecycle hooks that ran in the background failed: %s", hooksErr)
+	}
+}
+
+func isRunning(ctx context.Context, runner devcontainer.Runner) bool {
+	containerDetails, err := runner.Find(ctx)
+	return err == nil && containerDetails != nil && strings.ToLower(containerDetails.State.Status) == "running"
 }
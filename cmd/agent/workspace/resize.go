@@ -0,0 +1,67 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/loft-sh/devpod/cmd/flags"
+	"github.com/loft-sh/devpod/pkg/agent"
+	"github.com/loft-sh/devpod/pkg/driver"
+	"github.com/loft-sh/devpod/pkg/driver/drivercreate"
+	"github.com/loft-sh/log"
+	"github.com/spf13/cobra"
+)
+
+// ResizeCmd holds the cmd flags
+type ResizeCmd struct {
+	*flags.GlobalFlags
+
+	WorkspaceInfo string
+	CPUs          float64
+	Memory        int64
+}
+
+// NewResizeCmd creates a new command
+func NewResizeCmd(flags *flags.GlobalFlags) *cobra.Command {
+	cmd := &ResizeCmd{
+		GlobalFlags: flags,
+	}
+	resizeCmd := &cobra.Command{
+		Use:   "resize",
+		Short: "Changes the cpus and memory of a workspace on the remote server",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return cmd.Run(context.Background())
+		},
+	}
+	resizeCmd.Flags().StringVar(&cmd.WorkspaceInfo, "workspace-info", "", "The workspace info")
+	resizeCmd.Flags().Float64Var(&cmd.CPUs, "cpus", 0, "The new number of cpus")
+	resizeCmd.Flags().Int64Var(&cmd.Memory, "memory", 0, "The new memory in bytes")
+	_ = resizeCmd.MarkFlagRequired("workspace-info")
+	return resizeCmd
+}
+
+func (cmd *ResizeCmd) Run(ctx context.Context) error {
+	// get workspace
+	shouldExit, workspaceInfo, err := agent.WriteWorkspaceInfo(cmd.WorkspaceInfo, log.Default.ErrorStreamOnly())
+	if err != nil {
+		return fmt.Errorf("error parsing workspace info: %w", err)
+	} else if shouldExit {
+		return nil
+	}
+
+	baseDriver, err := drivercreate.NewDriver(workspaceInfo, log.Default)
+	if err != nil {
+		return err
+	}
+	defer driver.Close(baseDriver)
+	resizeDriver, ok := baseDriver.(driver.ResizeDriver)
+	if !ok {
+		return fmt.Errorf("resizing workspaces is not supported by the %s driver", workspaceInfo.Agent.Driver)
+	}
+
+	return resizeDriver.ResizeDevContainer(ctx, workspaceInfo.Workspace.ID, &driver.ResizeOptions{
+		CPUs:   cmd.CPUs,
+		Memory: cmd.Memory,
+	})
+}
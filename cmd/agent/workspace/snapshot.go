@@ -0,0 +1,142 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/loft-sh/devpod/cmd/flags"
+	"github.com/loft-sh/devpod/pkg/agent"
+	"github.com/loft-sh/devpod/pkg/driver"
+	"github.com/loft-sh/devpod/pkg/driver/drivercreate"
+	provider2 "github.com/loft-sh/devpod/pkg/provider"
+	"github.com/loft-sh/log"
+	"github.com/spf13/cobra"
+)
+
+// SnapshotCmd holds the cmd flags
+type SnapshotCmd struct {
+	*flags.GlobalFlags
+
+	WorkspaceInfo     string
+	Name              string
+	SourceWorkspaceID string
+}
+
+// NewSnapshotCmd creates a new command
+func NewSnapshotCmd(flags *flags.GlobalFlags) *cobra.Command {
+	snapshotCmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Manages the snapshots of a workspace on the remote server",
+	}
+
+	for _, action := range []string{"create", "list", "delete", "restore"} {
+		cmd := &SnapshotCmd{
+			GlobalFlags: flags,
+		}
+		action := action
+		actionCmd := &cobra.Command{
+			Use:   action,
+			Short: fmt.Sprintf("Runs snapshot %s on the remote server", action),
+			Args:  cobra.NoArgs,
+			RunE: func(_ *cobra.Command, _ []string) error {
+				return cmd.Run(context.Background(), action)
+			},
+		}
+		actionCmd.Flags().StringVar(&cmd.WorkspaceInfo, "workspace-info", "", "The workspace info")
+		_ = actionCmd.MarkFlagRequired("workspace-info")
+		if action != "list" {
+			actionCmd.Flags().StringVar(&cmd.Name, "name", "", "The snapshot name")
+			_ = actionCmd.MarkFlagRequired("name")
+		}
+		if action == "restore" {
+			actionCmd.Flags().StringVar(&cmd.SourceWorkspaceID, "source-id", "", "The id of the workspace the snapshot was taken from")
+			_ = actionCmd.MarkFlagRequired("source-id")
+		}
+
+		snapshotCmd.AddCommand(actionCmd)
+	}
+
+	return snapshotCmd
+}
+
+func (cmd *SnapshotCmd) Run(ctx context.Context, action string) error {
+	// get workspace
+	shouldExit, workspaceInfo, err := agent.WriteWorkspaceInfo(cmd.WorkspaceInfo, log.Default.ErrorStreamOnly())
+	if err != nil {
+		return fmt.Errorf("error parsing workspace info: %w", err)
+	} else if shouldExit {
+		return nil
+	}
+	logger := log.Default.ErrorStreamOnly()
+
+	baseDriver, err := drivercreate.NewDriver(workspaceInfo, logger)
+	if err != nil {
+		return err
+	}
+	defer driver.Close(baseDriver)
+	snapshotDriver, ok := baseDriver.(driver.SnapshotDriver)
+	if !ok {
+		return fmt.Errorf("snapshots are not supported by the %s driver", workspaceInfo.Agent.Driver)
+	}
+
+	workspaceID := workspaceInfo.Workspace.ID
+	options, err := cmd.snapshotOptions(workspaceInfo)
+	if err != nil {
+		return err
+	}
+
+	var out interface{}
+	switch action {
+	case "create":
+		out, err = snapshotDriver.CreateSnapshot(ctx, workspaceID, options)
+	case "list":
+		out, err = snapshotDriver.ListSnapshots(ctx, workspaceID, options)
+	case "delete":
+		err = snapshotDriver.DeleteSnapshot(ctx, workspaceID, options)
+	case "restore":
+		out, err = snapshotDriver.RestoreSnapshot(ctx, workspaceID, options)
+	default:
+		err = fmt.Errorf("unknown snapshot action %s", action)
+	}
+	if err != nil || out == nil {
+		return err
+	}
+
+	return json.NewEncoder(os.Stdout).Encode(out)
+}
+
+func (cmd *SnapshotCmd) snapshotOptions(workspaceInfo *provider2.AgentWorkspaceInfo) (*driver.SnapshotOptions, error) {
+	options := &driver.SnapshotOptions{
+		Name:              cmd.Name,
+		SourceWorkspaceID: cmd.SourceWorkspaceID,
+		WorkspaceUID:      workspaceInfo.Workspace.UID,
+		WorkspaceFolder:   workspaceInfo.ContentFolder,
+	}
+	if options.Name != "" {
+		err := driver.ValidateSnapshotName(options.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the archive of a restore is the one of the workspace the snapshot was taken from
+	archiveWorkspaceID := workspaceInfo.Workspace.ID
+	if options.SourceWorkspaceID != "" {
+		archiveWorkspaceID = options.SourceWorkspaceID
+
+		// never overwrite a local folder that is mounted directly
+		if workspaceInfo.ContentFolder == workspaceInfo.Workspace.Source.LocalFolder {
+			options.WorkspaceFolder = ""
+		}
+	}
+
+	archiveDir, err := agent.GetAgentSnapshotsDir(workspaceInfo.Agent.DataPath, workspaceInfo.Workspace.Context, archiveWorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	options.ArchiveDir = archiveDir
+
+	return options, nil
+}
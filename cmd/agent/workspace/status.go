@@ -51,6 +51,7 @@ func (cmd *StatusCmd) Run(ctx context.Context, log log.Logger) error {
 	if err != nil {
 		return err
 	}
+	defer runner.Close()
 
 	// find dev container
 	containerDetails, err := runner.Find(ctx)
@@ -61,6 +61,7 @@ func stopContainer(ctx context.Context, workspaceInfo *provider2.AgentWorkspaceI
 	if err != nil {
 		return err
 	}
+	defer runner.Close()
 
 	err = runner.Stop(ctx)
 	if err != nil {
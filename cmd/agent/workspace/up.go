@@ -126,6 +126,7 @@ func (cmd *UpCmd) devPodUp(ctx context.Context, workspaceInfo *provider2.AgentWo
 	if err != nil {
 		return nil, err
 	}
+	defer runner.Close()
 
 	// start the devcontainer
 	result, err := runner.Up(ctx, devcontainer.UpOptions{
@@ -22,5 +22,8 @@ func NewWorkspaceCmd(flags *flags.GlobalFlags) *cobra.Command {
 	workspaceCmd.AddCommand(NewInstallDotfilesCmd(flags))
 	workspaceCmd.AddCommand(NewSetupGPGCmd(flags))
 	workspaceCmd.AddCommand(NewLogsCmd(flags))
+	workspaceCmd.AddCommand(NewSnapshotCmd(flags))
+	workspaceCmd.AddCommand(NewResizeCmd(flags))
+	workspaceCmd.AddCommand(NewGenerateKubeCmd(flags))
 	return workspaceCmd
 }
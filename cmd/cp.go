@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path"
+
+	"github.com/loft-sh/devpod/cmd/completion"
+	"github.com/loft-sh/devpod/cmd/flags"
+	"github.com/loft-sh/devpod/pkg/config"
+	"github.com/loft-sh/devpod/pkg/provider"
+	"github.com/loft-sh/devpod/pkg/remotecopy"
+	devssh "github.com/loft-sh/devpod/pkg/ssh"
+	"github.com/loft-sh/devpod/pkg/tunnel"
+	"github.com/loft-sh/devpod/pkg/workspace"
+	"github.com/loft-sh/log"
+	"github.com/pkg/sftp"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+)
+
+// CpCmd holds the cp cmd flags
+type CpCmd struct {
+	*flags.GlobalFlags
+
+	Recursive bool
+	User      string
+}
+
+// NewCpCmd creates a new cp command
+func NewCpCmd(flags *flags.GlobalFlags) *cobra.Command {
+	cmd := &CpCmd{
+		GlobalFlags: flags,
+	}
+	cpCmd := &cobra.Command{
+		Use:   "cp [flags] <source> <destination>",
+		Short: "Copies files and folders between the local machine and a workspace",
+		Long: `Copies files and folders between the local machine and the container of a workspace.
+One of source and destination has to be in the format workspace:path, relative paths in the
+container are resolved against the workspace folder. Permissions and modification times are
+preserved and files copied into the container are owned by the remote user.
+
+Examples:
+  devpod cp ./config.json my-workspace:config.json
+  devpod cp -r my-workspace:/var/log/app ./logs`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			ctx, cancel := signal.NotifyContext(cobraCmd.Context(), os.Interrupt)
+			defer cancel()
+
+			return cmd.Run(ctx, args[0], args[1])
+		},
+		ValidArgsFunction: func(rootCmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return completion.GetWorkspaceSuggestions(rootCmd, cmd.Context, cmd.Provider, nil, toComplete, cmd.Owner, log.Default)
+		},
+	}
+
+	cpCmd.Flags().BoolVarP(&cmd.Recursive, "recursive", "r", false, "Copy folders recursively")
+	cpCmd.Flags().StringVar(&cmd.User, "user", "", "The user that owns the files copied into the container, defaults to the remote user of the workspace")
+	return cpCmd
+}
+
+// Run runs the command logic
+func (cmd *CpCmd) Run(ctx context.Context, src, dst string) error {
+	srcWorkspace, srcPath, srcRemote := remotecopy.ParsePath(src)
+	dstWorkspace, dstPath, dstRemote := remotecopy.ParsePath(dst)
+	if srcRemote == dstRemote {
+		return fmt.Errorf("either source or destination has to be in the format workspace:path")
+	}
+	workspaceName := srcWorkspace
+	if dstRemote {
+		workspaceName = dstWorkspace
+	}
+
+	devPodConfig, err := config.LoadConfig(cmd.Context, cmd.Provider)
+	if err != nil {
+		return err
+	}
+
+	client, err := workspace.Get(ctx, devPodConfig, []string{workspaceName}, true, cmd.Owner, false, log.Default)
+	if err != nil {
+		return err
+	}
+
+	options := remotecopy.Options{
+		Recursive: cmd.Recursive,
+		User:      cmd.User,
+	}
+	if options.User == "" {
+		options.User, err = devssh.GetUser(client.WorkspaceConfig().ID, client.WorkspaceConfig().SSHConfigPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tunnel.WithContainer(ctx, devPodConfig, client, log.Default, func(ctx context.Context, containerClient *ssh.Client) error {
+		sftpClient, err := sftp.NewClient(containerClient)
+		if err != nil {
+			return fmt.Errorf("start sftp client: %w", err)
+		}
+		defer sftpClient.Close()
+
+		if dstRemote {
+			return remotecopy.Upload(ctx, sftpClient, srcPath, resolveRemotePath(client.WorkspaceConfig(), dstPath), options, log.Default)
+		}
+
+		return remotecopy.Download(ctx, sftpClient, resolveRemotePath(client.WorkspaceConfig(), srcPath), dstPath, options, log.Default)
+	})
+}
+
+// resolveRemotePath resolves relative paths against the workspace folder in the container
+func resolveRemotePath(workspaceConfig *provider.Workspace, remotePath string) string {
+	if path.IsAbs(remotePath) {
+		return remotePath
+	}
+
+	result, err := provider.LoadWorkspaceResult(workspaceConfig.Context, workspaceConfig.ID)
+	if err != nil || result == nil || result.SubstitutionContext == nil || result.SubstitutionContext.ContainerWorkspaceFolder == "" {
+		return path.Clean(remotePath)
+	}
+
+	return path.Join(result.SubstitutionContext.ContainerWorkspaceFolder, remotePath)
+}
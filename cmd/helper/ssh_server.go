@@ -100,6 +100,14 @@ func (cmd *SSHServerCmd) Run(_ *cobra.Command, _ []string) error {
 	// should we listen on stdout & stdin?
 	if cmd.Stdio {
 		if cmd.TrackActivity {
+			// register the session so the container daemon knows when the last session disconnected
+			unregister, err := agent.RegisterContainerSession()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error registering session: %v\n", err)
+			} else {
+				defer unregister()
+			}
+
 			go func() {
 				_, err = os.Stat(agent.ContainerActivityFile)
 				if err != nil {
@@ -0,0 +1,79 @@
+package helper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/loft-sh/devpod/pkg/agent"
+	"github.com/loft-sh/devpod/pkg/compose"
+	"github.com/loft-sh/devpod/pkg/docker"
+	"github.com/spf13/cobra"
+)
+
+type WatchComposeShutdownCmd struct {
+	DockerCommand  string
+	ComposeCommand string
+	ComposeArgs    []string
+	ProjectName    string
+	ContainerID    string
+	Interval       time.Duration
+}
+
+// NewWatchComposeShutdownCmd creates a new command
+func NewWatchComposeShutdownCmd() *cobra.Command {
+	cmd := &WatchComposeShutdownCmd{}
+	watchCmd := &cobra.Command{
+		Use:   "watch-compose-shutdown [compose args...]",
+		Short: "Stops the compose project once the dev container ran its shutdown action or stopped",
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(cobraCmd.Context(), args)
+		},
+	}
+
+	watchCmd.Flags().StringVar(&cmd.DockerCommand, "docker-command", compose.DockerCommand, "The docker command to use")
+	watchCmd.Flags().StringVar(&cmd.ComposeCommand, "compose-command", compose.DockerComposeCommand, "The docker compose command to use")
+	watchCmd.Flags().StringArrayVar(&cmd.ComposeArgs, "compose-arg", []string{}, "Arguments the docker compose command needs, e.g. compose")
+	watchCmd.Flags().StringVar(&cmd.ProjectName, "project-name", "", "The name of the compose project")
+	watchCmd.Flags().StringVar(&cmd.ContainerID, "container-id", "", "The id of the dev container")
+	watchCmd.Flags().DurationVar(&cmd.Interval, "interval", 30*time.Second, "How often to check the dev container")
+	_ = watchCmd.MarkFlagRequired("project-name")
+	_ = watchCmd.MarkFlagRequired("container-id")
+	return watchCmd
+}
+
+func (cmd *WatchComposeShutdownCmd) Run(ctx context.Context, args []string) error {
+	dockerHelper := &docker.DockerHelper{DockerCommand: cmd.DockerCommand}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(cmd.Interval):
+		}
+
+		// the container is gone, e.g. because the workspace was deleted or recreated
+		stdout := &bytes.Buffer{}
+		err := dockerHelper.Run(ctx, []string{"inspect", "--format", "{{.State.Running}}", cmd.ContainerID}, nil, stdout, nil)
+		if err != nil {
+			return nil
+		}
+
+		// the dev container stopped itself or the shutdown action file exists
+		if strings.TrimSpace(stdout.String()) == "true" {
+			err = dockerHelper.Run(ctx, []string{"exec", cmd.ContainerID, "test", "-f", agent.ContainerShutdownActionFile}, nil, nil, nil)
+			if err != nil {
+				continue
+			}
+		}
+
+		composeHelper := &compose.ComposeHelper{Command: cmd.ComposeCommand, Args: cmd.ComposeArgs}
+		err = composeHelper.Stop(ctx, cmd.ProjectName, args)
+		if err != nil {
+			return fmt.Errorf("stop compose project %s: %w", cmd.ProjectName, err)
+		}
+
+		return nil
+	}
+}
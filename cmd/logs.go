@@ -2,15 +2,22 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"time"
 
+	workspace2 "github.com/loft-sh/devpod/cmd/agent/workspace"
 	"github.com/loft-sh/devpod/cmd/completion"
 	"github.com/loft-sh/devpod/cmd/flags"
 	"github.com/loft-sh/devpod/pkg/agent"
 	clientpkg "github.com/loft-sh/devpod/pkg/client"
 	"github.com/loft-sh/devpod/pkg/config"
+	"github.com/loft-sh/devpod/pkg/devcontainer"
+	"github.com/loft-sh/devpod/pkg/driver"
+	"github.com/loft-sh/devpod/pkg/driver/kubernetes"
+	"github.com/loft-sh/devpod/pkg/provider"
 	"github.com/loft-sh/devpod/pkg/ssh"
 	"github.com/loft-sh/devpod/pkg/workspace"
 	"github.com/loft-sh/log"
@@ -21,6 +28,12 @@ import (
 // LogsCmd holds the configuration
 type LogsCmd struct {
 	*flags.GlobalFlags
+
+	Follow         bool
+	Since          string
+	Tail           int
+	Timestamps     bool
+	LifecycleHooks bool
 }
 
 // NewLogsCmd creates a new destroy command
@@ -38,12 +51,24 @@ func NewLogsCmd(flags *flags.GlobalFlags) *cobra.Command {
 			return completion.GetWorkspaceSuggestions(rootCmd, cmd.Context, cmd.Provider, args, toComplete, cmd.Owner, log.Default)
 		},
 	}
+	startCmd.Flags().BoolVarP(&cmd.Follow, "follow", "f", false, "Keep streaming new logs")
+	startCmd.Flags().StringVar(&cmd.Since, "since", "", "Only show logs newer than a relative duration such as 10m or a RFC3339 timestamp")
+	startCmd.Flags().IntVar(&cmd.Tail, "tail", -1, "Number of lines to show from the end of the logs, -1 shows all lines")
+	startCmd.Flags().BoolVar(&cmd.Timestamps, "timestamps", false, "Show timestamps of the container logs")
+	startCmd.Flags().BoolVar(&cmd.LifecycleHooks, "lifecycle-hooks", true, "Include the output of lifecycle hooks, with --follow they are interleaved with the container logs")
 
 	return startCmd
 }
 
 // Run runs the command logic
 func (cmd *LogsCmd) Run(ctx context.Context, args []string) error {
+	if cmd.Since != "" {
+		_, err := driver.ParseLogsSince(cmd.Since, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
 	devPodConfig, err := config.LoadConfig(cmd.Context, cmd.Provider)
 	if err != nil {
 		return err
@@ -60,6 +85,17 @@ func (cmd *LogsCmd) Run(ctx context.Context, args []string) error {
 	}
 	log := log.Default
 
+	// read the logs through the kubernetes api without injecting the agent if possible
+	_, agentInfo, err := client.AgentInfo(provider.CLIOptions{})
+	if err == nil && kubernetes.NativeConnectEnabled(&agentInfo.Agent) {
+		err = cmd.nativeLogs(ctx, agentInfo, log)
+		if !errors.Is(err, errNativeLogsUnavailable) {
+			return err
+		}
+
+		log.Warnf("%v, falling back to ssh", err)
+	}
+
 	// create readers
 	stdoutReader, stdoutWriter, err := os.Pipe()
 	if err != nil {
@@ -112,6 +148,7 @@ func (cmd *LogsCmd) Run(ctx context.Context, args []string) error {
 	if log.GetLevel() == logrus.DebugLevel {
 		agentCommand += " --debug"
 	}
+	agentCommand += cmd.agentFlags()
 
 	// create new ssh client
 	// start ssh client as root / default user
@@ -136,3 +173,51 @@ func (cmd *LogsCmd) Run(ctx context.Context, args []string) error {
 
 	return nil
 }
+
+var errNativeLogsUnavailable = errors.New("kubernetes api unavailable")
+
+// nativeLogs reads the logs on the cli through the kubernetes driver, it returns errNativeLogsUnavailable if the
+// cluster can't be reached
+func (cmd *LogsCmd) nativeLogs(ctx context.Context, agentInfo *provider.AgentWorkspaceInfo, log log.Logger) error {
+	runner, err := devcontainer.NewRunner(agent.ContainerDevPodHelperLocation, agent.DefaultAgentDownloadURL(), agentInfo, log.ErrorStreamOnly())
+	if err != nil {
+		return fmt.Errorf("%w: %w", errNativeLogsUnavailable, err)
+	}
+	defer runner.Close()
+	_, err = runner.Find(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errNativeLogsUnavailable, err)
+	}
+
+	logsCmd := &workspace2.LogsCmd{
+		GlobalFlags:    cmd.GlobalFlags,
+		Follow:         cmd.Follow,
+		Since:          cmd.Since,
+		Tail:           cmd.Tail,
+		Timestamps:     cmd.Timestamps,
+		LifecycleHooks: cmd.LifecycleHooks,
+	}
+	return logsCmd.Logs(ctx, runner, log.ErrorStreamOnly())
+}
+
+// agentFlags passes the log options on to the agent
+func (cmd *LogsCmd) agentFlags() string {
+	agentFlags := ""
+	if cmd.Follow {
+		agentFlags += " --follow"
+	}
+	if cmd.Since != "" {
+		agentFlags += fmt.Sprintf(" --since '%s'", cmd.Since)
+	}
+	if cmd.Tail >= 0 {
+		agentFlags += fmt.Sprintf(" --tail %d", cmd.Tail)
+	}
+	if cmd.Timestamps {
+		agentFlags += " --timestamps"
+	}
+	if !cmd.LifecycleHooks {
+		agentFlags += " --lifecycle-hooks=false"
+	}
+
+	return agentFlags
+}
@@ -0,0 +1,48 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/loft-sh/devpod/cmd/flags"
+	"github.com/loft-sh/devpod/pkg/tunnel"
+	"github.com/loft-sh/log"
+	"github.com/spf13/cobra"
+)
+
+// ForwardCmd holds the forward cmd flags
+type ForwardCmd struct {
+	*flags.GlobalFlags
+}
+
+// NewForwardCmd creates a new command
+func NewForwardCmd(flags *flags.GlobalFlags) *cobra.Command {
+	cmd := &ForwardCmd{
+		GlobalFlags: flags,
+	}
+	forwardCmd := &cobra.Command{
+		Use:   "forward [flags] [workspace-path|workspace-name] [local-port:]remote-port",
+		Short: "Forwards a port within an active session of the workspace",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(cobraCmd.Context(), args[:len(args)-1], args[len(args)-1])
+		},
+	}
+
+	return forwardCmd
+}
+
+// Run runs the command logic
+func (cmd *ForwardCmd) Run(ctx context.Context, args []string, port string) error {
+	workspace, err := getWorkspace(ctx, cmd.GlobalFlags, args)
+	if err != nil {
+		return err
+	}
+
+	err = tunnel.ForwardPort(ctx, workspace, port)
+	if err != nil {
+		return err
+	}
+
+	log.Default.Donef("Successfully forwarded port %s", port)
+	return nil
+}
@@ -0,0 +1,87 @@
+package ports
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/docker/go-units"
+	"github.com/loft-sh/devpod/cmd/completion"
+	"github.com/loft-sh/devpod/cmd/flags"
+	"github.com/loft-sh/devpod/pkg/tunnel"
+	"github.com/loft-sh/log"
+	"github.com/loft-sh/log/table"
+	"github.com/spf13/cobra"
+)
+
+// ListCmd holds the list cmd flags
+type ListCmd struct {
+	*flags.GlobalFlags
+
+	Output string
+}
+
+// NewListCmd creates a new command
+func NewListCmd(flags *flags.GlobalFlags) *cobra.Command {
+	cmd := &ListCmd{
+		GlobalFlags: flags,
+	}
+	listCmd := &cobra.Command{
+		Use:     "list [flags] [workspace-path|workspace-name]",
+		Aliases: []string{"ls"},
+		Short:   "Lists the forwarded ports of a workspace",
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(cobraCmd.Context(), args)
+		},
+		ValidArgsFunction: func(rootCmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return completion.GetWorkspaceSuggestions(rootCmd, cmd.Context, cmd.Provider, args, toComplete, cmd.Owner, log.Default)
+		},
+	}
+
+	listCmd.Flags().StringVar(&cmd.Output, "output", "plain", "The output format to use. Can be json or plain")
+	return listCmd
+}
+
+// Run runs the command logic
+func (cmd *ListCmd) Run(ctx context.Context, args []string) error {
+	workspace, err := getWorkspace(ctx, cmd.GlobalFlags, args)
+	if err != nil {
+		return err
+	}
+
+	ports, err := tunnel.ListForwardedPorts(ctx, workspace)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Output == "plain" {
+		tableEntries := [][]string{}
+		for _, port := range ports {
+			tableEntries = append(tableEntries, []string{
+				port.LocalAddress,
+				port.RemoteAddress,
+				port.Label,
+				port.Origin,
+				units.HumanSize(float64(port.BytesTransferred)),
+			})
+		}
+
+		table.PrintTable(log.Default, []string{
+			"Local Address",
+			"Remote Address",
+			"Label",
+			"Origin",
+			"Transferred",
+		}, tableEntries)
+	} else if cmd.Output == "json" {
+		out, err := json.MarshalIndent(ports, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(out))
+	} else {
+		return fmt.Errorf("unexpected output format, choose either json or plain. Got %s", cmd.Output)
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/loft-sh/devpod/cmd/flags"
+	"github.com/loft-sh/devpod/pkg/config"
+	"github.com/loft-sh/devpod/pkg/provider"
+	"github.com/loft-sh/devpod/pkg/workspace"
+	"github.com/loft-sh/log"
+	"github.com/spf13/cobra"
+)
+
+// NewPortsCmd returns a new command
+func NewPortsCmd(flags *flags.GlobalFlags) *cobra.Command {
+	portsCmd := &cobra.Command{
+		Use:   "ports",
+		Short: "DevPod port forwarding commands",
+	}
+
+	portsCmd.AddCommand(NewListCmd(flags))
+	portsCmd.AddCommand(NewForwardCmd(flags))
+	portsCmd.AddCommand(NewStopCmd(flags))
+	return portsCmd
+}
+
+func getWorkspace(ctx context.Context, globalFlags *flags.GlobalFlags, args []string) (*provider.Workspace, error) {
+	devPodConfig, err := config.LoadConfig(globalFlags.Context, globalFlags.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := workspace.Get(ctx, devPodConfig, args, false, globalFlags.Owner, false, log.Default.ErrorStreamOnly())
+	if err != nil {
+		return nil, err
+	}
+
+	return client.WorkspaceConfig(), nil
+}
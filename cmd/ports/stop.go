@@ -0,0 +1,48 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/loft-sh/devpod/cmd/flags"
+	"github.com/loft-sh/devpod/pkg/tunnel"
+	"github.com/loft-sh/log"
+	"github.com/spf13/cobra"
+)
+
+// StopCmd holds the stop cmd flags
+type StopCmd struct {
+	*flags.GlobalFlags
+}
+
+// NewStopCmd creates a new command
+func NewStopCmd(flags *flags.GlobalFlags) *cobra.Command {
+	cmd := &StopCmd{
+		GlobalFlags: flags,
+	}
+	stopCmd := &cobra.Command{
+		Use:   "stop [flags] [workspace-path|workspace-name] port|address",
+		Short: "Stops forwarding a port in all active sessions of the workspace",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(cobraCmd.Context(), args[:len(args)-1], args[len(args)-1])
+		},
+	}
+
+	return stopCmd
+}
+
+// Run runs the command logic
+func (cmd *StopCmd) Run(ctx context.Context, args []string, port string) error {
+	workspace, err := getWorkspace(ctx, cmd.GlobalFlags, args)
+	if err != nil {
+		return err
+	}
+
+	err = tunnel.StopForwardedPort(ctx, workspace, port)
+	if err != nil {
+		return err
+	}
+
+	log.Default.Donef("Successfully stopped forwarding port %s", port)
+	return nil
+}
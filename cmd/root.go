@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"os/exec"
 
 	"github.com/loft-sh/devpod/cmd/agent"
 	"github.com/loft-sh/devpod/cmd/completion"
@@ -12,9 +11,13 @@ import (
 	"github.com/loft-sh/devpod/cmd/helper"
 	"github.com/loft-sh/devpod/cmd/ide"
 	"github.com/loft-sh/devpod/cmd/machine"
+	"github.com/loft-sh/devpod/cmd/ports"
 	"github.com/loft-sh/devpod/cmd/pro"
 	"github.com/loft-sh/devpod/cmd/provider"
+	"github.com/loft-sh/devpod/cmd/snapshot"
+	"github.com/loft-sh/devpod/cmd/sync"
 	"github.com/loft-sh/devpod/cmd/use"
+	"github.com/loft-sh/devpod/cmd/workspace"
 	"github.com/loft-sh/devpod/pkg/client/clientimplementation"
 	"github.com/loft-sh/devpod/pkg/config"
 	"github.com/loft-sh/devpod/pkg/telemetry"
@@ -88,8 +91,9 @@ func Execute() {
 			os.Exit(sshExitErr.ExitStatus())
 		}
 
+		// exec.ExitError as well as the exit errors of the docker api and driver plugins
 		//nolint:all
-		if execExitErr, ok := err.(*exec.ExitError); ok {
+		if execExitErr, ok := err.(interface{ ExitCode() int }); ok {
 			os.Exit(execExitErr.ExitCode())
 		}
 
@@ -122,10 +126,15 @@ func BuildRoot() *cobra.Command {
 	rootCmd.AddCommand(ide.NewIDECmd(globalFlags))
 	rootCmd.AddCommand(machine.NewMachineCmd(globalFlags))
 	rootCmd.AddCommand(context.NewContextCmd(globalFlags))
+	rootCmd.AddCommand(ports.NewPortsCmd(globalFlags))
+	rootCmd.AddCommand(snapshot.NewSnapshotCmd(globalFlags))
+	rootCmd.AddCommand(sync.NewSyncCmd(globalFlags))
+	rootCmd.AddCommand(workspace.NewWorkspaceCmd(globalFlags))
 	rootCmd.AddCommand(pro.NewProCmd(globalFlags, log2.Default))
 	rootCmd.AddCommand(NewUpCmd(globalFlags))
 	rootCmd.AddCommand(NewDeleteCmd(globalFlags))
 	rootCmd.AddCommand(NewSSHCmd(globalFlags))
+	rootCmd.AddCommand(NewCpCmd(globalFlags))
 	rootCmd.AddCommand(NewVersionCmd())
 	rootCmd.AddCommand(NewStopCmd(globalFlags))
 	rootCmd.AddCommand(NewListCmd(globalFlags))
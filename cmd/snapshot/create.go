@@ -0,0 +1,76 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/loft-sh/devpod/cmd/completion"
+	"github.com/loft-sh/devpod/cmd/flags"
+	"github.com/loft-sh/devpod/pkg/driver"
+	"github.com/loft-sh/log"
+	"github.com/spf13/cobra"
+)
+
+// CreateCmd holds the create cmd flags
+type CreateCmd struct {
+	*flags.GlobalFlags
+
+	Name string
+}
+
+// NewCreateCmd creates a new command
+func NewCreateCmd(flags *flags.GlobalFlags) *cobra.Command {
+	cmd := &CreateCmd{
+		GlobalFlags: flags,
+	}
+	createCmd := &cobra.Command{
+		Use:   "create [flags] [workspace-path|workspace-name]",
+		Short: "Captures the devcontainer and workspace folder of a workspace",
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(cobraCmd.Context(), args)
+		},
+		ValidArgsFunction: func(rootCmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return completion.GetWorkspaceSuggestions(rootCmd, cmd.Context, cmd.Provider, args, toComplete, cmd.Owner, log.Default)
+		},
+	}
+
+	createCmd.Flags().StringVar(&cmd.Name, "name", "", "The name of the snapshot, defaults to the current time")
+	return createCmd
+}
+
+// Run runs the command logic
+func (cmd *CreateCmd) Run(ctx context.Context, args []string) error {
+	if cmd.Name == "" {
+		cmd.Name = time.Now().Format("20060102-150405")
+	}
+	err := driver.ValidateSnapshotName(cmd.Name)
+	if err != nil {
+		return err
+	}
+
+	devPodConfig, client, err := getWorkspaceClient(ctx, cmd.GlobalFlags, args)
+	if err != nil {
+		return err
+	}
+
+	err = client.Lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Unlock()
+
+	snapshot := &driver.Snapshot{}
+	err = runAgentSnapshotCommand(ctx, devPodConfig, client, "create", fmt.Sprintf(" --name '%s'", cmd.Name), snapshot, log.Default)
+	if err != nil {
+		return err
+	}
+
+	if !snapshot.Ready {
+		log.Default.Donef("Started snapshot %s of workspace %s, it can be restored once it is ready", cmd.Name, client.Workspace())
+		return nil
+	}
+
+	log.Default.Donef("Successfully created snapshot %s of workspace %s", cmd.Name, client.Workspace())
+	return nil
+}
@@ -0,0 +1,61 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/loft-sh/devpod/cmd/completion"
+	"github.com/loft-sh/devpod/cmd/flags"
+	"github.com/loft-sh/devpod/pkg/driver"
+	"github.com/loft-sh/log"
+	"github.com/spf13/cobra"
+)
+
+// DeleteCmd holds the delete cmd flags
+type DeleteCmd struct {
+	*flags.GlobalFlags
+
+	Name string
+}
+
+// NewDeleteCmd creates a new command
+func NewDeleteCmd(flags *flags.GlobalFlags) *cobra.Command {
+	cmd := &DeleteCmd{
+		GlobalFlags: flags,
+	}
+	deleteCmd := &cobra.Command{
+		Use:   "delete [flags] [workspace-path|workspace-name]",
+		Short: "Deletes a snapshot of a workspace",
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(cobraCmd.Context(), args)
+		},
+		ValidArgsFunction: func(rootCmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return completion.GetWorkspaceSuggestions(rootCmd, cmd.Context, cmd.Provider, args, toComplete, cmd.Owner, log.Default)
+		},
+	}
+
+	deleteCmd.Flags().StringVar(&cmd.Name, "name", "", "The name of the snapshot")
+	_ = deleteCmd.MarkFlagRequired("name")
+	return deleteCmd
+}
+
+// Run runs the command logic
+func (cmd *DeleteCmd) Run(ctx context.Context, args []string) error {
+	err := driver.ValidateSnapshotName(cmd.Name)
+	if err != nil {
+		return err
+	}
+
+	devPodConfig, client, err := getWorkspaceClient(ctx, cmd.GlobalFlags, args)
+	if err != nil {
+		return err
+	}
+
+	err = runAgentSnapshotCommand(ctx, devPodConfig, client, "delete", fmt.Sprintf(" --name '%s'", cmd.Name), nil, log.Default)
+	if err != nil {
+		return err
+	}
+
+	log.Default.Donef("Successfully deleted snapshot %s of workspace %s", cmd.Name, client.Workspace())
+	return nil
+}
@@ -0,0 +1,99 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/loft-sh/devpod/cmd/completion"
+	"github.com/loft-sh/devpod/cmd/flags"
+	"github.com/loft-sh/devpod/pkg/driver"
+	"github.com/loft-sh/log"
+	"github.com/loft-sh/log/table"
+	"github.com/spf13/cobra"
+)
+
+// ListCmd holds the list cmd flags
+type ListCmd struct {
+	*flags.GlobalFlags
+
+	Output string
+}
+
+// NewListCmd creates a new command
+func NewListCmd(flags *flags.GlobalFlags) *cobra.Command {
+	cmd := &ListCmd{
+		GlobalFlags: flags,
+	}
+	listCmd := &cobra.Command{
+		Use:     "list [flags] [workspace-path|workspace-name]",
+		Aliases: []string{"ls"},
+		Short:   "Lists the snapshots of a workspace",
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(cobraCmd.Context(), args)
+		},
+		ValidArgsFunction: func(rootCmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return completion.GetWorkspaceSuggestions(rootCmd, cmd.Context, cmd.Provider, args, toComplete, cmd.Owner, log.Default)
+		},
+	}
+
+	listCmd.Flags().StringVar(&cmd.Output, "output", "plain", "The output format to use. Can be json or plain")
+	return listCmd
+}
+
+// Run runs the command logic
+func (cmd *ListCmd) Run(ctx context.Context, args []string) error {
+	devPodConfig, client, err := getWorkspaceClient(ctx, cmd.GlobalFlags, args)
+	if err != nil {
+		return err
+	}
+
+	snapshots := []*driver.Snapshot{}
+	err = runAgentSnapshotCommand(ctx, devPodConfig, client, "list", "", &snapshots, log.Default)
+	if err != nil {
+		return err
+	} else if snapshots == nil {
+		snapshots = []*driver.Snapshot{}
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Created.After(snapshots[j].Created)
+	})
+
+	if cmd.Output == "plain" {
+		tableEntries := [][]string{}
+		for _, snapshot := range snapshots {
+			size := ""
+			if snapshot.Size > 0 {
+				size = units.HumanSize(float64(snapshot.Size))
+			}
+
+			tableEntries = append(tableEntries, []string{
+				snapshot.Name,
+				time.Since(snapshot.Created).Round(time.Second).String(),
+				size,
+				strconv.FormatBool(snapshot.Ready),
+			})
+		}
+
+		table.PrintTable(log.Default, []string{
+			"Name",
+			"Age",
+			"Size",
+			"Ready",
+		}, tableEntries)
+	} else if cmd.Output == "json" {
+		out, err := json.MarshalIndent(snapshots, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(out))
+	} else {
+		return fmt.Errorf("unexpected output format, choose either json or plain. Got %s", cmd.Output)
+	}
+
+	return nil
+}
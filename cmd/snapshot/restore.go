@@ -0,0 +1,128 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/loft-sh/devpod/cmd/completion"
+	"github.com/loft-sh/devpod/cmd/flags"
+	clientpkg "github.com/loft-sh/devpod/pkg/client"
+	"github.com/loft-sh/devpod/pkg/client/clientimplementation"
+	"github.com/loft-sh/devpod/pkg/config"
+	"github.com/loft-sh/devpod/pkg/driver"
+	"github.com/loft-sh/devpod/pkg/encoding"
+	"github.com/loft-sh/devpod/pkg/provider"
+	"github.com/loft-sh/devpod/pkg/types"
+	"github.com/loft-sh/devpod/pkg/workspace"
+	"github.com/loft-sh/log"
+	"github.com/spf13/cobra"
+)
+
+// RestoreCmd holds the restore cmd flags
+type RestoreCmd struct {
+	*flags.GlobalFlags
+
+	Name        string
+	WorkspaceID string
+}
+
+// NewRestoreCmd creates a new command
+func NewRestoreCmd(flags *flags.GlobalFlags) *cobra.Command {
+	cmd := &RestoreCmd{
+		GlobalFlags: flags,
+	}
+	restoreCmd := &cobra.Command{
+		Use:   "restore [flags] [workspace-path|workspace-name]",
+		Short: "Creates a new workspace from a snapshot of a workspace",
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(cobraCmd.Context(), args)
+		},
+		ValidArgsFunction: func(rootCmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return completion.GetWorkspaceSuggestions(rootCmd, cmd.Context, cmd.Provider, args, toComplete, cmd.Owner, log.Default)
+		},
+	}
+
+	restoreCmd.Flags().StringVar(&cmd.Name, "name", "", "The name of the snapshot")
+	_ = restoreCmd.MarkFlagRequired("name")
+	restoreCmd.Flags().StringVar(&cmd.WorkspaceID, "workspace-id", "", "The id of the new workspace, defaults to <workspace>-<snapshot>")
+	return restoreCmd
+}
+
+// Run runs the command logic
+func (cmd *RestoreCmd) Run(ctx context.Context, args []string) error {
+	err := driver.ValidateSnapshotName(cmd.Name)
+	if err != nil {
+		return err
+	}
+
+	devPodConfig, sourceClient, err := getWorkspaceClient(ctx, cmd.GlobalFlags, args)
+	if err != nil {
+		return err
+	}
+	sourceWorkspace := sourceClient.WorkspaceConfig()
+
+	workspaceID := cmd.WorkspaceID
+	if workspaceID == "" {
+		workspaceID = sourceWorkspace.ID + "-" + cmd.Name
+	}
+	workspaceID = workspace.ToID(workspaceID)
+	if provider.WorkspaceExists(devPodConfig.DefaultContext, workspaceID) {
+		return fmt.Errorf("workspace %s already exists, please choose another one via --workspace-id", workspaceID)
+	}
+
+	// the new workspace uses the same source and provider, snapshots only exist on the machine they were taken on
+	now := types.Now()
+	newWorkspace := provider.CloneWorkspace(sourceWorkspace)
+	newWorkspace.ID = workspaceID
+	newWorkspace.UID = encoding.CreateNewUID(devPodConfig.DefaultContext, workspaceID)
+	newWorkspace.Origin = ""
+	newWorkspace.CreationTimestamp = now
+	newWorkspace.LastUsedTimestamp = now
+	if newWorkspace.Machine.ID != "" {
+		if newWorkspace.Machine.AutoDelete {
+			log.Default.Warnf("Workspace %s shares the machine %s with workspace %s, which deletes the machine when it gets deleted", workspaceID, newWorkspace.Machine.ID, sourceWorkspace.ID)
+		}
+		newWorkspace.Machine.AutoDelete = false
+	}
+	err = provider.SaveWorkspaceConfig(newWorkspace)
+	if err != nil {
+		return fmt.Errorf("save workspace config: %w", err)
+	}
+
+	snapshot, err := cmd.restore(ctx, devPodConfig, sourceWorkspace.ID, workspaceID)
+	if err != nil {
+		_ = clientimplementation.DeleteWorkspaceFolder(devPodConfig.DefaultContext, workspaceID, newWorkspace.SSHConfigPath, log.Default)
+		return err
+	}
+
+	// start the devcontainer from the committed image
+	if snapshot.Image != "" {
+		newWorkspace.DevContainerImage = snapshot.Image
+		err = provider.SaveWorkspaceConfig(newWorkspace)
+		if err != nil {
+			return fmt.Errorf("save workspace config: %w", err)
+		}
+	}
+
+	log.Default.Donef("Successfully restored snapshot %s into workspace %s, run 'devpod up %s' to start it", cmd.Name, workspaceID, workspaceID)
+	return nil
+}
+
+func (cmd *RestoreCmd) restore(ctx context.Context, devPodConfig *config.Config, sourceWorkspaceID, workspaceID string) (*driver.Snapshot, error) {
+	baseClient, err := workspace.Get(ctx, devPodConfig, []string{workspaceID}, false, cmd.Owner, true, log.Default.ErrorStreamOnly())
+	if err != nil {
+		return nil, err
+	}
+	client, ok := baseClient.(clientpkg.WorkspaceClient)
+	if !ok {
+		return nil, fmt.Errorf("snapshots are not supported for proxy providers")
+	}
+
+	snapshot := &driver.Snapshot{}
+	err = runAgentSnapshotCommand(ctx, devPodConfig, client, "restore", fmt.Sprintf(" --name '%s' --source-id '%s'", cmd.Name, sourceWorkspaceID), snapshot, log.Default)
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
@@ -0,0 +1,103 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/loft-sh/devpod/cmd/flags"
+	"github.com/loft-sh/devpod/pkg/agent"
+	clientpkg "github.com/loft-sh/devpod/pkg/client"
+	"github.com/loft-sh/devpod/pkg/config"
+	"github.com/loft-sh/devpod/pkg/provider"
+	"github.com/loft-sh/devpod/pkg/workspace"
+	"github.com/loft-sh/log"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// NewSnapshotCmd returns a new command
+func NewSnapshotCmd(flags *flags.GlobalFlags) *cobra.Command {
+	snapshotCmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "DevPod workspace snapshot commands",
+	}
+
+	snapshotCmd.AddCommand(NewCreateCmd(flags))
+	snapshotCmd.AddCommand(NewListCmd(flags))
+	snapshotCmd.AddCommand(NewDeleteCmd(flags))
+	snapshotCmd.AddCommand(NewRestoreCmd(flags))
+	return snapshotCmd
+}
+
+func getWorkspaceClient(ctx context.Context, globalFlags *flags.GlobalFlags, args []string) (*config.Config, clientpkg.WorkspaceClient, error) {
+	devPodConfig, err := config.LoadConfig(globalFlags.Context, globalFlags.Provider)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	baseClient, err := workspace.Get(ctx, devPodConfig, args, false, globalFlags.Owner, false, log.Default.ErrorStreamOnly())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, ok := baseClient.(clientpkg.WorkspaceClient)
+	if !ok {
+		return nil, nil, fmt.Errorf("snapshots are not supported for proxy providers")
+	}
+
+	return devPodConfig, client, nil
+}
+
+// runAgentSnapshotCommand runs the snapshot action on the agent of the workspace and decodes its output into out
+func runAgentSnapshotCommand(ctx context.Context, devPodConfig *config.Config, client clientpkg.WorkspaceClient, action string, extraArgs string, out interface{}, log log.Logger) error {
+	workspaceInfo, _, err := client.AgentInfo(provider.CLIOptions{})
+	if err != nil {
+		return err
+	}
+
+	command := fmt.Sprintf("'%s' agent workspace snapshot %s --workspace-info '%s'%s", client.AgentPath(), action, workspaceInfo, extraArgs)
+	if log.GetLevel() == logrus.DebugLevel {
+		command += " --debug"
+	}
+
+	stdout := &bytes.Buffer{}
+	stderr := log.ErrorStreamOnly().Writer(logrus.InfoLevel, false)
+	defer stderr.Close()
+
+	err = agent.InjectAgentAndExecute(
+		ctx,
+		func(ctx context.Context, command string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+			return client.Command(ctx, clientpkg.CommandOptions{
+				Command: command,
+				Stdin:   stdin,
+				Stdout:  stdout,
+				Stderr:  stderr,
+			})
+		},
+		client.AgentLocal(),
+		client.AgentPath(),
+		client.AgentURL(),
+		true,
+		command,
+		nil,
+		stdout,
+		stderr,
+		log.ErrorStreamOnly(),
+		config.ParseTimeOption(devPodConfig, config.ContextOptionAgentInjectTimeout),
+	)
+	if err != nil {
+		return fmt.Errorf("snapshot %s: %w", action, err)
+	} else if out == nil || stdout.Len() == 0 {
+		return nil
+	}
+
+	err = json.Unmarshal(stdout.Bytes(), out)
+	if err != nil {
+		return fmt.Errorf("parse snapshot %s output %s: %w", action, stdout.String(), err)
+	}
+
+	return nil
+}
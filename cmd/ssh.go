@@ -18,9 +18,9 @@ import (
 	client2 "github.com/loft-sh/devpod/pkg/client"
 	"github.com/loft-sh/devpod/pkg/config"
 	daemon "github.com/loft-sh/devpod/pkg/daemon/platform"
+	"github.com/loft-sh/devpod/pkg/driver/kubernetes"
 	"github.com/loft-sh/devpod/pkg/gpg"
 	"github.com/loft-sh/devpod/pkg/port"
-	"github.com/loft-sh/devpod/pkg/provider"
 	devssh "github.com/loft-sh/devpod/pkg/ssh"
 	"github.com/loft-sh/devpod/pkg/tunnel"
 	workspace2 "github.com/loft-sh/devpod/pkg/workspace"
@@ -180,7 +180,7 @@ func (cmd *SSHCmd) jumpContainerTailscale(
 
 	// Forward ports if specified
 	if len(cmd.ForwardPorts) > 0 {
-		return cmd.forwardPorts(ctx, toolSSHClient, log)
+		return cmd.forwardPorts(ctx, toolSSHClient.Dial, log)
 	}
 
 	// Reverse forward ports if specified
@@ -339,6 +339,15 @@ func (cmd *SSHCmd) jumpContainer(
 		return err
 	}
 
+	// forward ports through the kubernetes api without connecting to the container via ssh
+	if len(cmd.ForwardPorts) > 0 && cmd.canForwardPortsNatively() {
+		dial := tunnel.NewKubernetesDialer(ctx, client, log)
+		if dial != nil {
+			client.Unlock()
+			return cmd.forwardPorts(ctx, dial, log)
+		}
+	}
+
 	envVars, err := cmd.retrieveEnVars()
 	if err != nil {
 		return err
@@ -416,7 +425,7 @@ func (cmd *SSHCmd) reverseForwardPorts(
 
 func (cmd *SSHCmd) forwardPorts(
 	ctx context.Context,
-	containerClient *ssh.Client,
+	dial devssh.DialFunc,
 	log log.Logger,
 ) error {
 	timeout, err := cmd.forwardTimeout(log)
@@ -440,9 +449,9 @@ func (cmd *SSHCmd) forwardPorts(
 			mapping.Container.Address,
 		)
 		go func(portMapping string) {
-			err := devssh.PortForward(
+			err := devssh.PortForwardWithDialer(
 				ctx,
-				containerClient,
+				dial,
 				mapping.Host.Protocol,
 				mapping.Host.Address,
 				mapping.Container.Protocol,
@@ -459,10 +468,22 @@ func (cmd *SSHCmd) forwardPorts(
 	return <-errChan
 }
 
+// canForwardPortsNatively returns if all port mappings forward local tcp ports to tcp ports on localhost of the container
+func (cmd *SSHCmd) canForwardPortsNatively() bool {
+	for _, portMapping := range cmd.ForwardPorts {
+		mapping, err := port.ParsePortSpec(portMapping)
+		if err != nil || mapping.Host.Protocol != "tcp" || !kubernetes.CanPortForward(mapping.Container.Protocol, mapping.Container.Address) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (cmd *SSHCmd) startTunnel(ctx context.Context, devPodConfig *config.Config, containerClient *ssh.Client, workspaceClient client2.BaseWorkspaceClient, log log.Logger) error {
 	// check if we should forward ports
 	if len(cmd.ForwardPorts) > 0 {
-		return cmd.forwardPorts(ctx, containerClient, log)
+		return cmd.forwardPorts(ctx, containerClient.Dial, log)
 	}
 
 	// check if we should reverse forward ports
@@ -475,7 +496,7 @@ func (cmd *SSHCmd) startTunnel(ctx context.Context, devPodConfig *config.Config,
 		configureGitCredentials := devPodConfig.ContextOption(config.ContextOptionSSHInjectGitCredentials) == "true"
 		configureGitSSHSignatureHelper := devPodConfig.ContextOption(config.ContextOptionGitSSHSignatureForwarding) == "true"
 
-		go cmd.startServices(ctx, devPodConfig, containerClient, workspaceClient.WorkspaceConfig(), configureDockerCredentials, configureGitCredentials, configureGitSSHSignatureHelper, log)
+		go cmd.startServices(ctx, devPodConfig, containerClient, workspaceClient, configureDockerCredentials, configureGitCredentials, configureGitSSHSignatureHelper, log)
 	}
 	// start ssh
 	writer := log.ErrorStreamOnly().Writer(logrus.InfoLevel, false)
@@ -542,7 +563,7 @@ func (cmd *SSHCmd) startServices(
 	ctx context.Context,
 	devPodConfig *config.Config,
 	containerClient *ssh.Client,
-	workspace *provider.Workspace,
+	workspaceClient client2.BaseWorkspaceClient,
 	configureDockerCredentials, configureGitCredentials, configureGitSSHSignatureHelper bool,
 	log log.Logger,
 ) {
@@ -551,11 +572,12 @@ func (cmd *SSHCmd) startServices(
 			ctx,
 			devPodConfig,
 			containerClient,
+			tunnel.NewKubernetesDialer(ctx, workspaceClient, log),
 			cmd.User,
 			false,
 			nil,
 			nil,
-			workspace,
+			workspaceClient.WorkspaceConfig(),
 			configureDockerCredentials,
 			configureGitCredentials,
 			configureGitSSHSignatureHelper,
@@ -690,6 +712,7 @@ func startServicesDaemon(ctx context.Context, devPodConfig *config.Config, clien
 			ctx,
 			devPodConfig,
 			sshClient,
+			nil,
 			user,
 			forwardPorts,
 			extraPorts,
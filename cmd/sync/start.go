@@ -0,0 +1,134 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/loft-sh/devpod/cmd/completion"
+	"github.com/loft-sh/devpod/cmd/flags"
+	"github.com/loft-sh/devpod/pkg/config"
+	"github.com/loft-sh/devpod/pkg/filesync"
+	"github.com/loft-sh/devpod/pkg/provider"
+	"github.com/loft-sh/devpod/pkg/tunnel"
+	"github.com/loft-sh/devpod/pkg/workspace"
+	"github.com/loft-sh/log"
+	"github.com/pkg/sftp"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+)
+
+// StartCmd holds the start cmd flags
+type StartCmd struct {
+	*flags.GlobalFlags
+
+	LocalFolder string
+	Excludes    []string
+	Prefer      string
+	Interval    time.Duration
+	Once        bool
+}
+
+// NewStartCmd creates a new command
+func NewStartCmd(flags *flags.GlobalFlags) *cobra.Command {
+	cmd := &StartCmd{
+		GlobalFlags: flags,
+	}
+	startCmd := &cobra.Command{
+		Use:   "start [flags] [workspace-path|workspace-name]",
+		Short: "Syncs a local folder with the workspace folder in the container in both directions",
+		Long: `Syncs a local folder with the workspace folder in the container in both directions until it is stopped.
+Paths matched by the .gitignore files or .devpodignore of the local folder are skipped. Paths that were changed
+on both sides since the last sync are reported as conflicts and left untouched, unless --prefer is set. If one
+side was emptied, the sync stops until --prefer selects the side to keep.`,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			ctx, cancel := signal.NotifyContext(cobraCmd.Context(), os.Interrupt)
+			defer cancel()
+
+			return cmd.Run(ctx, args)
+		},
+		ValidArgsFunction: func(rootCmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return completion.GetWorkspaceSuggestions(rootCmd, cmd.Context, cmd.Provider, args, toComplete, cmd.Owner, log.Default)
+		},
+	}
+
+	startCmd.Flags().StringVar(&cmd.LocalFolder, "local-folder", "", "The local folder to sync, defaults to the folder the workspace was created from")
+	startCmd.Flags().StringArrayVar(&cmd.Excludes, "exclude", []string{}, "A path to skip in the format of a .dockerignore. Can be used multiple times")
+	startCmd.Flags().StringVar(&cmd.Prefer, "prefer", "", "Resolves conflicts with the local or remote changes. Can be local or remote")
+	startCmd.Flags().DurationVar(&cmd.Interval, "interval", 2*time.Second, "The time between two syncs")
+	startCmd.Flags().BoolVar(&cmd.Once, "once", false, "If true will sync once and exit")
+	return startCmd
+}
+
+// Run runs the command logic
+func (cmd *StartCmd) Run(ctx context.Context, args []string) error {
+	devPodConfig, err := config.LoadConfig(cmd.Context, cmd.Provider)
+	if err != nil {
+		return err
+	}
+
+	client, err := workspace.Get(ctx, devPodConfig, args, true, cmd.Owner, false, log.Default)
+	if err != nil {
+		return err
+	}
+
+	options, err := cmd.getOptions(client.WorkspaceConfig())
+	if err != nil {
+		return err
+	}
+
+	return tunnel.WithContainer(ctx, devPodConfig, client, log.Default, func(ctx context.Context, containerClient *ssh.Client) error {
+		sftpClient, err := sftp.NewClient(containerClient)
+		if err != nil {
+			return fmt.Errorf("start sftp client: %w", err)
+		}
+		defer sftpClient.Close()
+
+		return filesync.Sync(ctx, sftpClient, *options, log.Default)
+	})
+}
+
+func (cmd *StartCmd) getOptions(workspaceConfig *provider.Workspace) (*filesync.Options, error) {
+	localFolder := cmd.LocalFolder
+	if localFolder == "" {
+		localFolder = workspaceConfig.Source.LocalFolder
+		if localFolder == "" {
+			return nil, fmt.Errorf("workspace %s wasn't created from a local folder, please specify the folder to sync with --local-folder", workspaceConfig.ID)
+		}
+	}
+	localFolder, err := filepath.Abs(localFolder)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(localFolder)
+	if err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a folder", localFolder)
+	}
+
+	result, err := provider.LoadWorkspaceResult(workspaceConfig.Context, workspaceConfig.ID)
+	if err != nil {
+		return nil, err
+	} else if result == nil || result.SubstitutionContext == nil || result.SubstitutionContext.ContainerWorkspaceFolder == "" {
+		return nil, fmt.Errorf("couldn't find the workspace folder in the container, please run 'devpod up %s' first", workspaceConfig.ID)
+	}
+
+	stateFolder, err := provider.GetWorkspaceDir(workspaceConfig.Context, workspaceConfig.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &filesync.Options{
+		LocalFolder:  localFolder,
+		RemoteFolder: result.SubstitutionContext.ContainerWorkspaceFolder,
+		StateFolder:  stateFolder,
+		Excludes:     cmd.Excludes,
+		Prefer:       cmd.Prefer,
+		Interval:     cmd.Interval,
+		Once:         cmd.Once,
+	}, nil
+}
@@ -0,0 +1,125 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/loft-sh/devpod/cmd/completion"
+	"github.com/loft-sh/devpod/cmd/flags"
+	"github.com/loft-sh/devpod/pkg/config"
+	"github.com/loft-sh/devpod/pkg/filesync"
+	"github.com/loft-sh/devpod/pkg/provider"
+	"github.com/loft-sh/devpod/pkg/workspace"
+	"github.com/loft-sh/log"
+	"github.com/loft-sh/log/table"
+	"github.com/spf13/cobra"
+)
+
+// StatusCmd holds the status cmd flags
+type StatusCmd struct {
+	*flags.GlobalFlags
+
+	Output string
+}
+
+// NewStatusCmd creates a new command
+func NewStatusCmd(flags *flags.GlobalFlags) *cobra.Command {
+	cmd := &StatusCmd{
+		GlobalFlags: flags,
+	}
+	statusCmd := &cobra.Command{
+		Use:   "status [flags] [workspace-path|workspace-name]",
+		Short: "Shows the status of the file sync of a workspace",
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(cobraCmd.Context(), args)
+		},
+		ValidArgsFunction: func(rootCmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return completion.GetWorkspaceSuggestions(rootCmd, cmd.Context, cmd.Provider, args, toComplete, cmd.Owner, log.Default)
+		},
+	}
+
+	statusCmd.Flags().StringVar(&cmd.Output, "output", "plain", "The output format to use. Can be json or plain")
+	return statusCmd
+}
+
+// Run runs the command logic
+func (cmd *StatusCmd) Run(ctx context.Context, args []string) error {
+	devPodConfig, err := config.LoadConfig(cmd.Context, cmd.Provider)
+	if err != nil {
+		return err
+	}
+
+	client, err := workspace.Get(ctx, devPodConfig, args, false, cmd.Owner, true, log.Default.ErrorStreamOnly())
+	if err != nil {
+		return err
+	}
+
+	stateFolder, err := provider.GetWorkspaceDir(client.WorkspaceConfig().Context, client.WorkspaceConfig().ID)
+	if err != nil {
+		return err
+	}
+
+	status, err := filesync.GetStatus(stateFolder)
+	if err != nil {
+		return fmt.Errorf("get sync status: %w", err)
+	} else if status == nil {
+		if cmd.Output == "json" {
+			fmt.Print("{}")
+			return nil
+		}
+
+		log.Default.Infof("Workspace %s wasn't synced yet, start the sync with 'devpod sync start %s'", client.Workspace(), client.Workspace())
+		return nil
+	}
+
+	if cmd.Output == "plain" {
+		lastSync := "never"
+		if !status.LastSync.IsZero() {
+			lastSync = time.Since(status.LastSync).Round(time.Second).String() + " ago"
+		}
+
+		table.PrintTable(log.Default, []string{
+			"Local Folder",
+			"Remote Folder",
+			"Running",
+			"Last Sync",
+			"Files",
+			"Conflicts",
+		}, [][]string{{
+			status.LocalFolder,
+			status.RemoteFolder,
+			strconv.FormatBool(status.Running),
+			lastSync,
+			strconv.Itoa(status.Files),
+			strconv.Itoa(len(status.Conflicts)),
+		}})
+
+		if len(status.Conflicts) > 0 {
+			tableEntries := [][]string{}
+			for _, conflict := range status.Conflicts {
+				tableEntries = append(tableEntries, []string{conflict.Path, conflict.Local, conflict.Remote})
+			}
+			table.PrintTable(log.Default, []string{
+				"Conflict",
+				"Local",
+				"Remote",
+			}, tableEntries)
+		}
+		if status.LastError != "" {
+			log.Default.Warnf("Last sync failed: %s", status.LastError)
+		}
+	} else if cmd.Output == "json" {
+		out, err := json.MarshalIndent(status, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(out))
+	} else {
+		return fmt.Errorf("unexpected output format, choose either json or plain. Got %s", cmd.Output)
+	}
+
+	return nil
+}
@@ -0,0 +1,18 @@
+package sync
+
+import (
+	"github.com/loft-sh/devpod/cmd/flags"
+	"github.com/spf13/cobra"
+)
+
+// NewSyncCmd returns a new command
+func NewSyncCmd(flags *flags.GlobalFlags) *cobra.Command {
+	syncCmd := &cobra.Command{
+		Use:   "sync",
+		Short: "DevPod file sync commands",
+	}
+
+	syncCmd.AddCommand(NewStartCmd(flags))
+	syncCmd.AddCommand(NewStatusCmd(flags))
+	return syncCmd
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/loft-sh/devpod/pkg/config"
 	config2 "github.com/loft-sh/devpod/pkg/devcontainer/config"
 	"github.com/loft-sh/devpod/pkg/devcontainer/sshtunnel"
+	"github.com/loft-sh/devpod/pkg/git"
 	"github.com/loft-sh/devpod/pkg/ide"
 	"github.com/loft-sh/devpod/pkg/ide/fleet"
 	"github.com/loft-sh/devpod/pkg/ide/jetbrains"
@@ -66,6 +67,7 @@ type UpCmd struct {
 	Reconfigure        bool
 
 	SSHConfigPath string
+	Branch        string
 
 	DotfilesSource        string
 	DotfilesScript        string
@@ -127,9 +129,11 @@ func NewUpCmd(f *flags.GlobalFlags) *cobra.Command {
 	upCmd.Flags().BoolVar(&cmd.OpenIDE, "open-ide", true, "If this is false and an IDE is configured, DevPod will only install the IDE server backend, but not open it")
 	upCmd.Flags().Var(&cmd.GitCloneStrategy, "git-clone-strategy", "The git clone strategy DevPod uses to checkout git based workspaces. Can be full (default), blobless, treeless or shallow")
 	upCmd.Flags().BoolVar(&cmd.GitCloneRecursiveSubmodules, "git-clone-recursive-submodules", false, "If true will clone git submodule repositories recursively")
+	upCmd.Flags().StringArrayVar(&cmd.GitSparsePaths, "git-sparse-path", []string{}, "A directory relative to the repository root to check out, all other directories are skipped. Can be used multiple times")
 	upCmd.Flags().StringVar(&cmd.GitSSHSigningKey, "git-ssh-signing-key", "", "The ssh key to use when signing git commits. Used to explicitly setup DevPod's ssh signature forwarding with given key. Should be same format as value of `git config user.signingkey`")
 	upCmd.Flags().StringVar(&cmd.FallbackImage, "fallback-image", "", "The fallback image to use if no devcontainer configuration has been detected")
 	upCmd.Flags().BoolVar(&cmd.DisableDaemon, "disable-daemon", false, "If enabled, will not install a daemon into the target machine to track activity")
+	upCmd.Flags().StringVar(&cmd.Branch, "branch", "", "Creates the workspace from a git worktree of this branch of the given local repository")
 	upCmd.Flags().StringVar(&cmd.Source, "source", "", "Optional source for the workspace. E.g. git:https://github.com/my-org/my-repo")
 
 	// testing
@@ -954,6 +958,7 @@ func startBrowserTunnel(
 				ctx,
 				devPodConfig,
 				containerClient,
+				tunnel.NewKubernetesDialer(ctx, client, logger),
 				user,
 				forwardPorts,
 				extraPorts,
@@ -1387,6 +1392,7 @@ func (cmd *UpCmd) prepareClient(ctx context.Context, devPodConfig *config.Config
 	}
 
 	var source *provider2.WorkspaceSource
+	createdWorktree := false
 	if cmd.Source != "" {
 		source = provider2.ParseWorkspaceSource(cmd.Source)
 		if source == nil {
@@ -1396,6 +1402,20 @@ func (cmd *UpCmd) prepareClient(ctx context.Context, devPodConfig *config.Config
 		}
 	}
 
+	if cmd.Branch != "" {
+		if len(args) == 0 || source != nil {
+			return nil, nil, fmt.Errorf("--branch requires the path to a local git repository")
+		} else if cmd.Platform.Enabled {
+			return nil, nil, fmt.Errorf("--branch is not supported in platform mode")
+		}
+
+		var err error
+		cmd.ID, source, createdWorktree, err = workspace2.ResolveWorktree(ctx, devPodConfig, args[0], cmd.Branch, cmd.ID, logger)
+		if err != nil {
+			return nil, logger, err
+		}
+	}
+
 	if cmd.SSHConfigPath == "" {
 		cmd.SSHConfigPath = devPodConfig.ContextOption(config.ContextOptionSSHConfigPath)
 	}
@@ -1420,9 +1440,25 @@ func (cmd *UpCmd) prepareClient(ctx context.Context, devPodConfig *config.Config
 		logger,
 	)
 	if err != nil {
+		if createdWorktree {
+			removeErr := git.RemoveWorktree(ctx, source.GitWorktreeRepository, source.LocalFolder, false)
+			if removeErr != nil {
+				logger.Warnf("Error removing worktree %s: %v", source.LocalFolder, removeErr)
+			}
+		}
 		return nil, logger, err
 	}
 
+	// remember the sparse checkout, so it is used again on --reset
+	workspace := client.WorkspaceConfig()
+	if len(cmd.GitSparsePaths) > 0 && workspace.Source.GitRepository != "" && !slices.Equal(workspace.Source.GitSparsePaths, cmd.GitSparsePaths) {
+		workspace.Source.GitSparsePaths = cmd.GitSparsePaths
+		err = provider2.SaveWorkspaceConfig(workspace)
+		if err != nil {
+			return nil, logger, fmt.Errorf("save workspace: %w", err)
+		}
+	}
+
 	if !cmd.Platform.Enabled {
 		proInstance := getProInstance(devPodConfig, client.Provider(), logger)
 		err = checkProviderUpdate(devPodConfig, proInstance, logger)
@@ -0,0 +1,65 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/loft-sh/devpod/cmd/completion"
+	"github.com/loft-sh/devpod/cmd/flags"
+	clientpkg "github.com/loft-sh/devpod/pkg/client"
+	"github.com/loft-sh/devpod/pkg/config"
+	"github.com/loft-sh/devpod/pkg/workspace"
+	"github.com/loft-sh/log"
+	"github.com/spf13/cobra"
+)
+
+// GenerateKubeCmd holds the generate-kube cmd flags
+type GenerateKubeCmd struct {
+	*flags.GlobalFlags
+}
+
+// NewGenerateKubeCmd creates a new command
+func NewGenerateKubeCmd(flags *flags.GlobalFlags) *cobra.Command {
+	cmd := &GenerateKubeCmd{
+		GlobalFlags: flags,
+	}
+	generateKubeCmd := &cobra.Command{
+		Use:   "generate-kube [flags] [workspace-path|workspace-name]",
+		Short: "Prints the kubernetes manifests of a podman workspace",
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(cobraCmd.Context(), args)
+		},
+		ValidArgsFunction: func(rootCmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return completion.GetWorkspaceSuggestions(rootCmd, cmd.Context, cmd.Provider, args, toComplete, cmd.Owner, log.Default)
+		},
+	}
+
+	return generateKubeCmd
+}
+
+// Run runs the command logic
+func (cmd *GenerateKubeCmd) Run(ctx context.Context, args []string) error {
+	devPodConfig, err := config.LoadConfig(cmd.Context, cmd.Provider)
+	if err != nil {
+		return err
+	}
+
+	logger := log.Default.ErrorStreamOnly()
+	baseClient, err := workspace.Get(ctx, devPodConfig, args, false, cmd.Owner, false, logger)
+	if err != nil {
+		return err
+	}
+	client, ok := baseClient.(clientpkg.WorkspaceClient)
+	if !ok {
+		return fmt.Errorf("generating kubernetes manifests is not supported for proxy providers")
+	}
+
+	err = client.Lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Unlock()
+
+	return client.GenerateKube(ctx, os.Stdout)
+}
@@ -0,0 +1,89 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/go-units"
+	"github.com/loft-sh/devpod/cmd/completion"
+	"github.com/loft-sh/devpod/cmd/flags"
+	clientpkg "github.com/loft-sh/devpod/pkg/client"
+	"github.com/loft-sh/devpod/pkg/config"
+	"github.com/loft-sh/devpod/pkg/workspace"
+	"github.com/loft-sh/log"
+	"github.com/spf13/cobra"
+)
+
+// ResizeCmd holds the resize cmd flags
+type ResizeCmd struct {
+	*flags.GlobalFlags
+
+	CPUs   float64
+	Memory string
+}
+
+// NewResizeCmd creates a new command
+func NewResizeCmd(flags *flags.GlobalFlags) *cobra.Command {
+	cmd := &ResizeCmd{
+		GlobalFlags: flags,
+	}
+	resizeCmd := &cobra.Command{
+		Use:   "resize [flags] [workspace-path|workspace-name]",
+		Short: "Changes the cpus and memory of an existing workspace",
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(cobraCmd.Context(), args)
+		},
+		ValidArgsFunction: func(rootCmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return completion.GetWorkspaceSuggestions(rootCmd, cmd.Context, cmd.Provider, args, toComplete, cmd.Owner, log.Default)
+		},
+	}
+
+	resizeCmd.Flags().Float64Var(&cmd.CPUs, "cpus", 0, "The number of cpus the workspace can use")
+	resizeCmd.Flags().StringVar(&cmd.Memory, "memory", "", "The memory the workspace can use, e.g. 8gb")
+	return resizeCmd
+}
+
+// Run runs the command logic
+func (cmd *ResizeCmd) Run(ctx context.Context, args []string) error {
+	options := clientpkg.ResizeOptions{CPUs: cmd.CPUs}
+	if cmd.Memory != "" {
+		memory, err := units.RAMInBytes(cmd.Memory)
+		if err != nil {
+			return fmt.Errorf("parse memory %s: %w", cmd.Memory, err)
+		}
+		options.Memory = memory
+	}
+	if options.CPUs < 0 || options.Memory < 0 {
+		return fmt.Errorf("cpus and memory need to be positive")
+	} else if options.CPUs == 0 && options.Memory == 0 {
+		return fmt.Errorf("please specify either --cpus or --memory")
+	}
+
+	devPodConfig, err := config.LoadConfig(cmd.Context, cmd.Provider)
+	if err != nil {
+		return err
+	}
+
+	baseClient, err := workspace.Get(ctx, devPodConfig, args, false, cmd.Owner, false, log.Default)
+	if err != nil {
+		return err
+	}
+	client, ok := baseClient.(clientpkg.WorkspaceClient)
+	if !ok {
+		return fmt.Errorf("resizing workspaces is not supported for proxy providers")
+	}
+
+	err = client.Lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Unlock()
+
+	err = client.Resize(ctx, options)
+	if err != nil {
+		return err
+	}
+
+	log.Default.Donef("Successfully resized workspace %s", client.Workspace())
+	return nil
+}
@@ -0,0 +1,18 @@
+package workspace
+
+import (
+	"github.com/loft-sh/devpod/cmd/flags"
+	"github.com/spf13/cobra"
+)
+
+// NewWorkspaceCmd returns a new command
+func NewWorkspaceCmd(flags *flags.GlobalFlags) *cobra.Command {
+	workspaceCmd := &cobra.Command{
+		Use:   "workspace",
+		Short: "DevPod workspace commands",
+	}
+
+	workspaceCmd.AddCommand(NewResizeCmd(flags))
+	workspaceCmd.AddCommand(NewGenerateKubeCmd(flags))
+	return workspaceCmd
+}
@@ -99,6 +99,18 @@ module.exports = {
           type: "doc",
           id: "developing-in-workspaces/stop-a-workspace",
         },
+        {
+          type: "doc",
+          id: "developing-in-workspaces/snapshot-a-workspace",
+        },
+        {
+          type: "doc",
+          id: "developing-in-workspaces/sync-a-workspace",
+        },
+        {
+          type: "doc",
+          id: "developing-in-workspaces/resize-a-workspace",
+        },
         {
           type: "doc",
           id: "developing-in-workspaces/delete-a-workspace",
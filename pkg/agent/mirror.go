@@ -0,0 +1,67 @@
+package agent
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/loft-sh/devpod/pkg/git"
+	provider2 "github.com/loft-sh/devpod/pkg/provider"
+	"github.com/loft-sh/log"
+)
+
+const defaultGitMirrorMaxAge = 30 * 24 * time.Hour
+
+// GetAgentGitMirrorsDir returns the folder of the git mirrors shared by the workspaces on this machine
+func GetAgentGitMirrorsDir(agentFolder string) (string, error) {
+	homeFolder, err := PrepareAgentHomeFolder(agentFolder)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(homeFolder, "git-mirrors"), nil
+}
+
+// getGitMirrorOption updates the mirror of the repository on this machine and returns the git option to clone from it.
+// Workspaces of the same repository then only fetch the changes since the last clone. Returns nil if the repository
+// should be cloned without a mirror, e.g. for shallow or blobless clones that would download more with one. The
+// returned function releases the mirror after the clone.
+func getGitMirrorOption(
+	ctx context.Context,
+	source *provider2.WorkspaceSource,
+	agentConfig *provider2.ProviderAgentConfig,
+	options provider2.CLIOptions,
+	helper string,
+	extraEnv []string,
+	log log.Logger,
+) (git.Option, func()) {
+	if agentConfig.GitMirror.Disabled == "true" || options.GitCloneStrategy != git.FullCloneStrategy ||
+		options.Platform.GitCloneStrategy != "" || len(options.GitSparsePaths) > 0 || len(source.GitSparsePaths) > 0 {
+		return nil, func() {}
+	}
+
+	mirrorsDir, err := GetAgentGitMirrorsDir(agentConfig.DataPath)
+	if err != nil {
+		log.Debugf("Error finding git mirrors folder: %v", err)
+		return nil, func() {}
+	}
+
+	maxAge := defaultGitMirrorMaxAge
+	if agentConfig.GitMirror.MaxAge != "" {
+		maxAge, err = time.ParseDuration(agentConfig.GitMirror.MaxAge)
+		if err != nil {
+			log.Warnf("Error parsing git mirror max age %s: %v", agentConfig.GitMirror.MaxAge, err)
+			maxAge = defaultGitMirrorMaxAge
+		}
+	}
+	git.PruneMirrors(mirrorsDir, maxAge, log)
+
+	mirrorDir := git.GetMirrorDir(mirrorsDir, source.GitRepository)
+	release, err := git.UpdateMirror(ctx, source.GitRepository, mirrorDir, helper, extraEnv, options.StrictHostKeyChecking, log)
+	if err != nil {
+		log.Warnf("Error updating git mirror, cloning without it: %v", err)
+		return nil, func() {}
+	}
+
+	return git.WithReference(mirrorDir), release
+}
@@ -0,0 +1,36 @@
+package agent
+
+import (
+	"net"
+	"os"
+)
+
+// ContainerSessionsSocket is the socket of the container daemon that every ssh session within the container
+// holds a connection to, so the daemon knows when the last session disconnected
+const ContainerSessionsSocket = "/tmp/devpod.sessions.sock"
+
+// ContainerShutdownActionFile is written by the container daemon once the last session disconnected and the
+// shutdown action can't be run from within the container, e.g. to stop the other services of a compose project
+const ContainerShutdownActionFile = "/tmp/devpod.shutdown-action"
+
+// RegisterContainerSession marks the current process as an active session within the container if the container
+// daemon tracks sessions. The returned function removes the session again.
+func RegisterContainerSession() (func(), error) {
+	_, err := os.Stat(ContainerSessionsSocket)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return func() {}, nil
+		}
+
+		return nil, err
+	}
+
+	conn, err := net.Dial("unix", ContainerSessionsSocket)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		_ = conn.Close()
+	}, nil
+}
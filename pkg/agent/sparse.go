@@ -0,0 +1,94 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/loft-sh/devpod/pkg/devcontainer/config"
+	"github.com/loft-sh/devpod/pkg/git"
+	provider2 "github.com/loft-sh/devpod/pkg/provider"
+	"github.com/loft-sh/log"
+	"github.com/tidwall/jsonc"
+)
+
+// getSparseCheckoutOption returns the git option for a sparse checkout of the workspace or nil if no directories
+// are configured. The directories are taken from --git-sparse-path, or the ones remembered with the workspace source,
+// and are extended by customizations.devpod.gitSparsePaths of the devcontainer.json.
+func getSparseCheckoutOption(source *provider2.WorkspaceSource, options provider2.CLIOptions, log log.Logger) git.Option {
+	sparsePaths := options.GitSparsePaths
+	if len(sparsePaths) == 0 {
+		sparsePaths = source.GitSparsePaths
+	}
+	sparsePaths = normalizeSparsePaths(sparsePaths)
+	if len(sparsePaths) == 0 {
+		return nil
+	}
+
+	return git.WithSparseCheckout(sparsePaths, func(ctx context.Context, targetDir string, extraEnv []string) ([]string, error) {
+		paths := sparsePaths
+		devContainerPath, devContainerConfig := readDevContainerFromHead(ctx, targetDir, extraEnv, source.GitSubPath, options.DevContainerPath, log)
+		if devContainerConfig != nil {
+			paths = append(paths, config.GetDevPodCustomizations(devContainerConfig).GitSparsePaths...)
+		}
+		// the sub path and the devcontainer.json are always needed
+		if source.GitSubPath != "" {
+			paths = append(paths, source.GitSubPath)
+		}
+		if devContainerPath != "" {
+			paths = append(paths, path.Dir(devContainerPath))
+		}
+
+		paths = normalizeSparsePaths(paths)
+		log.Infof("Sparse checkout of %s", strings.Join(paths, ", "))
+		return paths, nil
+	})
+}
+
+// readDevContainerFromHead reads the devcontainer.json from the cloned repository before its files are checked out
+func readDevContainerFromHead(ctx context.Context, targetDir string, extraEnv []string, subPath, devContainerPath string, log log.Logger) (string, *config.DevContainerConfig) {
+	candidates := []string{".devcontainer/devcontainer.json", ".devcontainer.json"}
+	if devContainerPath != "" {
+		candidates = []string{devContainerPath}
+	}
+
+	for _, candidate := range candidates {
+		candidate = path.Join(subPath, candidate)
+		stdout := &bytes.Buffer{}
+		gitCommand := git.CommandContext(ctx, extraEnv, "show", "HEAD:"+candidate)
+		gitCommand.Dir = targetDir
+		gitCommand.Stdout = stdout
+		err := gitCommand.Run()
+		if err != nil {
+			continue
+		}
+
+		devContainerConfig := &config.DevContainerConfig{}
+		err = json.Unmarshal(jsonc.ToJSON(stdout.Bytes()), devContainerConfig)
+		if err != nil {
+			log.Warnf("Error parsing %s: %v", candidate, err)
+			return candidate, nil
+		}
+
+		return candidate, devContainerConfig
+	}
+
+	return "", nil
+}
+
+// normalizeSparsePaths makes the paths relative to the repository root and removes duplicates
+func normalizeSparsePaths(paths []string) []string {
+	ret := []string{}
+	for _, p := range paths {
+		p = strings.ReplaceAll(strings.TrimSpace(p), "\\", "/")
+		p = strings.Trim(path.Clean("/"+p), "/")
+		if p != "" && !slices.Contains(ret, p) {
+			ret = append(ret, p)
+		}
+	}
+
+	return ret
+}
@@ -353,6 +353,10 @@ func CloneRepositoryForWorkspace(
 			return fmt.Errorf("marshal git options: %w", err)
 		}
 
+		if len(options.GitSparsePaths) > 0 || len(source.GitSparsePaths) > 0 {
+			log.Warnf("Sparse checkout is not supported with the platform git cache, cloning all directories")
+		}
+
 		// create client
 		log.Infof("Cloning repository %s in platform", source.GitRepository)
 		_, err = devpod.NewRunnerClient(grpcClient).Clone(ctx, &devpod.CloneRequest{
@@ -379,7 +383,16 @@ func CloneRepositoryForWorkspace(
 		if options.Platform.GitSkipLFS {
 			log.Info("Skipping Git LFS")
 		}
-		err := git.CloneRepositoryWithEnv(ctx, gitInfo, extraEnv, workspaceDir, helper, options.StrictHostKeyChecking, log, getGitOptions(options)...)
+		gitOpts := getGitOptions(options)
+		if sparseOption := getSparseCheckoutOption(source, options, log); sparseOption != nil {
+			gitOpts = append(gitOpts, sparseOption)
+		}
+		mirrorOption, releaseMirror := getGitMirrorOption(ctx, source, agentConfig, options, helper, extraEnv, log)
+		if mirrorOption != nil {
+			gitOpts = append(gitOpts, mirrorOption)
+		}
+		err := git.CloneRepositoryWithEnv(ctx, gitInfo, extraEnv, workspaceDir, helper, options.StrictHostKeyChecking, log, gitOpts...)
+		releaseMirror()
 		if err != nil {
 			// cleanup workspace dir if clone failed, otherwise we won't try to clone again when rebuilding this workspace
 			if cleanupErr := cleanupWorkspaceDir(workspaceDir); cleanupErr != nil {
@@ -497,3 +510,17 @@ func removeDirContents(dirPath string) error {
 
 	return nil
 }
+
+// GetAgentSnapshotsDir returns the folder where the workspace folder exports of snapshots are stored,
+// it lives outside of the workspace folder so that snapshots survive the deletion of the workspace
+func GetAgentSnapshotsDir(agentFolder, context, workspaceID string) (string, error) {
+	homeFolder, err := FindAgentHomeFolder(agentFolder)
+	if err != nil {
+		return "", err
+	}
+	if context == "" {
+		context = config.DefaultContext
+	}
+
+	return filepath.Join(homeFolder, "contexts", context, "snapshots", workspaceID), nil
+}
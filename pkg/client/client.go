@@ -99,6 +99,9 @@ type MachineClient interface {
 
 	// MachineConfig returns the machine config
 	MachineConfig() *provider.Machine
+
+	// Resize changes the cpus and memory of the machine
+	Resize(ctx context.Context, options ResizeOptions) error
 }
 
 type BaseWorkspaceClient interface {
@@ -130,6 +133,12 @@ type WorkspaceClient interface {
 
 	// AgentInfo returns the info to send to the agent
 	AgentInfo(options provider.CLIOptions) (string, *provider.AgentWorkspaceInfo, error)
+
+	// Resize changes the cpus and memory of the workspace
+	Resize(ctx context.Context, options ResizeOptions) error
+
+	// GenerateKube writes the kubernetes manifests of the workspace to stdout
+	GenerateKube(ctx context.Context, stdout io.Writer) error
 }
 
 type InitOptions struct{}
@@ -152,6 +161,14 @@ type DeleteOptions struct {
 
 type CreateOptions struct{}
 
+type ResizeOptions struct {
+	// CPUs is the new number of cpus, 0 keeps the current cpus
+	CPUs float64 `json:"cpus,omitempty"`
+
+	// Memory is the new memory in bytes, 0 keeps the current memory
+	Memory int64 `json:"memory,omitempty"`
+}
+
 type StatusOptions struct {
 	ContainerStatus bool `json:"containerStatus,omitempty"`
 }
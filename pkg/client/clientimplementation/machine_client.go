@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 	"time"
 
@@ -146,6 +147,49 @@ func (s *machineClient) Start(ctx context.Context, options client.StartOptions)
 	return nil
 }
 
+func (s *machineClient) Resize(ctx context.Context, options client.ResizeOptions) error {
+	if len(s.config.Exec.Resize) == 0 {
+		return fmt.Errorf("provider '%s' doesn't support resizing machines", s.config.Name)
+	}
+
+	done := printStillRunningLogMessagePeriodically(s.log)
+	defer close(done)
+
+	writer := s.log.Writer(logrus.InfoLevel, false)
+	defer writer.Close()
+
+	extraEnv := map[string]string{}
+	if options.CPUs > 0 {
+		extraEnv[provider.RESIZE_CPUS] = strconv.FormatFloat(options.CPUs, 'f', -1, 64)
+	}
+	if options.Memory > 0 {
+		extraEnv[provider.RESIZE_MEMORY] = strconv.FormatInt(options.Memory, 10)
+	}
+
+	s.log.Infof("Resizing machine '%s'...", s.machine.ID)
+	err := RunCommandWithBinaries(
+		ctx,
+		"resize",
+		s.config.Exec.Resize,
+		s.machine.Context,
+		nil,
+		s.machine,
+		s.devPodConfig.ProviderOptions(s.config.Name),
+		s.config,
+		extraEnv,
+		nil,
+		writer,
+		writer,
+		s.log,
+	)
+	if err != nil {
+		return err
+	}
+	s.log.Donef("Successfully resized '%s'", s.machine.ID)
+
+	return nil
+}
+
 func (s *machineClient) Stop(ctx context.Context, options client.StopOptions) error {
 	done := printStillRunningLogMessagePeriodically(s.log)
 	defer close(done)
@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"sync"
 	"time"
 
@@ -223,6 +224,9 @@ func (s *workspaceClient) agentInfo(cliOptions provider.CLIOptions) *provider.Ag
 	// Set registry cache from context option
 	agentInfo.RegistryCache = s.devPodConfig.ContextOption(config.ContextOptionRegistryCache)
 
+	// Set host requirements check from context option
+	agentInfo.HostRequirements = s.devPodConfig.ContextOption(config.ContextOptionHostRequirements)
+
 	return agentInfo
 }
 
@@ -489,6 +493,92 @@ func (s *workspaceClient) Stop(ctx context.Context, opt client.StopOptions) erro
 	return machineClient.Stop(ctx, opt)
 }
 
+func (s *workspaceClient) Resize(ctx context.Context, options client.ResizeOptions) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	// machine providers resize the whole machine if they can
+	if s.isMachineProvider() && len(s.config.Exec.Resize) > 0 {
+		machineClient, err := NewMachineClient(s.devPodConfig, s.config, s.machine, s.log)
+		if err != nil {
+			return err
+		}
+
+		return machineClient.Resize(ctx, options)
+	}
+
+	writer := s.log.Writer(logrus.InfoLevel, false)
+	defer writer.Close()
+
+	s.log.Infof("Resizing container...")
+	compressed, info, err := s.compressedAgentInfo(provider.CLIOptions{})
+	if err != nil {
+		return fmt.Errorf("agent info")
+	}
+	command := fmt.Sprintf("'%s' agent workspace resize --workspace-info '%s' --cpus %s --memory %d", info.Agent.Path, compressed, strconv.FormatFloat(options.CPUs, 'f', -1, 64), options.Memory)
+	if s.log.GetLevel() == logrus.DebugLevel {
+		command += " --debug"
+	}
+	err = RunCommandWithBinaries(
+		ctx,
+		"command",
+		s.config.Exec.Command,
+		s.workspace.Context,
+		s.workspace,
+		s.machine,
+		s.devPodConfig.ProviderOptions(s.config.Name),
+		s.config,
+		map[string]string{
+			provider.CommandEnv: command,
+		},
+		nil,
+		writer,
+		writer,
+		s.log.ErrorStreamOnly(),
+	)
+	if err != nil {
+		return err
+	}
+	s.log.Donef("Successfully resized container")
+
+	return nil
+}
+
+func (s *workspaceClient) GenerateKube(ctx context.Context, stdout io.Writer) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	writer := s.log.ErrorStreamOnly().Writer(logrus.InfoLevel, false)
+	defer writer.Close()
+
+	compressed, info, err := s.compressedAgentInfo(provider.CLIOptions{})
+	if err != nil {
+		return fmt.Errorf("agent info")
+	}
+	command := fmt.Sprintf("'%s' agent workspace generate-kube --workspace-info '%s'", info.Agent.Path, compressed)
+	if s.log.GetLevel() == logrus.DebugLevel {
+		command += " --debug"
+	}
+
+	return RunCommandWithBinaries(
+		ctx,
+		"command",
+		s.config.Exec.Command,
+		s.workspace.Context,
+		s.workspace,
+		s.machine,
+		s.devPodConfig.ProviderOptions(s.config.Name),
+		s.config,
+		map[string]string{
+			provider.CommandEnv: command,
+		},
+		nil,
+		stdout,
+		writer,
+		s.log.ErrorStreamOnly(),
+	)
+}
+
 func (s *workspaceClient) Command(ctx context.Context, commandOptions client.CommandOptions) (err error) {
 	// get environment variables
 	s.m.Lock()
@@ -22,6 +22,12 @@ const (
 	ContextOptionAgentInjectTimeout         = "AGENT_INJECT_TIMEOUT"
 	ContextOptionRegistryCache              = "REGISTRY_CACHE"
 	ContextOptionSSHStrictHostKeyChecking   = "SSH_STRICT_HOST_KEY_CHECKING"
+	ContextOptionAutoForwardPortsRange      = "AUTO_FORWARD_PORTS_RANGE"
+	ContextOptionAutoForwardPortsExclude    = "AUTO_FORWARD_PORTS_EXCLUDE"
+	ContextOptionAutoForwardPortsAllow      = "AUTO_FORWARD_PORTS_ALLOW"
+	ContextOptionHostRequirements           = "HOST_REQUIREMENTS"
+	ContextOptionGitMirrorCache             = "GIT_MIRROR_CACHE"
+	ContextOptionGitMirrorCacheMaxAge       = "GIT_MIRROR_CACHE_MAX_AGE"
 )
 
 var ContextOptions = []ContextOption{
@@ -105,6 +111,38 @@ var ContextOptions = []ContextOption{
 		Default:     "false",
 		Enum:        []string{"true", "false"},
 	},
+	{
+		Name:        ContextOptionAutoForwardPortsRange,
+		Description: "Specifies the range of ports within the workspace that are forwarded automatically",
+		Default:     "1024-12000",
+	},
+	{
+		Name:        ContextOptionAutoForwardPortsExclude,
+		Description: "Specifies a comma separated list of ports or port ranges that are never forwarded automatically, e.g. 5432,6000-6010",
+		Default:     "",
+	},
+	{
+		Name:        ContextOptionAutoForwardPortsAllow,
+		Description: "Specifies a comma separated list of ports or port ranges that are forwarded automatically instead of the port range",
+		Default:     "",
+	},
+	{
+		Name:        ContextOptionHostRequirements,
+		Description: "Specifies if DevPod should warn or fail if the cpus, memory or storage of the hostRequirements are not available",
+		Default:     "warn",
+		Enum:        []string{"warn", "fail"},
+	},
+	{
+		Name:        ContextOptionGitMirrorCache,
+		Description: "Specifies if the agent keeps a git mirror per repository on the machines of machine providers to clone new workspaces from",
+		Default:     "true",
+		Enum:        []string{"true", "false"},
+	},
+	{
+		Name:        ContextOptionGitMirrorCacheMaxAge,
+		Description: "Specifies after which duration unused git mirrors are removed from a machine, e.g. 720h",
+		Default:     "720h",
+	},
 }
 
 func MergeContextOptions(contextConfig *ContextConfig, environ []string) {
@@ -28,6 +28,13 @@ type DaemonConfig struct {
 	Platform devpod.PlatformOptions `json:"platform,omitempty"`
 	Ssh      SshConfig              `json:"ssh,omitempty"`
 	Timeout  string                 `json:"timeout"`
+
+	// ShutdownAction is the devcontainer.json shutdownAction to run after the last session disconnected
+	ShutdownAction string `json:"shutdownAction,omitempty"`
+	// ShutdownGracePeriod is the time to wait after the last session disconnected before running the shutdown action
+	ShutdownGracePeriod string `json:"shutdownGracePeriod,omitempty"`
+	// ShutdownUser is the user the sessions run as, defaults to the ssh user
+	ShutdownUser string `json:"shutdownUser,omitempty"`
 }
 
 func BuildWorkspaceDaemonConfig(platformOptions devpod.PlatformOptions, workspaceConfig *provider2.Workspace, substitutionContext *config.SubstitutionContext, mergedConfig *config.MergedDevContainerConfig) (*DaemonConfig, error) {
@@ -58,6 +65,8 @@ func BuildWorkspaceDaemonConfig(platformOptions devpod.PlatformOptions, workspac
 			Workdir: workdir,
 			User:    user,
 		},
+		ShutdownAction:      mergedConfig.ShutdownAction,
+		ShutdownGracePeriod: config.GetMergedDevPodCustomizations(mergedConfig).ShutdownGracePeriod,
 	}
 
 	return daemonConfig, nil
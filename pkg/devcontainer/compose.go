@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/exec"
 	"path"
 	"path/filepath"
 	"reflect"
@@ -19,6 +20,7 @@ import (
 	"github.com/loft-sh/devpod/pkg/devcontainer/metadata"
 	"github.com/loft-sh/devpod/pkg/dockerfile"
 	"github.com/loft-sh/devpod/pkg/driver"
+	"github.com/loft-sh/devpod/pkg/single"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v2"
@@ -186,6 +188,14 @@ func (r *runner) runDockerCompose(
 
 		// Start container if not running
 		if !didStartProject {
+			// make sure the host is able to run the containers
+			if containerDetails == nil || options.Recreate {
+				err = r.checkHostRequirements(ctx, parsedConfig.Config.HostRequirements)
+				if err != nil {
+					return nil, err
+				}
+			}
+
 			containerDetails, err = r.startContainer(ctx, parsedConfig, substitutionContext, project, composeHelper, composeGlobalArgs, containerDetails, options)
 			if err != nil {
 				return nil, errors.Wrap(err, "start container")
@@ -206,7 +216,48 @@ func (r *runner) runDockerCompose(
 	}
 
 	// setup container
-	return r.setupContainer(ctx, parsedConfig.Raw, containerDetails, mergedConfig, substitutionContext, timeout)
+	result, err := r.setupContainer(ctx, parsedConfig.Raw, containerDetails, mergedConfig, substitutionContext, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	// the other services can't be stopped from within the dev container
+	if mergedConfig.ShutdownAction == config.ShutdownActionStopCompose {
+		err = r.watchDockerComposeShutdown(composeHelper, project.Name, containerDetails.ID, composeGlobalArgs)
+		if err != nil {
+			r.Log.Warnf("Error watching the dev container, the compose project won't be stopped after the last session disconnected: %v", err)
+		}
+	}
+
+	return result, nil
+}
+
+// watchDockerComposeShutdown starts a process in the background that stops the compose project once the
+// dev container ran its shutdown action or stopped
+func (r *runner) watchDockerComposeShutdown(composeHelper *compose.ComposeHelper, projectName, containerID string, composeGlobalArgs []string) error {
+	return single.Single("devpod.compose."+projectName+"."+containerID+".pid", func() (*exec.Cmd, error) {
+		r.Log.Debugf("Start watching dev container %s to stop compose project %s", containerID, projectName)
+		binaryPath, err := os.Executable()
+		if err != nil {
+			return nil, err
+		}
+
+		args := []string{"helper", "watch-compose-shutdown", "--compose-command", composeHelper.Command, "--project-name", projectName, "--container-id", containerID}
+		if composeHelper.Docker != nil && composeHelper.Docker.DockerCommand != "" {
+			args = append(args, "--docker-command", composeHelper.Docker.DockerCommand)
+		}
+		for _, arg := range composeHelper.Args {
+			args = append(args, "--compose-arg", arg)
+		}
+		args = append(args, "--")
+		args = append(args, composeGlobalArgs...)
+
+		cmd := exec.Command(binaryPath, args...)
+		if composeHelper.Docker != nil && composeHelper.Docker.Environment != nil {
+			cmd.Env = append(os.Environ(), composeHelper.Docker.Environment...)
+		}
+		return cmd, nil
+	})
 }
 
 // onlyRunServices appends the services defined in .devcontainer.json runServices to the upArgs
@@ -484,7 +535,7 @@ func (r *runner) buildAndExtendDockerCompose(
 		return "", "", nil, "", err
 	}
 
-	extendImageBuildInfo, err := feature.GetExtendedBuildInfo(substitutionContext, imageBuildInfo, buildTarget, parsedConfig, r.Log, false)
+	extendImageBuildInfo, err := feature.GetExtendedBuildInfo(substitutionContext, imageBuildInfo, buildTarget, parsedConfig, r.Log, false, r.WorkspaceConfig.CLIOptions.UpgradeLockfile)
 	if err != nil {
 		return "", "", nil, "", err
 	}
@@ -764,6 +815,11 @@ exec "$$@"
 	}
 
 	for _, mount := range mergedConfig.Mounts {
+		if mount.IsKubernetesMount() {
+			r.Log.Warnf("Mount type '%s' of mount '%s' is only supported by the kubernetes driver, will skip", mount.Type, mount.String())
+			continue
+		}
+
 		overrideService.Volumes = append(overrideService.Volumes, composetypes.ServiceVolumeConfig{
 			Type:   mount.Type,
 			Source: mount.Source,
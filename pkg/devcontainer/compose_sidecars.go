@@ -0,0 +1,269 @@
+package devcontainer
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	composetypes "github.com/compose-spec/compose-go/v2/types"
+	"github.com/loft-sh/devpod/pkg/compose"
+	"github.com/loft-sh/devpod/pkg/devcontainer/config"
+	"github.com/loft-sh/devpod/pkg/driver"
+	"github.com/loft-sh/log"
+	"github.com/pkg/errors"
+)
+
+// runComposeWithSidecars runs a docker compose project on drivers without docker compose. The primary service
+// becomes the devcontainer and all other services run as sidecars sharing its network.
+func (r *runner) runComposeWithSidecars(
+	ctx context.Context,
+	parsedConfig *config.SubstitutedConfig,
+	substitutionContext *config.SubstitutionContext,
+	options UpOptions,
+	timeout time.Duration,
+) (*config.Result, error) {
+	composeFiles, envFiles, _, err := r.dockerComposeProjectFiles(parsedConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "get compose/env files")
+	}
+
+	r.Log.Debugf("Loading docker compose project %+v", composeFiles)
+	project, err := compose.LoadDockerComposeProject(ctx, composeFiles, envFiles)
+	if err != nil {
+		return nil, errors.Wrap(err, "load docker compose project")
+	}
+
+	devContainerConfig, sidecars, err := composeProjectToSidecars(project, parsedConfig.Config, r.ID, r.LocalWorkspaceFolder, r.Log)
+	if err != nil {
+		return nil, err
+	}
+
+	options.sidecars = sidecars
+	return r.runSingleContainer(ctx, &config.SubstitutedConfig{
+		Config: devContainerConfig,
+		Raw:    parsedConfig.Raw,
+	}, substitutionContext, options, timeout)
+}
+
+// composeProjectToSidecars converts the primary service of the project into an image or Dockerfile based
+// devcontainer config and the services it should run with into sidecars. Named volumes are scoped to the
+// workspace like docker compose scopes them to the project.
+func composeProjectToSidecars(
+	project *composetypes.Project,
+	devContainerConfig *config.DevContainerConfig,
+	workspaceID string,
+	localWorkspaceFolder string,
+	log log.Logger,
+) (*config.DevContainerConfig, []*driver.SidecarOptions, error) {
+	primary, err := project.GetService(devContainerConfig.Service)
+	if err != nil {
+		return nil, nil, fmt.Errorf("service '%s' configured in devcontainer.json not found in Docker Compose configuration", devContainerConfig.Service)
+	}
+
+	newConfig := config.CloneDevContainerConfig(devContainerConfig)
+	newConfig.ComposeContainer = config.ComposeContainer{}
+	if primary.Build != nil {
+		configDir := filepath.Dir(devContainerConfig.Origin)
+		dockerfile := primary.Build.Dockerfile
+		if !filepath.IsAbs(dockerfile) {
+			dockerfile = filepath.Join(primary.Build.Context, dockerfile)
+		}
+		dockerfile, err = filepath.Rel(configDir, dockerfile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("resolve dockerfile of service %s: %w", primary.Name, err)
+		}
+		buildContext, err := filepath.Rel(configDir, primary.Build.Context)
+		if err != nil {
+			return nil, nil, fmt.Errorf("resolve build context of service %s: %w", primary.Name, err)
+		}
+
+		newConfig.ImageContainer = config.ImageContainer{}
+		newConfig.DockerfileContainer = config.DockerfileContainer{
+			Build: &config.ConfigBuildOptions{
+				Dockerfile: filepath.ToSlash(dockerfile),
+				Context:    filepath.ToSlash(buildContext),
+				Target:     primary.Build.Target,
+				Args:       mappingToMap(primary.Build.Args),
+			},
+		}
+	} else if primary.Image != "" {
+		newConfig.ImageContainer = config.ImageContainer{Image: primary.Image}
+	} else {
+		return nil, nil, fmt.Errorf("service %s needs either an image or a build section", primary.Name)
+	}
+
+	// devcontainer.json settings take precedence over the service
+	containerEnv := composeEnvironment(primary.Environment)
+	for k, v := range newConfig.ContainerEnv {
+		containerEnv[k] = v
+	}
+	newConfig.ContainerEnv = containerEnv
+	if newConfig.ContainerUser == "" {
+		newConfig.ContainerUser = primary.User
+	}
+	if newConfig.Privileged == nil && primary.Privileged {
+		newConfig.Privileged = &primary.Privileged
+	}
+	if newConfig.Init == nil && primary.Init != nil {
+		newConfig.Init = primary.Init
+	}
+	newConfig.CapAdd = append(newConfig.CapAdd, primary.CapAdd...)
+	for _, volume := range primary.Volumes {
+		switch {
+		case volume.Type == composetypes.VolumeTypeVolume && volume.Source != "":
+			newConfig.Mounts = append(newConfig.Mounts, &config.Mount{
+				Type:   "volume",
+				Source: composeVolumeName(project, workspaceID, volume.Source),
+				Target: volume.Target,
+			})
+		case volume.Type == composetypes.VolumeTypeBind && isParentOrSame(volume.Source, localWorkspaceFolder):
+			// the workspace mount already takes care of this
+		default:
+			log.Warnf("Skipping %s mount %s of service %s, it is not supported by this provider", volume.Type, volume.Target, primary.Name)
+		}
+	}
+
+	// run either all services or the selected ones with their dependencies
+	selected := project
+	if len(devContainerConfig.RunServices) > 0 {
+		selected, err = project.WithSelectedServices(append([]string{primary.Name}, devContainerConfig.RunServices...), composetypes.IncludeDependencies)
+		if err != nil {
+			return nil, nil, fmt.Errorf("select run services: %w", err)
+		}
+	}
+
+	sidecars := []*driver.SidecarOptions{}
+	for _, name := range sortedServiceNames(selected) {
+		if name == primary.Name {
+			continue
+		}
+
+		sidecar, err := composeServiceToSidecar(selected.Services[name], func(source string) string {
+			return composeVolumeName(project, workspaceID, source)
+		}, log)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		sidecars = append(sidecars, sidecar)
+	}
+
+	return newConfig, sidecars, nil
+}
+
+// composeVolumeName returns the name of a named volume within the workspace. External volumes and volumes
+// with an explicit name are shared, all others are prefixed with the workspace id.
+func composeVolumeName(project *composetypes.Project, workspaceID, source string) string {
+	volume, ok := project.Volumes[source]
+	if ok && bool(volume.External) {
+		if volume.Name != "" {
+			return volume.Name
+		}
+
+		return source
+	} else if ok && volume.Name != "" && volume.Name != project.Name+"_"+source {
+		return volume.Name
+	}
+
+	return workspaceID + "_" + source
+}
+
+func composeServiceToSidecar(service composetypes.ServiceConfig, volumeName func(source string) string, log log.Logger) (*driver.SidecarOptions, error) {
+	if service.Image == "" {
+		return nil, fmt.Errorf("service %s needs an image, building sidecar images is not supported by this provider", service.Name)
+	} else if service.Build != nil {
+		log.Warnf("Service %s has a build section, using the image %s without building it", service.Name, service.Image)
+	}
+
+	sidecar := &driver.SidecarOptions{
+		Name:       service.Name,
+		Image:      service.Image,
+		User:       service.User,
+		Entrypoint: service.Entrypoint,
+		Cmd:        service.Command,
+		Env:        composeEnvironment(service.Environment),
+		CapAdd:     service.CapAdd,
+	}
+	if service.Privileged {
+		sidecar.Privileged = &service.Privileged
+	}
+	for _, volume := range service.Volumes {
+		source := volume.Source
+		if volume.Type == composetypes.VolumeTypeVolume && source != "" {
+			source = volumeName(source)
+		}
+
+		sidecar.Mounts = append(sidecar.Mounts, &config.Mount{
+			Type:   volume.Type,
+			Source: source,
+			Target: volume.Target,
+		})
+	}
+	for _, tmpfs := range service.Tmpfs {
+		sidecar.Mounts = append(sidecar.Mounts, &config.Mount{
+			Type:   composetypes.VolumeTypeTmpfs,
+			Target: strings.Split(tmpfs, ":")[0],
+		})
+	}
+	sidecar.Healthcheck = composeHealthcheck(service.HealthCheck)
+
+	return sidecar, nil
+}
+
+func composeHealthcheck(healthCheck *composetypes.HealthCheckConfig) *driver.Healthcheck {
+	if healthCheck == nil || healthCheck.Disable || len(healthCheck.Test) == 0 {
+		return nil
+	}
+
+	ret := &driver.Healthcheck{}
+	switch healthCheck.Test[0] {
+	case "CMD":
+		ret.Command = healthCheck.Test[1:]
+	case "CMD-SHELL":
+		ret.Command = []string{"/bin/sh", "-c", strings.Join(healthCheck.Test[1:], " ")}
+	default:
+		// NONE disables the healthcheck of the image
+		return nil
+	}
+	if healthCheck.Interval != nil {
+		ret.Interval = time.Duration(*healthCheck.Interval)
+	}
+	if healthCheck.Timeout != nil {
+		ret.Timeout = time.Duration(*healthCheck.Timeout)
+	}
+	if healthCheck.Retries != nil {
+		ret.Retries = int(*healthCheck.Retries)
+	}
+
+	return ret
+}
+
+func composeEnvironment(mapping composetypes.MappingWithEquals) map[string]string {
+	env := map[string]string{}
+	for k, v := range mapping {
+		// variables without a value are unset
+		if v != nil {
+			env[k] = *v
+		}
+	}
+
+	return env
+}
+
+func sortedServiceNames(project *composetypes.Project) []string {
+	names := []string{}
+	for name := range project.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func isParentOrSame(parent, child string) bool {
+	rel, err := filepath.Rel(parent, child)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
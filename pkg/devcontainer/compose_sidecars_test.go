@@ -0,0 +1,99 @@
+package devcontainer
+
+import (
+	"testing"
+	"time"
+
+	composetypes "github.com/compose-spec/compose-go/v2/types"
+	"github.com/loft-sh/devpod/pkg/devcontainer/config"
+	"github.com/loft-sh/log"
+	"gotest.tools/assert"
+)
+
+func TestComposeProjectToSidecars(t *testing.T) {
+	password := "secret"
+	interval := composetypes.Duration(5 * time.Second)
+	retries := uint64(10)
+	project := &composetypes.Project{
+		Name: "repo",
+		Volumes: composetypes.Volumes{
+			"pgdata": {Name: "repo_pgdata"},
+			"shared": {Name: "shared", External: true},
+		},
+		Services: composetypes.Services{
+			"app": {
+				Name:        "app",
+				Build:       &composetypes.BuildConfig{Context: "/repo", Dockerfile: ".devcontainer/Dockerfile"},
+				Environment: composetypes.MappingWithEquals{"DATABASE_HOST": &[]string{"localhost"}[0], "EDITOR": &[]string{"nano"}[0]},
+				Volumes: []composetypes.ServiceVolumeConfig{
+					{Type: composetypes.VolumeTypeBind, Source: "/", Target: "/workspaces"},
+					{Type: composetypes.VolumeTypeVolume, Source: "cache", Target: "/cache"},
+				},
+			},
+			"db": {
+				Name:        "db",
+				Image:       "postgres:16",
+				Environment: composetypes.MappingWithEquals{"POSTGRES_PASSWORD": &password, "UNSET": nil},
+				Volumes: []composetypes.ServiceVolumeConfig{
+					{Type: composetypes.VolumeTypeVolume, Source: "pgdata", Target: "/var/lib/postgresql/data"},
+					{Type: composetypes.VolumeTypeBind, Source: "/repo/init.sql", Target: "/docker-entrypoint-initdb.d/init.sql"},
+					{Type: composetypes.VolumeTypeVolume, Source: "shared", Target: "/shared"},
+				},
+				HealthCheck: &composetypes.HealthCheckConfig{
+					Test:     composetypes.HealthCheckTest{"CMD-SHELL", "pg_isready -U postgres"},
+					Interval: &interval,
+					Retries:  &retries,
+				},
+			},
+			"redis": {
+				Name:  "redis",
+				Image: "redis:7",
+			},
+		},
+	}
+
+	devContainerConfig := &config.DevContainerConfig{
+		ComposeContainer: config.ComposeContainer{
+			DockerComposeFile: []string{"docker-compose.yml"},
+			Service:           "app",
+		},
+		NonComposeBase: config.NonComposeBase{
+			ContainerEnv: map[string]string{"EDITOR": "vim"},
+		},
+		Origin: "/repo/.devcontainer/devcontainer.json",
+	}
+
+	newConfig, sidecars, err := composeProjectToSidecars(project, devContainerConfig, "my-workspace", "/repo", log.Discard)
+	assert.NilError(t, err)
+	assert.Equal(t, len(newConfig.DockerComposeFile), 0)
+	assert.Equal(t, newConfig.Build.Dockerfile, "Dockerfile")
+	assert.Equal(t, newConfig.Build.Context, "..")
+	assert.DeepEqual(t, newConfig.ContainerEnv, map[string]string{"DATABASE_HOST": "localhost", "EDITOR": "vim"})
+	assert.Equal(t, len(newConfig.Mounts), 1)
+	assert.Equal(t, newConfig.Mounts[0].Source, "my-workspace_cache")
+
+	assert.Equal(t, len(sidecars), 2)
+	assert.Equal(t, sidecars[0].Name, "db")
+	assert.DeepEqual(t, sidecars[0].Env, map[string]string{"POSTGRES_PASSWORD": "secret"})
+	assert.Equal(t, len(sidecars[0].Mounts), 3)
+	assert.Equal(t, sidecars[0].Mounts[0].Source, "my-workspace_pgdata")
+	assert.Equal(t, sidecars[0].Mounts[1].Source, "/repo/init.sql")
+	assert.Equal(t, sidecars[0].Mounts[2].Source, "shared")
+	assert.DeepEqual(t, sidecars[0].Healthcheck.Command, []string{"/bin/sh", "-c", "pg_isready -U postgres"})
+	assert.Equal(t, sidecars[0].Healthcheck.Interval, 5*time.Second)
+	assert.Equal(t, sidecars[0].Healthcheck.Retries, 10)
+	assert.Equal(t, sidecars[1].Name, "redis")
+
+	// only run the selected services
+	devContainerConfig.RunServices = []string{"db"}
+	_, sidecars, err = composeProjectToSidecars(project, devContainerConfig, "my-workspace", "/repo", log.Discard)
+	assert.NilError(t, err)
+	assert.Equal(t, len(sidecars), 1)
+	assert.Equal(t, sidecars[0].Name, "db")
+
+	// sidecars can't be built
+	project.Services["redis"] = composetypes.ServiceConfig{Name: "redis", Build: &composetypes.BuildConfig{Context: "/repo/redis"}}
+	devContainerConfig.RunServices = nil
+	_, _, err = composeProjectToSidecars(project, devContainerConfig, "my-workspace", "/repo", log.Discard)
+	assert.ErrorContains(t, err, "service redis needs an image")
+}
@@ -43,6 +43,15 @@ func CloneDevContainerConfig(config *DevContainerConfig) *DevContainerConfig {
 	return out
 }
 
+const (
+	// ShutdownActionNone keeps the dev container running after the last session disconnected
+	ShutdownActionNone = "none"
+	// ShutdownActionStopContainer stops the dev container after the last session disconnected
+	ShutdownActionStopContainer = "stopContainer"
+	// ShutdownActionStopCompose stops the docker compose services after the last session disconnected
+	ShutdownActionStopCompose = "stopCompose"
+)
+
 type DevContainerConfigBase struct {
 	// A name for the dev container which can be displayed to the user.
 	Name string `json:"name,omitempty"`
@@ -57,7 +66,7 @@ type DevContainerConfigBase struct {
 	ForwardPorts types.StrIntArray `json:"forwardPorts,omitempty"`
 
 	// Set default properties that are applied when a specific port number is forwarded.
-	PortsAttributes map[string]PortAttribute `json:"portAttributes,omitempty"`
+	PortsAttributes map[string]PortAttribute `json:"portsAttributes,omitempty"`
 
 	// Set default properties that are applied to all ports that don't get properties from the setting `remote.portsAttributes`.
 	OtherPortsAttributes *PortAttribute `json:"otherPortsAttributes,omitempty"`
@@ -327,6 +336,24 @@ type PortAttribute struct {
 type DevPodCustomizations struct {
 	PrebuildRepository         types.StrArray    `json:"prebuildRepository,omitempty"`
 	FeatureDownloadHTTPHeaders map[string]string `json:"featureDownloadHTTPHeaders,omitempty"`
+	ShutdownGracePeriod        string            `json:"shutdownGracePeriod,omitempty"`
+
+	// UpdateContentFiles are files relative to the workspace folder, e.g. lockfiles, that rerun the
+	// updateContentCommand when changed
+	UpdateContentFiles types.StrArray `json:"updateContentFiles,omitempty"`
+
+	// AutoForwardPortsRange is the range of ports that are forwarded automatically, e.g. 1024-12000
+	AutoForwardPortsRange string `json:"autoForwardPortsRange,omitempty"`
+
+	// AutoForwardPortsExclude are ports or port ranges that are never forwarded automatically
+	AutoForwardPortsExclude types.StrArray `json:"autoForwardPortsExclude,omitempty"`
+
+	// AutoForwardPortsAllow are ports or port ranges that are forwarded automatically instead of the range
+	AutoForwardPortsAllow types.StrArray `json:"autoForwardPortsAllow,omitempty"`
+
+	// GitSparsePaths are directories relative to the repository root that are checked out in addition
+	// to the ones of --git-sparse-path. If set, all other directories are skipped.
+	GitSparsePaths types.StrArray `json:"gitSparsePaths,omitempty"`
 }
 
 type VSCodeCustomizations struct {
@@ -335,6 +362,14 @@ type VSCodeCustomizations struct {
 	DevPort    int                    `json:"devPort,omitempty"`
 }
 
+// Mount types that are only supported by the kubernetes driver
+const (
+	MountTypeKubernetesSecret    = "k8s-secret"
+	MountTypeKubernetesConfigMap = "k8s-configmap"
+	MountTypeKubernetesPVC       = "k8s-pvc"
+	MountTypeEmptyDir            = "emptyDir"
+)
+
 type Mount struct {
 	Type     string   `json:"type,omitempty"`
 	Source   string   `json:"source,omitempty"`
@@ -361,6 +396,16 @@ func (m *Mount) String() string {
 	return strings.Join(components, ",")
 }
 
+// IsKubernetesMount returns true if the mount is a secret, config map, persistent volume claim or empty dir
+func (m *Mount) IsKubernetesMount() bool {
+	switch m.Type {
+	case MountTypeKubernetesSecret, MountTypeKubernetesConfigMap, MountTypeKubernetesPVC, MountTypeEmptyDir:
+		return true
+	}
+
+	return false
+}
+
 func GetContextPath(parsedConfig *DevContainerConfig) string {
 	context := parsedConfig.GetContext()
 	dockerfilePath := parsedConfig.GetDockerfile()
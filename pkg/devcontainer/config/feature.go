@@ -58,6 +58,9 @@ type FeatureConfig struct {
 	// Array of ID's of Features that should execute before this one. Allows control for feature authors on soft dependencies between different Features.
 	InstallsAfter []string `json:"installsAfter,omitempty"`
 
+	// An object of Feature dependencies that must be satisfied before this Feature is installed. Elements follow the same semantics of the features object in devcontainer.json.
+	DependsOn map[string]interface{} `json:"dependsOn,omitempty"`
+
 	// Container environment variables.
 	ContainerEnv map[string]string `json:"containerEnv,omitempty"`
 
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"github.com/docker/go-units"
+)
+
+// IsEmpty returns true if no cpu, memory or storage requirements are specified
+func (h *HostRequirements) IsEmpty() bool {
+	return h == nil || (h.CPUs == 0 && h.Memory == "" && h.Storage == "")
+}
+
+// GetMemory returns the required memory in bytes or 0 if not specified
+func (h *HostRequirements) GetMemory() (int64, error) {
+	return parseHostRequirementsSize(h.Memory)
+}
+
+// GetStorage returns the required storage in bytes or 0 if not specified
+func (h *HostRequirements) GetStorage() (int64, error) {
+	return parseHostRequirementsSize(h.Storage)
+}
+
+func parseHostRequirementsSize(size string) (int64, error) {
+	if size == "" {
+		return 0, nil
+	}
+
+	return units.RAMInBytes(size)
+}
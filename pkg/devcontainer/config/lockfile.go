@@ -0,0 +1,101 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tidwall/jsonc"
+)
+
+const DEVCONTAINER_LOCKFILE_NAME = "devcontainer-lock.json"
+
+// Lockfile pins the features of a devcontainer.json to an exact version
+type Lockfile struct {
+	// Features maps the feature id as referenced in the devcontainer.json to the resolved feature
+	Features map[string]*LockedFeature `json:"features"`
+}
+
+type LockedFeature struct {
+	// Version is the version of the feature as specified in its devcontainer-feature.json
+	Version string `json:"version,omitempty"`
+
+	// Resolved is the immutable reference the feature was resolved to, e.g. an OCI digest reference or the tarball url
+	Resolved string `json:"resolved,omitempty"`
+
+	// Integrity is the digest of the feature tarball, e.g. sha256:...
+	Integrity string `json:"integrity,omitempty"`
+
+	// DependsOn are the ids of the features this feature depends on
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// Equal returns true if both lockfiles pin the same features
+func (l *Lockfile) Equal(other *Lockfile) bool {
+	if l == nil || other == nil {
+		return l == other
+	}
+
+	return reflect.DeepEqual(l.Features, other.Features)
+}
+
+// GetLockfilePath returns the path of the lockfile that belongs to the given devcontainer.json
+func GetLockfilePath(config *DevContainerConfig) string {
+	if config.Origin == "" {
+		return ""
+	}
+
+	// .devcontainer.json uses .devcontainer-lock.json
+	name := DEVCONTAINER_LOCKFILE_NAME
+	if strings.HasPrefix(filepath.Base(config.Origin), ".") {
+		name = "." + name
+	}
+
+	return filepath.Join(filepath.Dir(config.Origin), name)
+}
+
+// ParseLockfile reads the lockfile next to the given devcontainer.json, if there is none nil is returned
+func ParseLockfile(config *DevContainerConfig) (*Lockfile, error) {
+	path := GetLockfilePath(config)
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	lockfile := &Lockfile{}
+	err = json.Unmarshal(jsonc.ToJSON(data), lockfile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse %s", path)
+	}
+	if lockfile.Features == nil {
+		lockfile.Features = map[string]*LockedFeature{}
+	}
+
+	return lockfile, nil
+}
+
+// SaveLockfile writes the lockfile next to the given devcontainer.json
+func SaveLockfile(config *DevContainerConfig, lockfile *Lockfile) error {
+	path := GetLockfilePath(config)
+	if path == "" {
+		return errors.New("no origin in config")
+	}
+
+	out, err := json.MarshalIndent(lockfile, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(out, '\n'), 0644)
+}
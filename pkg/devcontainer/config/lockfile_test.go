@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestGetLockfilePath(t *testing.T) {
+	assert.Equal(t, GetLockfilePath(&DevContainerConfig{}), "")
+	assert.Equal(t, GetLockfilePath(&DevContainerConfig{Origin: "/repo/.devcontainer/devcontainer.json"}), filepath.Join("/repo/.devcontainer", "devcontainer-lock.json"))
+	assert.Equal(t, GetLockfilePath(&DevContainerConfig{Origin: "/repo/.devcontainer.json"}), filepath.Join("/repo", ".devcontainer-lock.json"))
+}
+
+func TestLockfile(t *testing.T) {
+	devContainerConfig := &DevContainerConfig{Origin: filepath.Join(t.TempDir(), "devcontainer.json")}
+
+	// no lockfile yet
+	lockfile, err := ParseLockfile(devContainerConfig)
+	assert.NilError(t, err)
+	assert.Assert(t, lockfile == nil)
+
+	expected := &Lockfile{Features: map[string]*LockedFeature{
+		"ghcr.io/devcontainers/features/go:1": {
+			Version:   "1.3.0",
+			Resolved:  "ghcr.io/devcontainers/features/go@sha256:abc",
+			Integrity: "sha256:def",
+			DependsOn: []string{"ghcr.io/devcontainers/features/common-utils"},
+		},
+	}}
+	err = SaveLockfile(devContainerConfig, expected)
+	assert.NilError(t, err)
+
+	lockfile, err = ParseLockfile(devContainerConfig)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, lockfile, expected)
+	assert.Assert(t, lockfile.Equal(expected))
+	assert.Assert(t, !lockfile.Equal(&Lockfile{Features: map[string]*LockedFeature{}}))
+	assert.Assert(t, !lockfile.Equal(nil))
+
+	// lockfiles may contain comments like the devcontainer.json
+	err = os.WriteFile(GetLockfilePath(devContainerConfig), []byte("{\n  // pinned\n  \"features\": {}\n}"), 0644)
+	assert.NilError(t, err)
+	lockfile, err = ParseLockfile(devContainerConfig)
+	assert.NilError(t, err)
+	assert.Equal(t, len(lockfile.Features), 0)
+
+	err = os.WriteFile(GetLockfilePath(devContainerConfig), []byte("{"), 0644)
+	assert.NilError(t, err)
+	_, err = ParseLockfile(devContainerConfig)
+	assert.ErrorContains(t, err, "parse")
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"strconv"
+
+	portpkg "github.com/loft-sh/devpod/pkg/port"
+)
+
+const (
+	OnAutoForwardNotify      = "notify"
+	OnAutoForwardOpenBrowser = "openBrowser"
+	OnAutoForwardOpenPreview = "openPreview"
+	OnAutoForwardSilent      = "silent"
+	OnAutoForwardIgnore      = "ignore"
+)
+
+// GetPortAttribute returns the attributes for the given port. Ports that are not matched by portsAttributes
+// get the otherPortsAttributes. If neither match, an empty attribute is returned.
+func GetPortAttribute(portsAttributes map[string]PortAttribute, otherPortsAttributes *PortAttribute, port int) PortAttribute {
+	// exact matches take precedence over ranges
+	if attribute, ok := portsAttributes[strconv.Itoa(port)]; ok {
+		return attribute
+	}
+	for key, attribute := range portsAttributes {
+		if portMatches(key, port) {
+			return attribute
+		}
+	}
+
+	if otherPortsAttributes != nil {
+		return *otherPortsAttributes
+	}
+
+	return PortAttribute{}
+}
+
+// GetOnAutoForward returns the onAutoForward action of the attribute or the default notify
+func (p PortAttribute) GetOnAutoForward() string {
+	if p.OnAutoForward == "" {
+		return OnAutoForwardNotify
+	}
+
+	return p.OnAutoForward
+}
+
+// portMatches checks if the portsAttributes key, either a single port or a range such as 3000-3010, matches the port
+func portMatches(key string, port int) bool {
+	portRange, err := portpkg.ParseRange(key)
+	if err != nil {
+		return false
+	}
+
+	return portRange.Contains(port)
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestGetPortAttribute(t *testing.T) {
+	portsAttributes := map[string]PortAttribute{
+		"5432":      {Label: "Database", OnAutoForward: OnAutoForwardIgnore},
+		"3000-3010": {Label: "Frontend"},
+		"3005":      {Label: "Exact"},
+		"invalid":   {Label: "Invalid"},
+	}
+	otherPortsAttributes := &PortAttribute{OnAutoForward: OnAutoForwardSilent}
+
+	tests := []struct {
+		name                 string
+		port                 int
+		otherPortsAttributes *PortAttribute
+		wantLabel            string
+		wantOnAutoForward    string
+	}{
+		{name: "exact", port: 5432, wantLabel: "Database", wantOnAutoForward: OnAutoForwardIgnore},
+		{name: "range", port: 3010, wantLabel: "Frontend", wantOnAutoForward: OnAutoForwardNotify},
+		{name: "exact before range", port: 3005, wantLabel: "Exact", wantOnAutoForward: OnAutoForwardNotify},
+		{name: "other ports", port: 8080, otherPortsAttributes: otherPortsAttributes, wantOnAutoForward: OnAutoForwardSilent},
+		{name: "default", port: 8080, wantOnAutoForward: OnAutoForwardNotify},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attribute := GetPortAttribute(portsAttributes, tt.otherPortsAttributes, tt.port)
+			assert.Equal(t, attribute.Label, tt.wantLabel)
+			assert.Equal(t, attribute.GetOnAutoForward(), tt.wantOnAutoForward)
+		})
+	}
+}
@@ -56,6 +56,34 @@ func GetDevPodCustomizations(parsedConfig *DevContainerConfig) *DevPodCustomizat
 	return devPod
 }
 
+func GetMergedDevPodCustomizations(mergedConfig *MergedDevContainerConfig) *DevPodCustomizations {
+	if mergedConfig.Customizations == nil || mergedConfig.Customizations["devpod"] == nil {
+		return &DevPodCustomizations{}
+	}
+
+	// later customizations override earlier ones
+	merged := map[string]interface{}{}
+	for _, customization := range mergedConfig.Customizations["devpod"] {
+		values := map[string]interface{}{}
+		err := Convert(customization, &values)
+		if err != nil {
+			continue
+		}
+
+		for k, v := range values {
+			merged[k] = v
+		}
+	}
+
+	devPod := &DevPodCustomizations{}
+	err := Convert(merged, devPod)
+	if err != nil {
+		return &DevPodCustomizations{}
+	}
+
+	return devPod
+}
+
 func GetVSCodeConfiguration(mergedConfig *MergedDevContainerConfig) *VSCodeCustomizations {
 	if mergedConfig.Customizations == nil || mergedConfig.Customizations["vscode"] == nil {
 		return &VSCodeCustomizations{}
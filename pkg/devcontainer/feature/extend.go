@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -51,8 +52,8 @@ type BuildInfo struct {
 	BuildArgs               map[string]string
 }
 
-func GetExtendedBuildInfo(ctx *config.SubstitutionContext, imageBuildInfo *config.ImageBuildInfo, target string, devContainerConfig *config.SubstitutedConfig, log log.Logger, forceBuild bool) (*ExtendedBuildInfo, error) {
-	features, err := fetchFeatures(devContainerConfig.Config, log, forceBuild)
+func GetExtendedBuildInfo(ctx *config.SubstitutionContext, imageBuildInfo *config.ImageBuildInfo, target string, devContainerConfig *config.SubstitutedConfig, log log.Logger, forceBuild, upgradeLockfile bool) (*ExtendedBuildInfo, error) {
+	features, err := fetchFeatures(devContainerConfig.Config, log, forceBuild, upgradeLockfile)
 	if err != nil {
 		return nil, errors.Wrap(err, "fetch features")
 	}
@@ -228,32 +229,69 @@ func findContainerUsers(baseImageMetadata *config.ImageMetadataConfig, composeSe
 	return containerUser, remoteUser
 }
 
-func fetchFeatures(devContainerConfig *config.DevContainerConfig, log log.Logger, forceBuild bool) ([]*config.FeatureSet, error) {
+func fetchFeatures(devContainerConfig *config.DevContainerConfig, log log.Logger, forceBuild, upgradeLockfile bool) ([]*config.FeatureSet, error) {
+	lockfile, err := config.ParseLockfile(devContainerConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// when upgrading we resolve all features again
+	lockedFeatures := map[string]*config.LockedFeature{}
+	if lockfile != nil && !upgradeLockfile {
+		lockedFeatures = lockfile.Features
+	}
+	newLockfile := &config.Lockfile{Features: map[string]*config.LockedFeature{}}
+
 	featureSets := []*config.FeatureSet{}
+	lookup := map[string]*config.FeatureSet{}
 	for featureID, featureOptions := range devContainerConfig.Features {
-		featureFolder, err := ProcessFeatureID(featureID, devContainerConfig, log, forceBuild)
+		featureSet, lockedFeature, err := fetchFeature(featureID, featureOptions, devContainerConfig, lockedFeatures[featureID], log, forceBuild, upgradeLockfile)
 		if err != nil {
-			return nil, errors.Wrap(err, "process feature "+featureID)
+			return nil, err
 		}
-
-		// parse feature
-		log.Debugf("Parse dev container feature in %s", featureFolder)
-		featureConfig, err := config.ParseDevContainerFeature(featureFolder)
-		if err != nil {
-			return nil, errors.Wrap(err, "parse feature "+featureID)
+		if lockedFeature != nil {
+			newLockfile.Features[featureID] = lockedFeature
 		}
 
 		// add to return array
-		featureSets = append(featureSets, &config.FeatureSet{
-			ConfigID: NormalizeFeatureID(featureID),
-			Folder:   featureFolder,
-			Config:   featureConfig,
-			Options:  featureOptions,
-		})
+		featureSets = append(featureSets, featureSet)
+		lookup[featureSet.ConfigID] = featureSet
+	}
+
+	// resolve dependencies, newly added features are appended and resolved as well
+	for i := 0; i < len(featureSets); i++ {
+		for dependencyID, dependencyOptions := range featureSets[i].Config.DependsOn {
+			existingFeature, ok := lookup[NormalizeFeatureID(dependencyID)]
+			if ok {
+				existingFeature.Options = mergeFeatureOptions(existingFeature.Config, dependencyOptions, existingFeature.Options)
+				continue
+			}
+
+			log.Debugf("Resolve feature %s as dependency of %s", dependencyID, featureSets[i].ConfigID)
+			featureSet, lockedFeature, err := fetchFeature(dependencyID, dependencyOptions, devContainerConfig, lockedFeatures[dependencyID], log, forceBuild, upgradeLockfile)
+			if err != nil {
+				return nil, errors.Wrapf(err, "resolve dependency of feature %s", featureSets[i].ConfigID)
+			}
+			if lockedFeature != nil {
+				newLockfile.Features[dependencyID] = lockedFeature
+			}
+
+			featureSets = append(featureSets, featureSet)
+			lookup[featureSet.ConfigID] = featureSet
+		}
+	}
+
+	// update the lockfile if something has changed
+	if (lockfile != nil || len(newLockfile.Features) > 0) && !newLockfile.Equal(lockfile) {
+		log.Infof("Write feature lockfile %s", config.GetLockfilePath(devContainerConfig))
+		err = config.SaveLockfile(devContainerConfig, newLockfile)
+		if err != nil {
+			log.Warnf("Error writing feature lockfile: %v", err)
+		}
 	}
 
 	// compute order here
-	featureSets, err := computeFeatureOrder(devContainerConfig, featureSets)
+	featureSets, err = computeFeatureOrder(devContainerConfig, featureSets)
 	if err != nil {
 		return nil, errors.Wrap(err, "compute feature order")
 	}
@@ -261,6 +299,40 @@ func fetchFeatures(devContainerConfig *config.DevContainerConfig, log log.Logger
 	return featureSets, nil
 }
 
+func fetchFeature(featureID string, featureOptions interface{}, devContainerConfig *config.DevContainerConfig, lockedFeature *config.LockedFeature, log log.Logger, forceBuild, upgradeLockfile bool) (*config.FeatureSet, *config.LockedFeature, error) {
+	featureFolder, resolvedFeature, err := ProcessFeatureID(featureID, devContainerConfig, lockedFeature, log, forceBuild, upgradeLockfile)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "process feature "+featureID)
+	}
+
+	// parse feature
+	log.Debugf("Parse dev container feature in %s", featureFolder)
+	featureConfig, err := config.ParseDevContainerFeature(featureFolder)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "parse feature "+featureID)
+	}
+
+	// local features are not locked
+	if resolvedFeature != nil {
+		resolvedFeature = &config.LockedFeature{
+			Version:   featureConfig.Version,
+			Resolved:  resolvedFeature.Resolved,
+			Integrity: resolvedFeature.Integrity,
+		}
+		for dependencyID := range featureConfig.DependsOn {
+			resolvedFeature.DependsOn = append(resolvedFeature.DependsOn, dependencyID)
+		}
+		sort.Strings(resolvedFeature.DependsOn)
+	}
+
+	return &config.FeatureSet{
+		ConfigID: NormalizeFeatureID(featureID),
+		Folder:   featureFolder,
+		Config:   featureConfig,
+		Options:  featureOptions,
+	}, resolvedFeature, nil
+}
+
 func NormalizeFeatureID(featureID string) string {
 	ref, err := name.ParseReference(featureID)
 	if err != nil {
@@ -303,11 +375,24 @@ func computeFeatureOrder(devContainer *config.DevContainerConfig, features []*co
 	}
 
 	orderedFeatures = append(orderedFeatures, automaticOrder...)
+
+	// make sure the override order doesn't break hard dependencies
+	installed := map[string]bool{}
+	for _, feature := range orderedFeatures {
+		for dependencyID := range feature.Config.DependsOn {
+			if !installed[NormalizeFeatureID(dependencyID)] {
+				return nil, fmt.Errorf("overrideFeatureInstallOrder installs feature %s before its dependency %s", feature.ConfigID, dependencyID)
+			}
+		}
+
+		installed[feature.ConfigID] = true
+	}
+
 	return orderedFeatures, nil
 }
 
 func computeAutomaticFeatureOrder(features []*config.FeatureSet) ([]*config.FeatureSet, error) {
-	g := graph.NewGraph[*config.FeatureSet](graph.NewNode[*config.FeatureSet]("root", nil))
+	g := graph.NewGraphOf[*config.FeatureSet](graph.NewNode[*config.FeatureSet]("root", nil), "feature dependency")
 
 	// build lookup map
 	lookup := map[string]*config.FeatureSet{}
@@ -322,7 +407,21 @@ func computeAutomaticFeatureOrder(features []*config.FeatureSet) ([]*config.Feat
 			return nil, err
 		}
 
-		// add edges
+		// add hard dependency edges
+		for dependencyID := range feature.Config.DependsOn {
+			dependencyFeature, ok := lookup[NormalizeFeatureID(dependencyID)]
+			if !ok {
+				return nil, fmt.Errorf("feature %s depends on %s, but it wasn't resolved", feature.ConfigID, dependencyID)
+			}
+
+			// add an edge from feature to dependencyFeature
+			_, err = g.InsertNodeAt(feature.ConfigID, dependencyFeature.ConfigID, dependencyFeature)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// add soft dependency edges
 		for _, installAfter := range feature.Config.InstallsAfter {
 			installAfterFeature, ok := lookup[installAfter]
 			if !ok {
@@ -0,0 +1,69 @@
+package feature
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/loft-sh/devpod/pkg/devcontainer/config"
+	"gotest.tools/assert"
+)
+
+func TestComputeFeatureOrderDependsOn(t *testing.T) {
+	baseTools := &config.FeatureSet{
+		ConfigID: "ghcr.io/my-org/features/base-tools",
+		Config:   &config.FeatureConfig{},
+	}
+	node := &config.FeatureSet{
+		ConfigID: "ghcr.io/my-org/features/node",
+		Config: &config.FeatureConfig{
+			DependsOn: map[string]interface{}{
+				"ghcr.io/my-org/features/base-tools:1": map[string]interface{}{},
+			},
+		},
+	}
+	yarn := &config.FeatureSet{
+		ConfigID: "ghcr.io/my-org/features/yarn",
+		Config: &config.FeatureConfig{
+			DependsOn: map[string]interface{}{
+				"ghcr.io/my-org/features/node:1": map[string]interface{}{},
+			},
+		},
+	}
+
+	ordered, err := computeFeatureOrder(&config.DevContainerConfig{}, []*config.FeatureSet{yarn, node, baseTools})
+	assert.NilError(t, err)
+	assert.Equal(t, len(ordered), 3)
+	assert.Equal(t, ordered[0].ConfigID, baseTools.ConfigID)
+	assert.Equal(t, ordered[1].ConfigID, node.ConfigID)
+	assert.Equal(t, ordered[2].ConfigID, yarn.ConfigID)
+
+	// override order must not break hard dependencies
+	_, err = computeFeatureOrder(&config.DevContainerConfig{
+		DevContainerConfigBase: config.DevContainerConfigBase{
+			OverrideFeatureInstallOrder: []string{"ghcr.io/my-org/features/yarn"},
+		},
+	}, []*config.FeatureSet{yarn, node, baseTools})
+	assert.ErrorContains(t, err, "before its dependency")
+
+	// cycles are reported
+	baseTools.Config.DependsOn = map[string]interface{}{
+		"ghcr.io/my-org/features/yarn:1": map[string]interface{}{},
+	}
+	_, err = computeFeatureOrder(&config.DevContainerConfig{}, []*config.FeatureSet{yarn, node, baseTools})
+	assert.Assert(t, err != nil && strings.Contains(err.Error(), "cyclic feature dependency found"), err)
+}
+
+func TestMergeFeatureOptions(t *testing.T) {
+	featureConfig := &config.FeatureConfig{
+		Options: map[string]config.FeatureConfigOption{
+			"version": {},
+			"tools":   {},
+		},
+	}
+
+	merged := mergeFeatureOptions(featureConfig, map[string]interface{}{"version": "1", "tools": "git"}, "2")
+	assert.DeepEqual(t, merged, map[string]interface{}{"version": "2", "tools": "git"})
+
+	merged = mergeFeatureOptions(featureConfig, "1", map[string]interface{}{"tools": "curl"})
+	assert.DeepEqual(t, merged, map[string]interface{}{"version": "1", "tools": "curl"})
+}
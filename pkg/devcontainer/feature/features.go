@@ -1,6 +1,7 @@
 package feature
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -91,55 +92,82 @@ func escapeQuotesForShell(str string) string {
 	return strings.ReplaceAll(str, "'", `'\''`)
 }
 
-func ProcessFeatureID(id string, devContainerConfig *config.DevContainerConfig, log log.Logger, forceBuild bool) (string, error) {
+// ProcessFeatureID downloads the feature if necessary and returns its folder and what it was resolved to. Tarball features
+// are downloaded again with forceBuild, OCI features are only resolved again with upgradeLockfile, as their tags are
+// pinned by the lockfile.
+func ProcessFeatureID(id string, devContainerConfig *config.DevContainerConfig, lockedFeature *config.LockedFeature, log log.Logger, forceBuild, upgradeLockfile bool) (string, *config.LockedFeature, error) {
 	if strings.HasPrefix(id, "https://") || strings.HasPrefix(id, "http://") {
 		log.Debugf("Process url feature")
-		return processDirectTarFeature(id, config.GetDevPodCustomizations(devContainerConfig).FeatureDownloadHTTPHeaders, log, forceBuild)
+		return processDirectTarFeature(id, config.GetDevPodCustomizations(devContainerConfig).FeatureDownloadHTTPHeaders, lockedFeature, log, forceBuild || upgradeLockfile)
 	} else if strings.HasPrefix(id, "./") || strings.HasPrefix(id, "../") {
 		log.Debugf("Process local feature")
-		return filepath.Abs(path.Join(filepath.ToSlash(filepath.Dir(devContainerConfig.Origin)), id))
+		featureFolder, err := filepath.Abs(path.Join(filepath.ToSlash(filepath.Dir(devContainerConfig.Origin)), id))
+		return featureFolder, nil, err
 	}
 
 	// get oci feature
 	log.Debugf("Process OCI feature")
-	return processOCIFeature(id, log)
+	return processOCIFeature(id, lockedFeature, log, upgradeLockfile)
 }
 
-func processOCIFeature(id string, log log.Logger) (string, error) {
+func processOCIFeature(id string, lockedFeature *config.LockedFeature, log log.Logger, forceDownload bool) (string, *config.LockedFeature, error) {
+	// use the pinned digest if the feature is locked
+	reference := id
+	if lockedFeature != nil && lockedFeature.Resolved != "" {
+		log.Debugf("Use locked feature %s for %s", lockedFeature.Resolved, id)
+		reference = lockedFeature.Resolved
+	}
+
 	// feature already exists?
-	featureFolder := getFeaturesTempFolder(id)
+	featureFolder := getFeaturesTempFolder(reference)
 	featureExtractedFolder := filepath.Join(featureFolder, "extracted")
 	_, err := os.Stat(featureExtractedFolder)
-	if err == nil {
-		// make sure feature.json is there as well
+	if err == nil && !forceDownload {
+		// make sure feature.json and the resolved digests are there as well
 		_, err = os.Stat(filepath.Join(featureExtractedFolder, config.DEVCONTAINER_FEATURE_FILE_NAME))
-		if err == nil {
-			return featureExtractedFolder, nil
-		} else {
-			log.Debugf("Feature folder already exists but seems to be empty")
-			_ = os.RemoveAll(featureFolder)
+		resolvedFeature, resolvedErr := readResolvedFeature(featureFolder)
+		if err == nil && resolvedErr == nil {
+			return featureExtractedFolder, resolvedFeature, verifyFeatureIntegrity(id, lockedFeature, resolvedFeature)
 		}
+
+		log.Debugf("Feature folder already exists but seems to be incomplete")
 	}
+	_ = os.RemoveAll(featureFolder)
 
-	ref, err := name.ParseReference(id)
+	ref, err := name.ParseReference(reference)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	img, err := remote.Image(ref, remote.WithAuthFromKeychain(authn.DefaultKeychain))
 	if err != nil {
-		return "", err
+		return "", nil, err
+	}
+
+	digest, err := img.Digest()
+	if err != nil {
+		return "", nil, errors.Wrap(err, "get image digest")
 	}
 
 	destFile := filepath.Join(featureFolder, "feature.tgz")
-	err = downloadLayer(img, id, destFile, log)
+	layerDigest, err := downloadLayer(img, id, destFile, log)
 	if err != nil {
-		return "", err
+		return "", nil, err
+	}
+
+	resolvedFeature := &config.LockedFeature{
+		Resolved:  ref.Context().Digest(digest.String()).String(),
+		Integrity: layerDigest,
+	}
+	err = verifyFeatureIntegrity(id, lockedFeature, resolvedFeature)
+	if err != nil {
+		_ = os.RemoveAll(featureFolder)
+		return "", nil, err
 	}
 
 	file, err := os.Open(destFile)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 	defer file.Close()
 
@@ -147,90 +175,159 @@ func processOCIFeature(id string, log log.Logger) (string, error) {
 	err = extract.Extract(file, featureExtractedFolder)
 	if err != nil {
 		_ = os.RemoveAll(featureExtractedFolder)
-		return "", err
+		return "", nil, err
 	}
 
-	return featureExtractedFolder, nil
+	err = writeResolvedFeature(featureFolder, resolvedFeature)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return featureExtractedFolder, resolvedFeature, nil
 }
 
-func downloadLayer(img v1.Image, id, destFile string, log log.Logger) error {
+func downloadLayer(img v1.Image, id, destFile string, log log.Logger) (string, error) {
 	manifest, err := img.Manifest()
 	if err != nil {
-		return err
+		return "", err
 	} else if manifest.Config.MediaType != DEVCONTAINER_MANIFEST_MEDIATYPE {
-		return fmt.Errorf("incorrect manifest type %s, expected %s", manifest.Config.MediaType, DEVCONTAINER_MANIFEST_MEDIATYPE)
+		return "", fmt.Errorf("incorrect manifest type %s, expected %s", manifest.Config.MediaType, DEVCONTAINER_MANIFEST_MEDIATYPE)
 	} else if len(manifest.Layers) == 0 {
-		return fmt.Errorf("unexpected amount of layers, expected at least 1")
+		return "", fmt.Errorf("unexpected amount of layers, expected at least 1")
 	}
 
 	// download layer
 	log.Debugf("Download feature %s layer %s into %s...", id, manifest.Layers[0].Digest, destFile)
 	layer, err := img.LayerByDigest(manifest.Layers[0].Digest)
 	if err != nil {
-		return errors.Wrap(err, "retrieve layer")
+		return "", errors.Wrap(err, "retrieve layer")
 	}
 
 	data, err := layer.Uncompressed()
 	if err != nil {
-		return errors.Wrap(err, "download")
+		return "", errors.Wrap(err, "download")
 	}
 	defer data.Close()
 
 	err = os.MkdirAll(filepath.Dir(destFile), 0755)
 	if err != nil {
-		return errors.Wrap(err, "create target folder")
+		return "", errors.Wrap(err, "create target folder")
 	}
 
 	file, err := os.Create(destFile)
 	if err != nil {
-		return errors.Wrap(err, "create file")
+		return "", errors.Wrap(err, "create file")
 	}
 	defer file.Close()
 
 	_, err = io.Copy(file, data)
 	if err != nil {
-		return errors.Wrap(err, "download layer")
+		return "", errors.Wrap(err, "download layer")
 	}
 
-	return nil
+	return manifest.Layers[0].Digest.String(), nil
 }
 
-func processDirectTarFeature(id string, httpHeaders map[string]string, log log.Logger, forceDownload bool) (string, error) {
+func processDirectTarFeature(id string, httpHeaders map[string]string, lockedFeature *config.LockedFeature, log log.Logger, forceDownload bool) (string, *config.LockedFeature, error) {
 	downloadBase := id[strings.LastIndex(id, "/"):]
 	if !directTarballRegEx.MatchString(downloadBase) {
-		return "", fmt.Errorf("expected tarball name to follow 'devcontainer-feature-<feature-id>.tgz' format.  Received '%s' ", downloadBase)
+		return "", nil, fmt.Errorf("expected tarball name to follow 'devcontainer-feature-<feature-id>.tgz' format.  Received '%s' ", downloadBase)
 	}
 
 	// feature already exists?
 	featureFolder := getFeaturesTempFolder(id)
 	featureExtractedFolder := filepath.Join(featureFolder, "extracted")
+	downloadFile := filepath.Join(featureFolder, "feature.tgz")
 	_, err := os.Stat(featureExtractedFolder)
 	if err == nil && !forceDownload {
-		return featureExtractedFolder, nil
+		// only reuse the cached feature if it still matches the lockfile
+		resolvedFeature, err := getDirectTarFeatureResolved(id, downloadFile)
+		if err == nil && verifyFeatureIntegrity(id, lockedFeature, resolvedFeature) == nil {
+			return featureExtractedFolder, resolvedFeature, nil
+		}
+
+		log.Debugf("Cached feature %s doesn't match lockfile, download again", id)
 	}
 
 	// download feature tarball
-	downloadFile := filepath.Join(featureFolder, "feature.tgz")
 	err = downloadFeatureFromURL(id, downloadFile, httpHeaders, log)
 	if err != nil {
-		return "", err
+		return "", nil, err
+	}
+
+	// verify integrity
+	resolvedFeature, err := getDirectTarFeatureResolved(id, downloadFile)
+	if err != nil {
+		return "", nil, err
+	}
+	err = verifyFeatureIntegrity(id, lockedFeature, resolvedFeature)
+	if err != nil {
+		_ = os.RemoveAll(featureFolder)
+		return "", nil, err
 	}
 
 	// extract file
 	file, err := os.Open(downloadFile)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 	defer file.Close()
 
 	// extract tar.gz
+	_ = os.RemoveAll(featureExtractedFolder)
 	err = extract.Extract(file, featureExtractedFolder)
 	if err != nil {
 		_ = os.RemoveAll(featureExtractedFolder)
-		return "", errors.Wrap(err, "extract folder")
+		return "", nil, errors.Wrap(err, "extract folder")
+	}
+
+	return featureExtractedFolder, resolvedFeature, nil
+}
+
+func getDirectTarFeatureResolved(id, downloadFile string) (*config.LockedFeature, error) {
+	fileHash, err := hash.File(downloadFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "hash feature tarball")
+	}
+
+	return &config.LockedFeature{
+		Resolved:  id,
+		Integrity: "sha256:" + fileHash,
+	}, nil
+}
+
+func verifyFeatureIntegrity(id string, lockedFeature *config.LockedFeature, resolvedFeature *config.LockedFeature) error {
+	if lockedFeature == nil || lockedFeature.Integrity == "" {
+		return nil
+	} else if lockedFeature.Integrity != resolvedFeature.Integrity {
+		return fmt.Errorf("integrity check of feature %s failed: expected %s, got %s. Run 'devpod build --upgrade-lockfile' if the feature was changed intentionally", id, lockedFeature.Integrity, resolvedFeature.Integrity)
+	}
+
+	return nil
+}
+
+func readResolvedFeature(featureFolder string) (*config.LockedFeature, error) {
+	data, err := os.ReadFile(filepath.Join(featureFolder, "resolved.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	resolvedFeature := &config.LockedFeature{}
+	err = json.Unmarshal(data, resolvedFeature)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolvedFeature, nil
+}
+
+func writeResolvedFeature(featureFolder string, resolvedFeature *config.LockedFeature) error {
+	out, err := json.Marshal(resolvedFeature)
+	if err != nil {
+		return err
 	}
 
-	return featureExtractedFolder, nil
+	return os.WriteFile(filepath.Join(featureFolder, "resolved.json"), out, 0644)
 }
 
 func downloadFeatureFromURL(url string, destFile string, httpHeaders map[string]string, log log.Logger) error {
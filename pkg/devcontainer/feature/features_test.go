@@ -0,0 +1,86 @@
+package feature
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/loft-sh/devpod/pkg/devcontainer/config"
+	"github.com/loft-sh/log"
+	"gotest.tools/assert"
+)
+
+func TestProcessDirectTarFeatureLock(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	tarball := featureTarball(t, "1.0.0")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(tarball)
+	}))
+	defer server.Close()
+	id := server.URL + "/devcontainer-feature-test.tgz"
+
+	// without a lockfile the feature is resolved to its integrity
+	folder, resolved, err := processDirectTarFeature(id, nil, nil, log.Discard, false)
+	assert.NilError(t, err)
+	assert.Equal(t, resolved.Resolved, id)
+	assert.Assert(t, len(resolved.Integrity) > len("sha256:"))
+	_, err = os.Stat(filepath.Join(folder, config.DEVCONTAINER_FEATURE_FILE_NAME))
+	assert.NilError(t, err)
+
+	// a matching lock reuses the download
+	_, _, err = processDirectTarFeature(id, nil, resolved, log.Discard, false)
+	assert.NilError(t, err)
+
+	// the tarball changed upstream
+	tarball = featureTarball(t, "1.0.1")
+	_, _, err = processDirectTarFeature(id, nil, resolved, log.Discard, true)
+	assert.ErrorContains(t, err, "integrity check of feature")
+
+	// upgrading resolves the new tarball
+	_, upgraded, err := processDirectTarFeature(id, nil, nil, log.Discard, true)
+	assert.NilError(t, err)
+	assert.Assert(t, upgraded.Integrity != resolved.Integrity)
+}
+
+func TestProcessOCIFeatureLock(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	// pretend the locked digest was downloaded before, so no registry is needed
+	locked := &config.LockedFeature{
+		Resolved:  "ghcr.io/devcontainers/features/go@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+		Integrity: "sha256:1111",
+	}
+	featureFolder := getFeaturesTempFolder(locked.Resolved)
+	assert.NilError(t, os.MkdirAll(filepath.Join(featureFolder, "extracted"), 0755))
+	assert.NilError(t, os.WriteFile(filepath.Join(featureFolder, "extracted", config.DEVCONTAINER_FEATURE_FILE_NAME), []byte(`{"id": "go"}`), 0644))
+	assert.NilError(t, writeResolvedFeature(featureFolder, locked))
+
+	// the tag is resolved through the lock and not downloaded again with force build
+	folder, resolved, err := ProcessFeatureID("ghcr.io/devcontainers/features/go:1", &config.DevContainerConfig{}, locked, log.Discard, true, false)
+	assert.NilError(t, err)
+	assert.Equal(t, folder, filepath.Join(featureFolder, "extracted"))
+	assert.DeepEqual(t, resolved, locked)
+
+	// a lock with another integrity fails
+	_, _, err = processOCIFeature("ghcr.io/devcontainers/features/go:1", &config.LockedFeature{Resolved: locked.Resolved, Integrity: "sha256:2222"}, log.Discard, false)
+	assert.ErrorContains(t, err, "integrity check of feature")
+}
+
+func featureTarball(t *testing.T, version string) []byte {
+	content := []byte(`{"id": "test", "version": "` + version + `"}`)
+	buf := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	assert.NilError(t, tarWriter.WriteHeader(&tar.Header{Name: config.DEVCONTAINER_FEATURE_FILE_NAME, Mode: 0644, Size: int64(len(content))}))
+	_, err := tarWriter.Write(content)
+	assert.NilError(t, err)
+	assert.NilError(t, tarWriter.Close())
+	assert.NilError(t, gzipWriter.Close())
+	return buf.Bytes()
+}
@@ -20,28 +20,37 @@ func getFeatureEnvVariables(feature *config.FeatureConfig, featureOptions interf
 }
 
 func getFeatureValueObject(feature *config.FeatureConfig, featureOptions interface{}) map[string]interface{} {
-	defaults := getFeatureDefaults(feature)
+	return applyFeatureOptions(feature, getFeatureDefaults(feature), featureOptions)
+}
+
+// mergeFeatureOptions merges the options a feature was requested with as dependency into the options
+// the feature was already requested with. Already requested options take precedence.
+func mergeFeatureOptions(feature *config.FeatureConfig, dependencyOptions interface{}, featureOptions interface{}) map[string]interface{} {
+	return applyFeatureOptions(feature, applyFeatureOptions(feature, map[string]interface{}{}, dependencyOptions), featureOptions)
+}
+
+func applyFeatureOptions(feature *config.FeatureConfig, values map[string]interface{}, featureOptions interface{}) map[string]interface{} {
 	switch t := featureOptions.(type) {
 	case map[string]interface{}:
 		for k, v := range t {
-			defaults[k] = v
+			values[k] = v
 		}
 
-		return defaults
+		return values
 	case string:
 		if feature.Options == nil {
-			return defaults
+			return values
 		}
 
 		_, ok := feature.Options["version"]
 		if ok {
-			defaults["version"] = t
+			values["version"] = t
 		}
 
-		return defaults
+		return values
 	}
 
-	return defaults
+	return values
 }
 
 func getFeatureDefaults(feature *config.FeatureConfig) map[string]interface{} {
@@ -0,0 +1,104 @@
+package devcontainer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/docker/go-units"
+	"github.com/loft-sh/devpod/pkg/devcontainer/config"
+	"github.com/loft-sh/devpod/pkg/driver"
+)
+
+const (
+	HostRequirementsWarn = "warn"
+	HostRequirementsFail = "fail"
+
+	// diskSizeOption is the provider option most machine providers use for the disk size in GB
+	diskSizeOption = "DISK_SIZE"
+)
+
+// checkHostRequirements validates the cpus, memory and storage host requirements against the resources
+// reported by the driver and either warns or fails depending on the HOST_REQUIREMENTS context option
+func (r *runner) checkHostRequirements(ctx context.Context, hostRequirements *config.HostRequirements) error {
+	if hostRequirements.IsEmpty() {
+		return nil
+	}
+
+	resources := &driver.HostResources{}
+	if resourcesDriver, ok := r.Driver.(driver.HostResourcesDriver); ok {
+		driverResources, err := resourcesDriver.HostResources(ctx, r.ID)
+		if err != nil {
+			r.Log.Debugf("Error retrieving host resources: %v", err)
+		} else {
+			resources = driverResources
+		}
+	}
+	if resources.Storage == 0 {
+		resources.Storage = r.getMachineDiskSize()
+	}
+
+	problems, err := getUnmetHostRequirements(hostRequirements, resources)
+	if err != nil {
+		return err
+	} else if len(problems) == 0 {
+		return nil
+	}
+
+	message := "host requirements not met: " + strings.Join(problems, ", ")
+	if r.WorkspaceConfig != nil && r.WorkspaceConfig.HostRequirements == HostRequirementsFail {
+		return fmt.Errorf("%s. You can only warn about this via 'devpod context set-options -o HOST_REQUIREMENTS=warn'", message)
+	}
+
+	r.Log.Warnf("The %s", message)
+	return nil
+}
+
+func getUnmetHostRequirements(hostRequirements *config.HostRequirements, resources *driver.HostResources) ([]string, error) {
+	problems := []string{}
+	if hostRequirements.CPUs > 0 && resources.CPUs > 0 && float64(hostRequirements.CPUs) > resources.CPUs {
+		problems = append(problems, fmt.Sprintf("requires %d cpus, but only %s are available", hostRequirements.CPUs, strconv.FormatFloat(resources.CPUs, 'f', -1, 64)))
+	}
+
+	memory, err := hostRequirements.GetMemory()
+	if err != nil {
+		return nil, fmt.Errorf("parse memory host requirement: %w", err)
+	} else if memory > 0 && resources.Memory > 0 && memory > resources.Memory {
+		problems = append(problems, fmt.Sprintf("requires %s memory, but only %s are available", units.BytesSize(float64(memory)), units.BytesSize(float64(resources.Memory))))
+	}
+
+	storage, err := hostRequirements.GetStorage()
+	if err != nil {
+		return nil, fmt.Errorf("parse storage host requirement: %w", err)
+	} else if storage > 0 && resources.Storage > 0 && storage > resources.Storage {
+		problems = append(problems, fmt.Sprintf("requires %s storage, but only %s are available", units.BytesSize(float64(storage)), units.BytesSize(float64(resources.Storage))))
+	}
+
+	return problems, nil
+}
+
+// getMachineDiskSize returns the disk size of the machine from the provider options if there is one
+func (r *runner) getMachineDiskSize() int64 {
+	if r.WorkspaceConfig == nil || r.WorkspaceConfig.Machine == nil {
+		return 0
+	}
+
+	diskSize, ok := r.WorkspaceConfig.Options[diskSizeOption]
+	if !ok || diskSize.Value == "" {
+		return 0
+	}
+
+	gigabytes, err := strconv.ParseInt(diskSize.Value, 10, 64)
+	if err == nil {
+		return gigabytes * units.GiB
+	}
+
+	size, err := units.RAMInBytes(diskSize.Value)
+	if err != nil {
+		r.Log.Debugf("Error parsing %s provider option: %v", diskSizeOption, err)
+		return 0
+	}
+
+	return size
+}
@@ -0,0 +1,57 @@
+package devcontainer
+
+import (
+	"testing"
+
+	"github.com/docker/go-units"
+	"github.com/loft-sh/devpod/pkg/devcontainer/config"
+	"github.com/loft-sh/devpod/pkg/driver"
+	"gotest.tools/assert"
+)
+
+func TestGetUnmetHostRequirements(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		hostRequirements *config.HostRequirements
+		resources        *driver.HostResources
+
+		expectedProblems int
+		expectedErr      bool
+	}{
+		{
+			name:             "met",
+			hostRequirements: &config.HostRequirements{CPUs: 2, Memory: "4gb", Storage: "32gb"},
+			resources:        &driver.HostResources{CPUs: 4, Memory: 8 * units.GiB, Storage: 64 * units.GiB},
+		},
+		{
+			name:             "unmet",
+			hostRequirements: &config.HostRequirements{CPUs: 8, Memory: "16gb", Storage: "128gb"},
+			resources:        &driver.HostResources{CPUs: 4, Memory: 8 * units.GiB, Storage: 64 * units.GiB},
+			expectedProblems: 3,
+		},
+		{
+			name:             "unknown resources",
+			hostRequirements: &config.HostRequirements{CPUs: 8, Memory: "16gb", Storage: "128gb"},
+			resources:        &driver.HostResources{Memory: 8 * units.GiB},
+			expectedProblems: 1,
+		},
+		{
+			name:             "invalid memory",
+			hostRequirements: &config.HostRequirements{Memory: "lots"},
+			resources:        &driver.HostResources{Memory: 8 * units.GiB},
+			expectedErr:      true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		problems, err := getUnmetHostRequirements(testCase.hostRequirements, testCase.resources)
+		if testCase.expectedErr {
+			assert.Assert(t, err != nil, testCase.name)
+			continue
+		}
+
+		assert.NilError(t, err, testCase.name)
+		assert.Equal(t, len(problems), testCase.expectedProblems, testCase.name)
+	}
+}
@@ -41,7 +41,10 @@ type Runner interface {
 
 	Delete(ctx context.Context) error
 
-	Logs(ctx context.Context, writer io.Writer) error
+	Logs(ctx context.Context, options *driver.LogsOptions, writer io.Writer) error
+
+	// Close releases the resources of the driver, e.g. stops a driver plugin
+	Close() error
 }
 
 func NewRunner(
@@ -87,6 +90,9 @@ type UpOptions struct {
 	NoBuild       bool
 	ForceBuild    bool
 	RegistryCache string
+
+	// sidecars run next to the devcontainer if a docker compose project runs without docker compose
+	sidecars []*driver.SidecarOptions
 }
 
 func (r *runner) Up(ctx context.Context, options UpOptions, timeout time.Duration) (*config.Result, error) {
@@ -119,6 +125,10 @@ func (r *runner) Up(ctx context.Context, options UpOptions, timeout time.Duratio
 			timeout,
 		)
 	case isDockerComposeConfig(substitutedConfig.Config):
+		if sidecarDriver, ok := r.Driver.(driver.SidecarDriver); ok && sidecarDriver.SupportsSidecars() {
+			return r.runComposeWithSidecars(ctx, substitutedConfig, substitutionContext, options, timeout)
+		}
+
 		return r.runDockerCompose(ctx, substitutedConfig, substitutionContext, options, timeout)
 	default:
 		return r.runDefaultContainer(ctx, options, substitutedConfig, substitutionContext, timeout)
@@ -169,8 +179,12 @@ func (r *runner) Find(ctx context.Context) (*config.ContainerDetails, error) {
 	return containerDetails, nil
 }
 
-func (r *runner) Logs(ctx context.Context, writer io.Writer) error {
-	return r.Driver.GetDevContainerLogs(ctx, r.ID, writer, writer)
+func (r *runner) Logs(ctx context.Context, options *driver.LogsOptions, writer io.Writer) error {
+	return r.Driver.GetDevContainerLogs(ctx, r.ID, options, writer, writer)
+}
+
+func (r *runner) Close() error {
+	return driver.Close(r.Driver)
 }
 
 func isDockerFileConfig(config *config.DevContainerConfig) bool {
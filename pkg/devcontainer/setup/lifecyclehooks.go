@@ -3,13 +3,16 @@ package setup
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"os/user"
+	"path/filepath"
 	"regexp"
 	"slices"
+	"sort"
 	"strings"
 	"sync"
 
@@ -17,67 +20,182 @@ import (
 	"github.com/loft-sh/devpod/pkg/devcontainer/config"
 	"github.com/loft-sh/devpod/pkg/types"
 	"github.com/loft-sh/log"
+	"github.com/loft-sh/log/hash"
 	"github.com/sirupsen/logrus"
 )
 
-func RunLifecycleHooks(ctx context.Context, setupInfo *config.Result, log log.Logger) error {
-	mergedConfig := setupInfo.MergedConfig
-	remoteUser := config.GetRemoteUser(setupInfo)
-	probedEnv, err := config.ProbeUserEnv(ctx, mergedConfig.UserEnvProbe, remoteUser, log)
+const (
+	// LifecycleHooksLogFile captures the output of the lifecycle hooks that run in the background
+	LifecycleHooksLogFile = "/var/devpod/lifecycle-hooks.log"
+
+	// LifecycleHooksErrorFile holds the error of the lifecycle hooks that ran in the background, until they run again
+	LifecycleHooksErrorFile = "/var/devpod/" + lifecycleHooksErrorName
+
+	lifecycleHooksErrorName = "lifecycle-hooks.error"
+
+	// DefaultWaitFor is the lifecycle hook to wait for if waitFor is not specified
+	DefaultWaitFor = "updateContentCommand"
+)
+
+type lifecycleHook struct {
+	// name is the name of the hook in the devcontainer.json, e.g. postCreateCommand
+	name string
+
+	commands []types.LifecycleHook
+
+	// markerName and markerContent make sure the hook only runs once per content
+	markerName    string
+	markerContent string
+}
+
+// RunLifecycleHooks runs the lifecycle hooks up to and including the one specified by waitFor. It returns true
+// if there are remaining hooks that should be run in the background via RunBackgroundLifecycleHooks.
+func RunLifecycleHooks(ctx context.Context, setupInfo *config.Result, log log.Logger) (bool, error) {
+	hooks := getLifecycleHooks(setupInfo)
+	waitFor := getWaitForIndex(hooks, setupInfo.MergedConfig.WaitFor, log)
+	err := runLifecycleHooks(ctx, setupInfo, hooks[:waitFor+1], log)
 	if err != nil {
-		log.Errorf("failed to probe environment, this might lead to an incomplete setup of your workspace: %w", err)
+		return false, err
 	}
-	remoteEnv := mergeRemoteEnv(mergedConfig.RemoteEnv, probedEnv, remoteUser)
 
-	workspaceFolder := setupInfo.SubstitutionContext.ContainerWorkspaceFolder
-	containerDetails := setupInfo.ContainerDetails
+	for _, hook := range hooks[waitFor+1:] {
+		if len(hook.commands) > 0 {
+			return true, nil
+		}
+	}
 
-	// only run once per container run
-	err = run(mergedConfig.OnCreateCommands, remoteUser, workspaceFolder, remoteEnv,
-		"onCreateCommands", containerDetails.Created, log)
+	// an error of earlier background hooks is outdated if there are none anymore
+	_ = os.Remove(filepath.Join(markerFolder, lifecycleHooksErrorName))
+	return false, nil
+}
+
+// RunBackgroundLifecycleHooks runs the lifecycle hooks after the one specified by waitFor. If they fail, the error
+// is kept until they run again, see GetBackgroundLifecycleHooksError.
+func RunBackgroundLifecycleHooks(ctx context.Context, setupInfo *config.Result, log log.Logger) error {
+	errorFile := filepath.Join(markerFolder, lifecycleHooksErrorName)
+	_ = os.Remove(errorFile)
+
+	hooks := getLifecycleHooks(setupInfo)
+	waitFor := getWaitForIndex(hooks, setupInfo.MergedConfig.WaitFor, log)
+	err := runLifecycleHooks(ctx, setupInfo, hooks[waitFor+1:], log)
 	if err != nil {
-		return err
+		_ = os.MkdirAll(markerFolder, 0777)
+		writeErr := os.WriteFile(errorFile, []byte(err.Error()), 0644)
+		if writeErr != nil {
+			log.Debugf("Error writing lifecycle hooks error: %v", writeErr)
+		}
 	}
 
-	// TODO: rerun when contents changed
-	err = run(mergedConfig.UpdateContentCommands, remoteUser, workspaceFolder, remoteEnv,
-		"updateContentCommands", containerDetails.Created, log)
+	return err
+}
+
+// GetBackgroundLifecycleHooksError returns the error of the last run of the lifecycle hooks in the background, or
+// an empty string if they succeeded
+func GetBackgroundLifecycleHooksError() string {
+	out, err := os.ReadFile(filepath.Join(markerFolder, lifecycleHooksErrorName))
 	if err != nil {
-		return err
+		return ""
 	}
 
-	// only run once per container run
-	err = run(mergedConfig.PostCreateCommands, remoteUser, workspaceFolder, remoteEnv,
-		"postCreateCommands", containerDetails.Created, log)
-	if err != nil {
-		return err
+	return strings.TrimSpace(string(out))
+}
+
+func getLifecycleHooks(setupInfo *config.Result) []lifecycleHook {
+	mergedConfig := setupInfo.MergedConfig
+	containerDetails := setupInfo.ContainerDetails
+	return []lifecycleHook{
+		// only run once per container run
+		{name: "onCreateCommand", commands: mergedConfig.OnCreateCommands, markerName: "onCreateCommands", markerContent: containerDetails.Created},
+		// run once per container run and whenever the workspace contents changed
+		{name: "updateContentCommand", commands: mergedConfig.UpdateContentCommands, markerName: "updateContentCommands", markerContent: containerDetails.Created + getContentFingerprint(setupInfo)},
+		// only run once per container run
+		{name: "postCreateCommand", commands: mergedConfig.PostCreateCommands, markerName: "postCreateCommands", markerContent: containerDetails.Created},
+		// run when the container was restarted
+		{name: "postStartCommand", commands: mergedConfig.PostStartCommands, markerName: "postStartCommands", markerContent: containerDetails.State.StartedAt},
+		// run always when attaching to the container
+		{name: "postAttachCommand", commands: mergedConfig.PostAttachCommands, markerName: "postAttachCommands"},
 	}
+}
 
-	// run when the container was restarted
-	err = run(mergedConfig.PostStartCommands, remoteUser, workspaceFolder, remoteEnv,
-		"postStartCommands", containerDetails.State.StartedAt, log)
-	if err != nil {
-		return err
+// getContentFingerprint returns a fingerprint of the git HEAD of the workspace folder and the configured
+// update content files. An empty string is returned if there is nothing to fingerprint.
+func getContentFingerprint(setupInfo *config.Result) string {
+	workspaceFolder := setupInfo.SubstitutionContext.ContainerWorkspaceFolder
+	if workspaceFolder == "" {
+		return ""
+	}
+
+	fingerprint := []string{}
+	if command.Exists("git") {
+		// the workspace folder is usually owned by the remote user
+		out, err := exec.Command("git", "-c", "safe.directory=*", "-C", workspaceFolder, "rev-parse", "HEAD").Output()
+		if err == nil {
+			fingerprint = append(fingerprint, "HEAD="+strings.TrimSpace(string(out)))
+		}
+	}
+
+	for _, file := range config.GetMergedDevPodCustomizations(setupInfo.MergedConfig).UpdateContentFiles {
+		fileHash, err := hash.File(filepath.Join(workspaceFolder, file))
+		if err != nil {
+			fileHash = "missing"
+		}
+
+		fingerprint = append(fingerprint, file+"="+fileHash)
+	}
+	if len(fingerprint) == 0 {
+		return ""
+	}
+
+	return "\n" + strings.Join(fingerprint, "\n")
+}
+
+func getWaitForIndex(hooks []lifecycleHook, waitFor string, log log.Logger) int {
+	if waitFor == "" {
+		waitFor = DefaultWaitFor
+	}
+
+	for i, hook := range hooks {
+		if hook.name == waitFor {
+			return i
+		}
+	}
+
+	log.Warnf("Unknown waitFor value %s, falling back to %s", waitFor, DefaultWaitFor)
+	return getWaitForIndex(hooks, DefaultWaitFor, log)
+}
+
+func runLifecycleHooks(ctx context.Context, setupInfo *config.Result, hooks []lifecycleHook, log log.Logger) error {
+	if len(hooks) == 0 {
+		return nil
 	}
 
-	// run always when attaching to the container
-	err = run(mergedConfig.PostAttachCommands, remoteUser, workspaceFolder, remoteEnv,
-		"postAttachCommands", "", log)
+	mergedConfig := setupInfo.MergedConfig
+	remoteUser := config.GetRemoteUser(setupInfo)
+	probedEnv, err := config.ProbeUserEnv(ctx, mergedConfig.UserEnvProbe, remoteUser, log)
 	if err != nil {
-		return err
+		log.Errorf("failed to probe environment, this might lead to an incomplete setup of your workspace: %w", err)
+	}
+	remoteEnv := mergeRemoteEnv(mergedConfig.RemoteEnv, probedEnv, remoteUser)
+	workspaceFolder := setupInfo.SubstitutionContext.ContainerWorkspaceFolder
+
+	for _, hook := range hooks {
+		err = run(ctx, hook.commands, remoteUser, workspaceFolder, remoteEnv, hook.markerName, hook.markerContent, log)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func run(commands []types.LifecycleHook, remoteUser, dir string, remoteEnv map[string]string, name, content string, log log.Logger) error {
+func run(ctx context.Context, commands []types.LifecycleHook, remoteUser, dir string, remoteEnv map[string]string, name, content string, log log.Logger) error {
 	if len(commands) == 0 {
 		return nil
 	}
 
 	// check marker file
 	if content != "" {
-		exists, err := markerFileExists(name, content)
+		exists, err := markerExists(name, content)
 		if err != nil {
 			return err
 		} else if exists {
@@ -95,80 +213,138 @@ func run(commands []types.LifecycleHook, remoteUser, dir string, remoteEnv map[s
 			continue
 		}
 
-		for k, c := range cmd {
-			log.Infof("Run command %s: %s...", k, strings.Join(c, " "))
-			currentUser, err := user.Current()
+		// named commands of the object syntax run in parallel
+		if len(cmd) > 1 {
+			err := runParallel(ctx, cmd, remoteUser, dir, remoteEnvArr, log)
 			if err != nil {
 				return err
 			}
-			args := []string{}
-			if remoteUser != currentUser.Username {
-				args = append(args, "su", remoteUser, "-c", command.Quote(c))
-			} else {
-				args = append(args, "sh", "-c", command.Quote(c))
-			}
 
-			// create command
-			cmd := exec.Command(args[0], args[1:]...)
-			cmd.Dir = dir
-			cmd.Env = os.Environ()
-			cmd.Env = append(cmd.Env, remoteEnvArr...)
+			continue
+		}
 
-			// Create pipes for stdout and stderr
-			stdoutPipe, err := cmd.StdoutPipe()
-			if err != nil {
-				return fmt.Errorf("failed to get stdout pipe: %w", err)
-			}
-			stderrPipe, err := cmd.StderrPipe()
+		for k, c := range cmd {
+			err := runCommand(ctx, k, c, remoteUser, dir, remoteEnvArr, "", log)
 			if err != nil {
-				return fmt.Errorf("failed to get stderr pipe: %w", err)
+				return err
 			}
+		}
+	}
 
-			// Start the command
-			if err := cmd.Start(); err != nil {
-				return fmt.Errorf("failed to start command: %w", err)
-			}
+	// the marker is only written if all commands succeeded, so failed hooks run again
+	if content != "" {
+		return writeMarker(name, content)
+	}
 
-			// Use WaitGroup to wait for both stdout and stderr processing
-			var wg sync.WaitGroup
-			wg.Add(2)
+	return nil
+}
 
-			go func() {
-				defer wg.Done()
-				logPipeOutput(log, stdoutPipe, logrus.InfoLevel)
-			}()
+func runParallel(ctx context.Context, cmd types.LifecycleHook, remoteUser, dir string, remoteEnvArr []string, log log.Logger) error {
+	names := make([]string, 0, len(cmd))
+	for k := range cmd {
+		names = append(names, k)
+	}
+	sort.Strings(names)
 
-			go func() {
-				defer wg.Done()
-				logPipeOutput(log, stderrPipe, logrus.ErrorLevel)
-			}()
+	var wg sync.WaitGroup
+	errs := make([]error, len(names))
+	for i, k := range names {
+		wg.Add(1)
+		go func(i int, k string, c []string) {
+			defer wg.Done()
 
-			// Wait for command to finish
-			wg.Wait()
-			err = cmd.Wait()
-			if err != nil {
-				log.Debugf("Failed running postCreateCommand lifecycle script %s: %v", cmd.Args, err)
-				return fmt.Errorf("failed to run: %s, error: %w", strings.Join(c, " "), err)
-			}
+			errs[i] = runCommand(ctx, k, c, remoteUser, dir, remoteEnvArr, "["+k+"] ", log)
+		}(i, k, cmd[k])
+	}
+	wg.Wait()
 
-			log.Donef("Successfully ran command %s: %s", k, strings.Join(c, " "))
+	// report all failed commands
+	failed := []string{}
+	failedErrs := []error{}
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, names[i])
+			failedErrs = append(failedErrs, fmt.Errorf("%s: %w", names[i], err))
 		}
 	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d parallel commands failed (%s): %w", len(failed), len(names), strings.Join(failed, ", "), errors.Join(failedErrs...))
+	}
+
+	return nil
+}
+
+func runCommand(ctx context.Context, name string, c []string, remoteUser, dir string, remoteEnvArr []string, prefix string, log log.Logger) error {
+	log.Infof("%sRun command %s: %s...", prefix, name, strings.Join(c, " "))
+	currentUser, err := user.Current()
+	if err != nil {
+		return err
+	}
+	args := []string{}
+	if remoteUser != currentUser.Username {
+		args = append(args, "su", remoteUser, "-c", command.Quote(c))
+	} else {
+		args = append(args, "sh", "-c", command.Quote(c))
+	}
+
+	// create command
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = dir
+	cmd.Env = os.Environ()
+	cmd.Env = append(cmd.Env, remoteEnvArr...)
+
+	// Create pipes for stdout and stderr
+	stdoutPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to get stdout pipe: %w", err)
+	}
+	stderrPipe, err := cmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("failed to get stderr pipe: %w", err)
+	}
+
+	// Start the command
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start command: %w", err)
+	}
+
+	// Use WaitGroup to wait for both stdout and stderr processing
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		logPipeOutput(log, stdoutPipe, prefix, logrus.InfoLevel)
+	}()
+
+	go func() {
+		defer wg.Done()
+		logPipeOutput(log, stderrPipe, prefix, logrus.ErrorLevel)
+	}()
+
+	// Wait for command to finish
+	wg.Wait()
+	err = cmd.Wait()
+	if err != nil {
+		log.Debugf("Failed running postCreateCommand lifecycle script %s: %v", cmd.Args, err)
+		return fmt.Errorf("failed to run: %s, error: %w", strings.Join(c, " "), err)
+	}
 
+	log.Donef("%sSuccessfully ran command %s: %s", prefix, name, strings.Join(c, " "))
 	return nil
 }
 
-func logPipeOutput(log log.Logger, pipe io.ReadCloser, level logrus.Level) {
+func logPipeOutput(log log.Logger, pipe io.ReadCloser, prefix string, level logrus.Level) {
 	scanner := bufio.NewScanner(pipe)
 	for scanner.Scan() {
 		line := scanner.Text()
 		if level == logrus.InfoLevel {
-			log.Info(line)
+			log.Info(prefix + line)
 		} else if level == logrus.ErrorLevel {
 			if containsError(line) {
-				log.Error(line)
+				log.Error(prefix + line)
 			} else {
-				log.Warn(line)
+				log.Warn(prefix + line)
 			}
 		}
 	}
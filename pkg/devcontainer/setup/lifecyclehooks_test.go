@@ -0,0 +1,148 @@
+package setup
+
+import (
+	"context"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/loft-sh/devpod/pkg/devcontainer/config"
+	"github.com/loft-sh/devpod/pkg/types"
+	"github.com/loft-sh/log"
+	"gotest.tools/assert"
+)
+
+func TestRunParallel(t *testing.T) {
+	currentUser, err := user.Current()
+	assert.NilError(t, err)
+
+	hook := types.LifecycleHook{
+		"ok":     []string{"true"},
+		"broken": []string{"sh", "-c", "exit 3"},
+		"failed": []string{"false"},
+	}
+	err = runParallel(context.Background(), hook, currentUser.Username, t.TempDir(), nil, log.Discard)
+	assert.ErrorContains(t, err, "2 of 3 parallel commands failed (broken, failed)")
+	assert.Assert(t, !strings.Contains(err.Error(), "ok:"))
+
+	err = runParallel(context.Background(), types.LifecycleHook{
+		"first":  []string{"true"},
+		"second": []string{"true"},
+	}, currentUser.Username, t.TempDir(), nil, log.Discard)
+	assert.NilError(t, err)
+}
+
+func TestGetContentFingerprint(t *testing.T) {
+	workspaceFolder := t.TempDir()
+	setupInfo := &config.Result{
+		MergedConfig: &config.MergedDevContainerConfig{
+			UpdatedConfigProperties: config.UpdatedConfigProperties{
+				Customizations: map[string][]interface{}{
+					"devpod": {map[string]interface{}{"updateContentFiles": []string{"go.sum"}}},
+				},
+			},
+		},
+		SubstitutionContext: &config.SubstitutionContext{ContainerWorkspaceFolder: workspaceFolder},
+	}
+
+	missing := getContentFingerprint(setupInfo)
+	assert.Assert(t, strings.Contains(missing, "go.sum=missing"))
+
+	err := os.WriteFile(filepath.Join(workspaceFolder, "go.sum"), []byte("v1"), 0o644)
+	assert.NilError(t, err)
+	first := getContentFingerprint(setupInfo)
+	assert.Assert(t, first != missing)
+	assert.Equal(t, first, getContentFingerprint(setupInfo))
+
+	err = os.WriteFile(filepath.Join(workspaceFolder, "go.sum"), []byte("v2"), 0o644)
+	assert.NilError(t, err)
+	assert.Assert(t, first != getContentFingerprint(setupInfo))
+}
+
+func TestGetWaitForIndex(t *testing.T) {
+	hooks := getLifecycleHooks(newTestSetupInfo(t, t.TempDir()))
+	for waitFor, expected := range map[string]int{
+		"":                     1,
+		"onCreateCommand":      0,
+		"updateContentCommand": 1,
+		"postStartCommand":     3,
+		"postAttachCommand":    4,
+		"unknown":              1,
+	} {
+		assert.Equal(t, getWaitForIndex(hooks, waitFor, log.Discard), expected, waitFor)
+	}
+}
+
+func TestRunLifecycleHooks(t *testing.T) {
+	defaultMarkerFolder := markerFolder
+	t.Cleanup(func() { markerFolder = defaultMarkerFolder })
+	markerFolder = t.TempDir()
+	workspaceFolder := t.TempDir()
+	setupInfo := newTestSetupInfo(t, workspaceFolder)
+
+	// hooks up to waitFor run in the foreground, the rest in the background
+	background, err := RunLifecycleHooks(context.Background(), setupInfo, log.Discard)
+	assert.NilError(t, err)
+	assert.Assert(t, background)
+	assertHooksLog(t, workspaceFolder, "onCreate\nupdateContent\n")
+
+	err = RunBackgroundLifecycleHooks(context.Background(), setupInfo, log.Discard)
+	assert.ErrorContains(t, err, "failed to run")
+	assert.Assert(t, strings.Contains(GetBackgroundLifecycleHooksError(), "exit status 1"))
+	assertHooksLog(t, workspaceFolder, "onCreate\nupdateContent\npostCreate\n")
+
+	// the failed hook runs again, the succeeded ones don't
+	err = os.WriteFile(filepath.Join(workspaceFolder, "ready"), nil, 0o644)
+	assert.NilError(t, err)
+	background, err = RunLifecycleHooks(context.Background(), setupInfo, log.Discard)
+	assert.NilError(t, err)
+	assert.Assert(t, background)
+	err = RunBackgroundLifecycleHooks(context.Background(), setupInfo, log.Discard)
+	assert.NilError(t, err)
+	assert.Equal(t, GetBackgroundLifecycleHooksError(), "")
+	assertHooksLog(t, workspaceFolder, "onCreate\nupdateContent\npostCreate\npostCreate\npostStart\npostAttach\n")
+
+	// postAttachCommand runs every time
+	err = RunBackgroundLifecycleHooks(context.Background(), setupInfo, log.Discard)
+	assert.NilError(t, err)
+	assertHooksLog(t, workspaceFolder, "onCreate\nupdateContent\npostCreate\npostCreate\npostStart\npostAttach\npostAttach\n")
+
+	// everything runs in the foreground
+	setupInfo.MergedConfig.WaitFor = "postAttachCommand"
+	background, err = RunLifecycleHooks(context.Background(), setupInfo, log.Discard)
+	assert.NilError(t, err)
+	assert.Assert(t, !background)
+}
+
+func newTestSetupInfo(t *testing.T, workspaceFolder string) *config.Result {
+	currentUser, err := user.Current()
+	assert.NilError(t, err)
+
+	appendHook := func(name string) []types.LifecycleHook {
+		return []types.LifecycleHook{{"": []string{"sh", "-c", "echo " + name + " >> hooks.log"}}}
+	}
+	mergedConfig := &config.MergedDevContainerConfig{}
+	mergedConfig.RemoteUser = currentUser.Username
+	mergedConfig.UserEnvProbe = string(config.NoneProbe)
+	mergedConfig.OnCreateCommands = appendHook("onCreate")
+	mergedConfig.UpdateContentCommands = appendHook("updateContent")
+	mergedConfig.PostCreateCommands = append(appendHook("postCreate"), types.LifecycleHook{"": []string{"test", "-f", "ready"}})
+	mergedConfig.PostStartCommands = appendHook("postStart")
+	mergedConfig.PostAttachCommands = appendHook("postAttach")
+	return &config.Result{
+		MergedConfig: mergedConfig,
+		ContainerDetails: &config.ContainerDetails{
+			Created: "created",
+			State:   config.ContainerDetailsState{StartedAt: "started"},
+		},
+		SubstitutionContext: &config.SubstitutionContext{ContainerWorkspaceFolder: workspaceFolder},
+	}
+}
+
+func assertHooksLog(t *testing.T, workspaceFolder string, expected string) {
+	out, err := os.ReadFile(filepath.Join(workspaceFolder, "hooks.log"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(out), expected)
+}
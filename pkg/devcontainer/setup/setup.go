@@ -29,31 +29,33 @@ const (
 	ResultLocation = "/var/run/devpod/result.json"
 )
 
-func SetupContainer(ctx context.Context, setupInfo *config.Result, extraWorkspaceEnv []string, chownProjects bool, platformOptions *devpod.PlatformOptions, tunnelClient tunnel.TunnelClient, log log.Logger) error {
+// SetupContainer sets up the container and runs the lifecycle hooks up to waitFor. It returns true if there are
+// lifecycle hooks left that should be run in the background.
+func SetupContainer(ctx context.Context, setupInfo *config.Result, extraWorkspaceEnv []string, chownProjects bool, platformOptions *devpod.PlatformOptions, tunnelClient tunnel.TunnelClient, log log.Logger) (bool, error) {
 	// write result to ResultLocation
 	WriteResult(setupInfo, log)
 
 	// chown user dir
 	err := ChownWorkspace(setupInfo, chownProjects, log)
 	if err != nil {
-		return errors.Wrap(err, "chown workspace")
+		return false, errors.Wrap(err, "chown workspace")
 	}
 
 	// patch remote env
 	log.Debugf("Patch etc environment & profile...")
 	err = PatchEtcEnvironment(setupInfo.MergedConfig, log)
 	if err != nil {
-		return errors.Wrap(err, "patch etc environment")
+		return false, errors.Wrap(err, "patch etc environment")
 	}
 	err = PatchEtcEnvironmentFlags(extraWorkspaceEnv, log)
 	if err != nil {
-		return errors.Wrap(err, "patch etc environment from flags")
+		return false, errors.Wrap(err, "patch etc environment from flags")
 	}
 
 	// patch etc profile
 	err = PatchEtcProfile()
 	if err != nil {
-		return errors.Wrap(err, "patch etc profile")
+		return false, errors.Wrap(err, "patch etc profile")
 	}
 
 	// link /home/root to root if necessary
@@ -65,7 +67,7 @@ func SetupContainer(ctx context.Context, setupInfo *config.Result, extraWorkspac
 	// chown agent sock file
 	err = ChownAgentSock(setupInfo)
 	if err != nil {
-		return errors.Wrap(err, "chown ssh agent sock file")
+		return false, errors.Wrap(err, "chown ssh agent sock file")
 	}
 
 	// setup kube config
@@ -82,13 +84,13 @@ func SetupContainer(ctx context.Context, setupInfo *config.Result, extraWorkspac
 
 	// run commands
 	log.Debugf("Run lifecycle hooks commands...")
-	err = RunLifecycleHooks(ctx, setupInfo, log)
+	backgroundHooks, err := RunLifecycleHooks(ctx, setupInfo, log)
 	if err != nil {
-		return errors.Wrap(err, "lifecycle hooks")
+		return false, errors.Wrap(err, "lifecycle hooks")
 	}
 
 	log.Debugf("Done setting up environment")
-	return nil
+	return backgroundHooks, nil
 }
 
 func WriteResult(setupInfo *config.Result, log log.Logger) {
@@ -329,23 +331,35 @@ func SetupKubeConfig(ctx context.Context, setupInfo *config.Result, tunnelClient
 	return nil
 }
 
+// markerFolder holds the markers of the setup steps that only run once
+var markerFolder = "/var/devpod"
+
 func markerFileExists(markerName string, markerContent string) (bool, error) {
-	markerName = filepath.Join("/var/devpod", markerName+".marker")
-	t, err := os.ReadFile(markerName)
+	exists, err := markerExists(markerName, markerContent)
+	if err != nil || exists {
+		return exists, err
+	}
+
+	return false, writeMarker(markerName, markerContent)
+}
+
+func markerExists(markerName string, markerContent string) (bool, error) {
+	t, err := os.ReadFile(filepath.Join(markerFolder, markerName+".marker"))
 	if err != nil && !os.IsNotExist(err) {
 		return false, err
-	} else if err == nil && (markerContent == "" || string(t) == markerContent) {
-		return true, nil
 	}
 
-	// write marker
-	_ = os.MkdirAll(filepath.Dir(markerName), 0777)
-	err = os.WriteFile(markerName, []byte(markerContent), 0644)
+	return err == nil && (markerContent == "" || string(t) == markerContent), nil
+}
+
+func writeMarker(markerName string, markerContent string) error {
+	_ = os.MkdirAll(markerFolder, 0777)
+	err := os.WriteFile(filepath.Join(markerFolder, markerName+".marker"), []byte(markerContent), 0644)
 	if err != nil {
-		return false, errors.Wrap(err, "write marker")
+		return errors.Wrap(err, "write marker")
 	}
 
-	return false, nil
+	return nil
 }
 
 func setupPlatformGitCredentials(userName string, platformOptions *devpod.PlatformOptions, log log.Logger) error {
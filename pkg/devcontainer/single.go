@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"path"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -91,6 +93,12 @@ func (r *runner) runSingleContainer(
 			}
 		}
 	} else {
+		// make sure the host is able to run the container
+		err = r.checkHostRequirements(ctx, parsedConfig.Config.HostRequirements)
+		if err != nil {
+			return nil, err
+		}
+
 		// we need to build the container
 		buildInfo, err := r.build(ctx, parsedConfig, substitutionContext, provider2.BuildOptions{
 			CLIOptions: provider2.CLIOptions{
@@ -140,7 +148,7 @@ func (r *runner) runSingleContainer(
 		}
 
 		// run dev container
-		err = r.runContainer(ctx, parsedConfig, substitutionContext, mergedConfig, buildInfo)
+		err = r.runContainer(ctx, parsedConfig, substitutionContext, mergedConfig, buildInfo, options.sidecars)
 		if err != nil {
 			return nil, errors.Wrap(err, "start dev container")
 		}
@@ -164,6 +172,7 @@ func (r *runner) runContainer(
 	substitutionContext *config.SubstitutionContext,
 	mergedConfig *config.MergedDevContainerConfig,
 	buildInfo *config.BuildInfo,
+	sidecars []*driver.SidecarOptions,
 ) error {
 	var err error
 
@@ -183,6 +192,7 @@ func (r *runner) runContainer(
 	}
 
 	runOptions.Env = r.addExtraEnvVars(runOptions.Env)
+	runOptions.Sidecars = sidecars
 
 	// check if docker
 	dockerDriver, ok := r.Driver.(driver.DockerDriver)
@@ -274,9 +284,10 @@ func (r *runner) getDockerlessRunOptions(
 			metadata.ImageMetadataLabel + "=" + string(marshalled),
 			config.UserLabel + "=" + buildInfo.Dockerless.User,
 		},
-		Privileged:     mergedConfig.Privileged,
-		WorkspaceMount: &workspaceMountParsed,
-		Mounts:         mounts,
+		Privileged:       mergedConfig.Privileged,
+		WorkspaceMount:   &workspaceMountParsed,
+		Mounts:           mounts,
+		HostRequirements: mergedConfig.HostRequirements,
 	}, nil
 }
 
@@ -311,22 +322,52 @@ func (r *runner) getRunOptions(
 		uid = r.WorkspaceConfig.Workspace.UID
 	}
 
+	mounts := mergedConfig.Mounts
+	if worktreeMount := r.getWorktreeMount(); worktreeMount != nil {
+		mounts = append(mounts, worktreeMount)
+	}
+
 	return &driver.RunOptions{
-		UID:            uid,
-		Image:          buildInfo.ImageName,
-		User:           user,
-		Entrypoint:     entrypoint,
-		Cmd:            cmd,
-		Env:            mergedConfig.ContainerEnv,
-		CapAdd:         mergedConfig.CapAdd,
-		Labels:         labels,
-		Privileged:     mergedConfig.Privileged,
-		WorkspaceMount: &workspaceMountParsed,
-		SecurityOpt:    mergedConfig.SecurityOpt,
-		Mounts:         mergedConfig.Mounts,
+		UID:              uid,
+		Image:            buildInfo.ImageName,
+		User:             user,
+		Entrypoint:       entrypoint,
+		Cmd:              cmd,
+		Env:              mergedConfig.ContainerEnv,
+		CapAdd:           mergedConfig.CapAdd,
+		Labels:           labels,
+		Privileged:       mergedConfig.Privileged,
+		WorkspaceMount:   &workspaceMountParsed,
+		SecurityOpt:      mergedConfig.SecurityOpt,
+		Mounts:           mounts,
+		HostRequirements: mergedConfig.HostRequirements,
 	}, nil
 }
 
+// getWorktreeMount returns the mount of the git folder of the repository if the workspace is one of its git worktrees
+// that is mounted directly. The worktree references the git folder by its absolute path, so it's mounted at the same path.
+func (r *runner) getWorktreeMount() *config.Mount {
+	if r.WorkspaceConfig == nil || r.WorkspaceConfig.Workspace == nil {
+		return nil
+	}
+
+	source := r.WorkspaceConfig.Workspace.Source
+	if source.GitWorktreeRepository == "" || source.LocalFolder == source.GitWorktreeRepository || source.LocalFolder != r.LocalWorkspaceFolder {
+		return nil
+	}
+
+	gitDir := filepath.ToSlash(filepath.Join(source.GitWorktreeRepository, ".git"))
+	if !path.IsAbs(gitDir) {
+		return nil
+	}
+
+	return &config.Mount{
+		Type:   "bind",
+		Source: gitDir,
+		Target: gitDir,
+	}
+}
+
 // add environment variables that signals that we are in a remote container
 // (vscode compatibility) and specifically that we are using devpod.
 func (r *runner) addExtraEnvVars(env map[string]string) map[string]string {
@@ -0,0 +1,344 @@
+package docker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	dockerclient "github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/homedir"
+)
+
+// ExecExitError is returned if a command executed through the engine api exits with a non-zero code
+type ExecExitError struct {
+	Code int
+}
+
+func (e *ExecExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// ExitCode returns the exit code of the command
+func (e *ExecExitError) ExitCode() int {
+	return e.Code
+}
+
+// apiClient returns a client for the docker engine api or nil if the docker cli should be used instead.
+// The engine api is only used for the docker cli itself, podman and nerdctl always go through the cli.
+func (r *DockerHelper) apiClient(ctx context.Context) *dockerclient.Client {
+	r.apiOnce.Do(func() {
+		cli, err := r.newAPIClient(ctx)
+		if err != nil {
+			if r.Log != nil {
+				r.Log.Debugf("Using %s cli instead of the docker engine api: %v", r.DockerCommand, err)
+			}
+			return
+		}
+
+		r.api = cli
+	})
+
+	return r.api
+}
+
+func (r *DockerHelper) newAPIClient(ctx context.Context) (*dockerclient.Client, error) {
+	dockerCommand := strings.TrimSuffix(filepath.Base(r.DockerCommand), ".exe")
+	if dockerCommand != "docker" {
+		return nil, fmt.Errorf("%s is not the docker cli", r.DockerCommand)
+	}
+
+	host, err := r.resolveDockerHost()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []dockerclient.Opt{dockerclient.WithAPIVersionNegotiation()}
+	if host != "" {
+		opts = append(opts, dockerclient.WithHost(host))
+	}
+	if version := r.getenv("DOCKER_API_VERSION"); version != "" {
+		opts = append(opts, dockerclient.WithVersion(version))
+	}
+	cli, err := dockerclient.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// make sure the daemon is reachable and not podman behind a docker alias
+	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	version, err := cli.ServerVersion(pingCtx)
+	if err != nil {
+		_ = cli.Close()
+		return nil, err
+	}
+	for _, component := range version.Components {
+		if strings.Contains(strings.ToLower(component.Name), "podman") {
+			_ = cli.Close()
+			return nil, fmt.Errorf("daemon is podman")
+		}
+	}
+
+	return cli, nil
+}
+
+// resolveDockerHost returns the daemon host the docker cli would talk to. An empty host means the
+// default socket of the platform.
+func (r *DockerHelper) resolveDockerHost() (string, error) {
+	if r.getenv("DOCKER_TLS_VERIFY") != "" || r.getenv("DOCKER_CERT_PATH") != "" {
+		return "", fmt.Errorf("tls connections are only supported through the cli")
+	}
+
+	host := r.getenv("DOCKER_HOST")
+	if host == "" {
+		var err error
+		host, err = r.contextHost()
+		if err != nil {
+			return "", err
+		} else if host == "" {
+			return "", nil
+		}
+	}
+
+	parsed, err := url.Parse(host)
+	if err != nil {
+		return "", fmt.Errorf("parse docker host %s: %w", host, err)
+	}
+	switch parsed.Scheme {
+	case "unix", "npipe", "tcp", "http":
+		return host, nil
+	default:
+		return "", fmt.Errorf("docker host scheme %s is only supported through the cli", parsed.Scheme)
+	}
+}
+
+// contextHost returns the host of the current docker context if it isn't the default one
+func (r *DockerHelper) contextHost() (string, error) {
+	configDir := r.getenv("DOCKER_CONFIG")
+	if configDir == "" {
+		configDir = filepath.Join(homedir.Get(), ".docker")
+	}
+
+	contextName := r.getenv("DOCKER_CONTEXT")
+	if contextName == "" {
+		out, err := os.ReadFile(filepath.Join(configDir, "config.json"))
+		if err != nil && !os.IsNotExist(err) {
+			return "", err
+		} else if err == nil {
+			dockerConfig := struct {
+				CurrentContext string `json:"currentContext,omitempty"`
+			}{}
+			err = json.Unmarshal(out, &dockerConfig)
+			if err != nil {
+				return "", fmt.Errorf("parse docker config: %w", err)
+			}
+
+			contextName = dockerConfig.CurrentContext
+		}
+	}
+	if contextName == "" || contextName == "default" {
+		return "", nil
+	}
+
+	hash := sha256.Sum256([]byte(contextName))
+	contextID := hex.EncodeToString(hash[:])
+	_, err := os.Stat(filepath.Join(configDir, "contexts", "tls", contextID))
+	if err == nil {
+		return "", fmt.Errorf("docker context %s uses tls", contextName)
+	}
+
+	out, err := os.ReadFile(filepath.Join(configDir, "contexts", "meta", contextID, "meta.json"))
+	if err != nil {
+		return "", fmt.Errorf("read docker context %s: %w", contextName, err)
+	}
+	meta := struct {
+		Endpoints map[string]struct {
+			Host string `json:"Host,omitempty"`
+		} `json:"Endpoints,omitempty"`
+	}{}
+	err = json.Unmarshal(out, &meta)
+	if err != nil {
+		return "", fmt.Errorf("parse docker context %s: %w", contextName, err)
+	}
+
+	return meta.Endpoints["docker"].Host, nil
+}
+
+// getenv looks up the variable in the helper environment first and falls back to the process environment
+func (r *DockerHelper) getenv(key string) string {
+	for i := len(r.Environment) - 1; i >= 0; i-- {
+		name, value, found := strings.Cut(r.Environment[i], "=")
+		if found && name == key {
+			return value
+		}
+	}
+
+	return os.Getenv(key)
+}
+
+func apiFindContainer(ctx context.Context, cli *dockerclient.Client, labels []string) ([]string, error) {
+	args := filters.NewArgs()
+	for _, label := range labels {
+		args.Add("label", label)
+	}
+
+	containers, err := cli.ContainerList(ctx, container.ListOptions{All: true, Filters: args})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []string{}
+	for _, c := range containers {
+		ids = append(ids, c.ID)
+	}
+
+	return ids, nil
+}
+
+// apiInspect returns the same json array docker inspect would print for the given ids
+func apiInspect(ctx context.Context, cli *dockerclient.Client, ids []string, inspectType string) ([]byte, error) {
+	raws := make([]json.RawMessage, 0, len(ids))
+	for _, id := range ids {
+		var raw []byte
+		var err error
+		if inspectType == "image" {
+			_, raw, err = cli.ImageInspectWithRaw(ctx, id)
+		} else {
+			_, raw, err = cli.ContainerInspectWithRaw(ctx, id, false)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		raws = append(raws, raw)
+	}
+
+	return json.Marshal(raws)
+}
+
+func apiContainerLogs(ctx context.Context, cli *dockerclient.Client, id string, options container.LogsOptions, stdout io.Writer, stderr io.Writer) error {
+	details, err := cli.ContainerInspect(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	options.ShowStdout = true
+	options.ShowStderr = true
+	reader, err := cli.ContainerLogs(ctx, id, options)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	// containers with a tty don't multiplex their output
+	if details.Config != nil && details.Config.Tty {
+		_, err = io.Copy(writerOrDiscard(stdout), reader)
+		return err
+	}
+
+	return demuxStream(stdout, stderr, reader)
+}
+
+func apiExec(ctx context.Context, cli *dockerclient.Client, id, user string, cmd []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	created, err := cli.ContainerExecCreate(ctx, id, container.ExecOptions{
+		User:         user,
+		Cmd:          cmd,
+		AttachStdin:  stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return fmt.Errorf("create exec: %w", err)
+	}
+
+	resp, err := cli.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return fmt.Errorf("attach exec: %w", err)
+	}
+	defer resp.Close()
+
+	// the hijacked connection doesn't know about the context, so close it ourselves
+	stop := context.AfterFunc(ctx, resp.Close)
+	defer stop()
+
+	if stdin != nil {
+		go func() {
+			_, _ = io.Copy(resp.Conn, stdin)
+			_ = resp.CloseWrite()
+		}()
+	}
+
+	err = demuxStream(stdout, stderr, resp.Reader)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	} else if err != nil {
+		return err
+	}
+
+	inspect, err := cli.ContainerExecInspect(ctx, created.ID)
+	if err != nil {
+		return fmt.Errorf("inspect exec: %w", err)
+	} else if inspect.ExitCode != 0 {
+		return &ExecExitError{Code: inspect.ExitCode}
+	}
+
+	return nil
+}
+
+// demuxStream splits a multiplexed docker stream into stdout and stderr. Each frame starts with
+// an 8 byte header holding the stream type in the first byte and the frame size in the last four.
+func demuxStream(stdout io.Writer, stderr io.Writer, src io.Reader) error {
+	stdout = writerOrDiscard(stdout)
+	stderr = writerOrDiscard(stderr)
+
+	header := make([]byte, 8)
+	for {
+		_, err := io.ReadFull(src, header)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("read stream header: %w", err)
+		}
+
+		var dst io.Writer
+		switch header[0] {
+		case 0, 1:
+			dst = stdout
+		case 2:
+			dst = stderr
+		case 3:
+			// the daemon reports an error through the system stream
+			message, err := io.ReadAll(io.LimitReader(src, int64(binary.BigEndian.Uint32(header[4:]))))
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("%s", strings.TrimSpace(string(message)))
+		default:
+			return fmt.Errorf("unexpected stream type %d", header[0])
+		}
+
+		_, err = io.CopyN(dst, src, int64(binary.BigEndian.Uint32(header[4:])))
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func writerOrDiscard(writer io.Writer) io.Writer {
+	if writer == nil {
+		return io.Discard
+	}
+
+	return writer
+}
@@ -0,0 +1,105 @@
+package docker
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func frame(stream byte, payload string) []byte {
+	header := make([]byte, 8)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+	return append(header, []byte(payload)...)
+}
+
+func TestDemuxStream(t *testing.T) {
+	src := &bytes.Buffer{}
+	src.Write(frame(1, "hello "))
+	src.Write(frame(2, "oops"))
+	src.Write(frame(1, "world"))
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	err := demuxStream(stdout, stderr, src)
+	assert.NilError(t, err)
+	assert.Equal(t, stdout.String(), "hello world")
+	assert.Equal(t, stderr.String(), "oops")
+
+	err = demuxStream(nil, nil, bytes.NewReader(frame(3, "exec failed\n")))
+	assert.Error(t, err, "exec failed")
+
+	err = demuxStream(nil, nil, bytes.NewReader(frame(1, "short")[:10]))
+	assert.ErrorContains(t, err, "EOF")
+}
+
+func TestResolveDockerHost(t *testing.T) {
+	configDir := t.TempDir()
+	hash := sha256.Sum256([]byte("colima"))
+	metaDir := filepath.Join(configDir, "contexts", "meta", hex.EncodeToString(hash[:]))
+	assert.NilError(t, os.MkdirAll(metaDir, 0755))
+	assert.NilError(t, os.WriteFile(filepath.Join(metaDir, "meta.json"), []byte(`{"Name":"colima","Endpoints":{"docker":{"Host":"unix:///home/user/.colima/docker.sock"}}}`), 0644))
+	assert.NilError(t, os.WriteFile(filepath.Join(configDir, "config.json"), []byte(`{"currentContext":"colima"}`), 0644))
+
+	t.Setenv("DOCKER_HOST", "")
+	t.Setenv("DOCKER_CONTEXT", "")
+	t.Setenv("DOCKER_TLS_VERIFY", "")
+	t.Setenv("DOCKER_CERT_PATH", "")
+
+	testCases := []struct {
+		Name        string
+		Environment []string
+		Host        string
+		Error       bool
+	}{
+		{
+			Name:        "docker host",
+			Environment: []string{"DOCKER_HOST=tcp://127.0.0.1:2375"},
+			Host:        "tcp://127.0.0.1:2375",
+		},
+		{
+			Name:        "ssh host",
+			Environment: []string{"DOCKER_HOST=ssh://user@remote"},
+			Error:       true,
+		},
+		{
+			Name:        "tls",
+			Environment: []string{"DOCKER_HOST=tcp://127.0.0.1:2376", "DOCKER_TLS_VERIFY=1"},
+			Error:       true,
+		},
+		{
+			Name:        "current context",
+			Environment: []string{"DOCKER_CONFIG=" + configDir},
+			Host:        "unix:///home/user/.colima/docker.sock",
+		},
+		{
+			Name:        "default context",
+			Environment: []string{"DOCKER_CONFIG=" + configDir, "DOCKER_CONTEXT=default"},
+			Host:        "",
+		},
+		{
+			Name:        "missing context",
+			Environment: []string{"DOCKER_CONFIG=" + configDir, "DOCKER_CONTEXT=missing"},
+			Error:       true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			helper := &DockerHelper{DockerCommand: "docker", Environment: testCase.Environment}
+			host, err := helper.resolveDockerHost()
+			if testCase.Error {
+				assert.Assert(t, err != nil)
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.Equal(t, host, testCase.Host)
+		})
+	}
+}
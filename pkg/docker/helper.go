@@ -9,8 +9,12 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/docker/docker/api/types/container"
+	dockerclient "github.com/docker/docker/client"
 	"github.com/loft-sh/devpod/pkg/command"
 	"github.com/loft-sh/devpod/pkg/devcontainer/config"
 	"github.com/loft-sh/devpod/pkg/image"
@@ -56,6 +60,11 @@ type DockerHelper struct {
 	Environment []string
 	Builder     DockerBuilder
 	Log         log.Logger
+
+	// find, inspect, start, stop, remove, logs and exec use the docker engine api
+	// if it is reachable and fall back to the cli otherwise
+	apiOnce sync.Once
+	api     *dockerclient.Client
 }
 
 func (r *DockerHelper) GPUSupportEnabled() (bool, error) {
@@ -67,6 +76,27 @@ func (r *DockerHelper) GPUSupportEnabled() (bool, error) {
 	return strings.Contains(string(out), "nvidia-container-runtime"), nil
 }
 
+// DockerInfo holds the subset of docker info DevPod is interested in
+type DockerInfo struct {
+	NCPU     int   `json:"NCPU,omitempty"`
+	MemTotal int64 `json:"MemTotal,omitempty"`
+}
+
+func (r *DockerHelper) Info(ctx context.Context) (*DockerInfo, error) {
+	out, err := r.buildCmd(ctx, "info", "--format", "{{json .}}").Output()
+	if err != nil {
+		return nil, command.WrapCommandError(out, err)
+	}
+
+	info := &DockerInfo{}
+	err = json.Unmarshal(out, info)
+	if err != nil {
+		return nil, perrors.Wrap(err, "parse docker info")
+	}
+
+	return info, nil
+}
+
 func (r *DockerHelper) FindDevContainer(ctx context.Context, labels []string) (*config.ContainerDetails, error) {
 	containers, err := r.FindContainer(ctx, labels)
 	if err != nil {
@@ -118,6 +148,15 @@ func (r *DockerHelper) DeleteVolume(ctx context.Context, volume string) error {
 }
 
 func (r *DockerHelper) Stop(ctx context.Context, id string) error {
+	if cli := r.apiClient(ctx); cli != nil {
+		err := cli.ContainerStop(ctx, id, container.StopOptions{})
+		if err != nil {
+			return perrors.Wrap(err, "stop container")
+		}
+
+		return nil
+	}
+
 	out, err := r.buildCmd(ctx, "stop", id).CombinedOutput()
 	if err != nil {
 		return perrors.Wrapf(err, "%s", string(out))
@@ -126,6 +165,41 @@ func (r *DockerHelper) Stop(ctx context.Context, id string) error {
 	return nil
 }
 
+// Update changes the cpus and memory of a running container, zero values are left unchanged
+func (r *DockerHelper) Update(ctx context.Context, id string, cpus float64, memory int64) error {
+	if cli := r.apiClient(ctx); cli != nil {
+		resources := container.Resources{NanoCPUs: int64(cpus * 1e9)}
+		if memory > 0 {
+			// the swap limit needs to be raised together with the memory limit
+			resources.Memory = memory
+			resources.MemorySwap = -1
+		}
+
+		_, err := cli.ContainerUpdate(ctx, id, container.UpdateConfig{Resources: resources})
+		if err != nil {
+			return perrors.Wrap(err, "update container")
+		}
+
+		return nil
+	}
+
+	args := []string{"update"}
+	if cpus > 0 {
+		args = append(args, "--cpus", strconv.FormatFloat(cpus, 'f', -1, 64))
+	}
+	if memory > 0 {
+		args = append(args, "--memory", strconv.FormatInt(memory, 10), "--memory-swap", "-1")
+	}
+	args = append(args, id)
+
+	out, err := r.buildCmd(ctx, args...).CombinedOutput()
+	if err != nil {
+		return perrors.Wrapf(err, "%s", string(out))
+	}
+
+	return nil
+}
+
 func (r *DockerHelper) Pull(ctx context.Context, image string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
 	cmd := r.buildCmd(ctx, "pull", image)
 	cmd.Stdin = stdin
@@ -135,6 +209,15 @@ func (r *DockerHelper) Pull(ctx context.Context, image string, stdin io.Reader,
 }
 
 func (r *DockerHelper) Remove(ctx context.Context, id string) error {
+	if cli := r.apiClient(ctx); cli != nil {
+		err := cli.ContainerRemove(ctx, id, container.RemoveOptions{})
+		if err != nil {
+			return perrors.Wrap(err, "remove container")
+		}
+
+		return nil
+	}
+
 	out, err := r.buildCmd(ctx, "rm", id).CombinedOutput()
 	if err != nil {
 		return perrors.Wrapf(err, "%s", string(out))
@@ -157,9 +240,16 @@ func (r *DockerHelper) RunWithDir(ctx context.Context, dir string, args []string
 }
 
 func (r *DockerHelper) StartContainer(ctx context.Context, containerId string) error {
-	out, err := r.buildCmd(ctx, "start", containerId).CombinedOutput()
-	if err != nil {
-		return perrors.Wrapf(err, "start command: %v", string(out))
+	if cli := r.apiClient(ctx); cli != nil {
+		err := cli.ContainerStart(ctx, containerId, container.StartOptions{})
+		if err != nil {
+			return perrors.Wrap(err, "start container")
+		}
+	} else {
+		out, err := r.buildCmd(ctx, "start", containerId).CombinedOutput()
+		if err != nil {
+			return perrors.Wrapf(err, "start command: %v", string(out))
+		}
 	}
 
 	container, err := r.FindContainerByID(ctx, []string{containerId})
@@ -254,11 +344,20 @@ func (r *DockerHelper) IsNerdctl() bool {
 }
 
 func (r *DockerHelper) Inspect(ctx context.Context, ids []string, inspectType string, obj interface{}) error {
-	args := []string{"inspect", "--type", inspectType}
-	args = append(args, ids...)
-	out, err := r.buildCmd(ctx, args...).Output()
-	if err != nil {
-		return fmt.Errorf("inspect container: %w", command.WrapCommandError(out, err))
+	var out []byte
+	var err error
+	if cli := r.apiClient(ctx); cli != nil && (inspectType == "container" || inspectType == "image") {
+		out, err = apiInspect(ctx, cli, ids, inspectType)
+		if err != nil {
+			return fmt.Errorf("inspect %s: %w", inspectType, err)
+		}
+	} else {
+		args := []string{"inspect", "--type", inspectType}
+		args = append(args, ids...)
+		out, err = r.buildCmd(ctx, args...).Output()
+		if err != nil {
+			return fmt.Errorf("inspect container: %w", command.WrapCommandError(out, err))
+		}
 	}
 
 	err = json.Unmarshal(out, obj)
@@ -273,6 +372,19 @@ func (r *DockerHelper) Inspect(ctx context.Context, ids []string, inspectType st
 // If no container is found, it will search for the labels manually inspecting
 // containers.
 func (r *DockerHelper) FindContainer(ctx context.Context, labels []string) ([]string, error) {
+	if cli := r.apiClient(ctx); cli != nil {
+		ids, err := apiFindContainer(ctx, cli, labels)
+		if err == nil {
+			return ids, nil
+		}
+
+		// fallback to manual search
+		if r.Log != nil {
+			r.Log.Debugf("Error listing containers through the docker engine api: %v", err)
+		}
+		return r.FindContainerJSON(ctx, labels)
+	}
+
 	args := []string{"ps", "-q", "-a"}
 	for _, label := range labels {
 		args = append(args, "--filter", "label="+label)
@@ -330,7 +442,29 @@ func (r *DockerHelper) FindContainerJSON(ctx context.Context, labels []string) (
 }
 
 func (r *DockerHelper) GetContainerLogs(ctx context.Context, id string, stdout io.Writer, stderr io.Writer) error {
-	args := []string{"logs", id}
+	return r.StreamContainerLogs(ctx, id, container.LogsOptions{}, stdout, stderr)
+}
+
+// StreamContainerLogs writes the logs of the container honouring follow, since, tail and timestamps of the options
+func (r *DockerHelper) StreamContainerLogs(ctx context.Context, id string, options container.LogsOptions, stdout io.Writer, stderr io.Writer) error {
+	if cli := r.apiClient(ctx); cli != nil {
+		return apiContainerLogs(ctx, cli, id, options, stdout, stderr)
+	}
+
+	args := []string{"logs"}
+	if options.Follow {
+		args = append(args, "--follow")
+	}
+	if options.Since != "" {
+		args = append(args, "--since", options.Since)
+	}
+	if options.Tail != "" {
+		args = append(args, "--tail", options.Tail)
+	}
+	if options.Timestamps {
+		args = append(args, "--timestamps")
+	}
+	args = append(args, id)
 	cmd := r.buildCmd(ctx, args...)
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
@@ -338,6 +472,24 @@ func (r *DockerHelper) GetContainerLogs(ctx context.Context, id string, stdout i
 	return cmd.Run()
 }
 
+// Exec runs the command as the given user in the container
+func (r *DockerHelper) Exec(ctx context.Context, id, user string, cmd []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	if cli := r.apiClient(ctx); cli != nil {
+		return apiExec(ctx, cli, id, user, cmd, stdin, stdout, stderr)
+	}
+
+	args := []string{"exec"}
+	if stdin != nil {
+		args = append(args, "-i")
+	}
+	if user != "" {
+		args = append(args, "-u", user)
+	}
+	args = append(args, id)
+	args = append(args, cmd...)
+	return r.Run(ctx, args, stdin, stdout, stderr)
+}
+
 func (r *DockerHelper) buildCmd(ctx context.Context, args ...string) *exec.Cmd {
 	cmd := exec.CommandContext(ctx, r.DockerCommand, args...)
 	if r.Environment != nil {
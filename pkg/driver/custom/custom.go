@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,11 +22,15 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func NewCustomDriver(workspaceInfo *provider2.AgentWorkspaceInfo, log log.Logger) driver.Driver {
+func NewCustomDriver(workspaceInfo *provider2.AgentWorkspaceInfo, log log.Logger) (driver.Driver, error) {
+	if len(workspaceInfo.Agent.Custom.Plugin) > 0 {
+		return startPlugin(workspaceInfo, log)
+	}
+
 	return &customDriver{
 		log:           log,
 		workspaceInfo: workspaceInfo,
-	}
+	}, nil
 }
 
 var _ driver.Driver = (*customDriver)(nil)
@@ -243,7 +248,20 @@ func (c *customDriver) RunDevContainer(ctx context.Context, workspaceId string,
 	return nil
 }
 
-func (c *customDriver) GetDevContainerLogs(ctx context.Context, workspaceID string, stdout io.Writer, stderr io.Writer) error {
+func (c *customDriver) GetDevContainerLogs(ctx context.Context, workspaceID string, options *driver.LogsOptions, stdout io.Writer, stderr io.Writer) error {
+	// pass the options as environment variables, commands that don't support them print the full logs
+	var extraEnv []string
+	if options != nil {
+		extraEnv = []string{
+			provider2.DEVCONTAINER_LOGS_FOLLOW + "=" + strconv.FormatBool(options.Follow),
+			provider2.DEVCONTAINER_LOGS_SINCE + "=" + options.Since,
+			provider2.DEVCONTAINER_LOGS_TIMESTAMPS + "=" + strconv.FormatBool(options.Timestamps),
+		}
+		if options.Tail >= 0 {
+			extraEnv = append(extraEnv, provider2.DEVCONTAINER_LOGS_TAIL+"="+strconv.Itoa(options.Tail))
+		}
+	}
+
 	// run command
 	err := c.runCommand(
 		ctx,
@@ -253,10 +271,10 @@ func (c *customDriver) GetDevContainerLogs(ctx context.Context, workspaceID stri
 		nil,
 		stdout,
 		stderr,
-		nil,
+		extraEnv,
 		c.log,
 	)
-	if err != nil {
+	if err != nil && ctx.Err() == nil {
 		return fmt.Errorf("error getting devcontainer logs: %w", err)
 	}
 
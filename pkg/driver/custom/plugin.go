@@ -0,0 +1,99 @@
+package custom
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/loft-sh/devpod/pkg/client/clientimplementation"
+	"github.com/loft-sh/devpod/pkg/driver"
+	"github.com/loft-sh/devpod/pkg/driver/plugin"
+	provider2 "github.com/loft-sh/devpod/pkg/provider"
+	"github.com/loft-sh/log"
+	"github.com/sirupsen/logrus"
+)
+
+// pluginExitTimeout is the time the plugin has to exit after its stdin was closed before it is killed
+const pluginExitTimeout = 5 * time.Second
+
+// pluginDriver forwards all calls to the plugin process, closing it stops the process
+type pluginDriver struct {
+	*plugin.Client
+
+	stdin  io.Closer
+	cancel context.CancelFunc
+	exited chan struct{}
+}
+
+// Close closes the stdin of the plugin and kills it if it doesn't exit in time
+func (p *pluginDriver) Close() error {
+	_ = p.stdin.Close()
+	select {
+	case <-p.exited:
+	case <-time.After(pluginExitTimeout):
+		p.cancel()
+		<-p.exited
+	}
+
+	p.cancel()
+	return nil
+}
+
+// startPlugin starts the long-lived plugin of the custom driver. The plugin runs until the driver is closed
+// or DevPod closes its stdin, which happens at the latest when the DevPod process exits.
+func startPlugin(workspaceInfo *provider2.AgentWorkspaceInfo, log log.Logger) (driver.Driver, error) {
+	command := workspaceInfo.Agent.Custom.Plugin
+	log.Debugf("Start driver plugin: %s", strings.Join(command, " "))
+
+	environ, err := ToEnvironWithBinaries(workspaceInfo, log)
+	if err != nil {
+		return nil, err
+	}
+	if log.GetLevel() == logrus.DebugLevel {
+		environ = append(environ, clientimplementation.DevPodDebug+"=true")
+	}
+
+	// use os pipes, so the plugin exiting isn't blocked by copying stdin
+	stdinReader, stdinWriter, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		_ = stdinReader.Close()
+		_ = stdinWriter.Close()
+		return nil, err
+	}
+
+	pluginCtx, cancel := context.WithCancel(context.Background())
+	p := &pluginDriver{
+		stdin:  stdinWriter,
+		cancel: cancel,
+		exited: make(chan struct{}),
+	}
+	go func() {
+		defer close(p.exited)
+		stderr := log.Writer(logrus.DebugLevel, false)
+		defer stderr.Close()
+
+		err := clientimplementation.RunCommand(pluginCtx, command, environ, stdinReader, stdoutWriter, stderr)
+		log.Debugf("Driver plugin exited: %v", err)
+		_ = stdinReader.Close()
+		_ = stdoutWriter.Close()
+	}()
+
+	ctx, cancelHandshake := context.WithTimeout(context.Background(), time.Minute)
+	defer cancelHandshake()
+	p.Client, err = plugin.NewClient(ctx, stdoutReader, stdinWriter, log)
+	if err != nil {
+		// the plugin doesn't speak the protocol, so don't wait for it to exit
+		p.cancel()
+		_ = p.Close()
+		return nil, fmt.Errorf("error starting driver plugin: %w", err)
+	}
+
+	return p, nil
+}
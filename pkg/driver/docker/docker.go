@@ -13,6 +13,7 @@ import (
 	"strconv"
 	"strings"
 
+	dockercontainer "github.com/docker/docker/api/types/container"
 	"github.com/loft-sh/devpod/pkg/compose"
 	config2 "github.com/loft-sh/devpod/pkg/config"
 	"github.com/loft-sh/devpod/pkg/devcontainer/config"
@@ -83,12 +84,7 @@ func (d *dockerDriver) CommandDevContainer(ctx context.Context, workspaceId, use
 		return fmt.Errorf("container not found")
 	}
 
-	args := []string{"exec"}
-	if stdin != nil {
-		args = append(args, "-i")
-	}
-	args = append(args, "-u", user, container.ID, "sh", "-c", command)
-	return d.Docker.Run(ctx, args, stdin, stdout, stderr)
+	return d.Docker.Exec(ctx, container.ID, user, []string{"sh", "-c", command}, stdin, stdout, stderr)
 }
 
 func (d *dockerDriver) PushDevContainer(ctx context.Context, image string) error {
@@ -224,6 +220,31 @@ func (d *dockerDriver) FindDevContainer(ctx context.Context, workspaceId string)
 	return containerDetails, nil
 }
 
+// ResizeDevContainer updates the cpu and memory limits of the running devcontainer
+func (d *dockerDriver) ResizeDevContainer(ctx context.Context, workspaceId string, options *driver.ResizeOptions) error {
+	container, err := d.FindDevContainer(ctx, workspaceId)
+	if err != nil {
+		return err
+	} else if container == nil {
+		return fmt.Errorf("container not found")
+	}
+
+	return d.Docker.Update(ctx, container.ID, options.CPUs, options.Memory)
+}
+
+func (d *dockerDriver) HostResources(ctx context.Context, workspaceId string) (*driver.HostResources, error) {
+	info, err := d.Docker.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// docker info doesn't include the free disk space
+	return &driver.HostResources{
+		CPUs:   float64(info.NCPU),
+		Memory: info.MemTotal,
+	}, nil
+}
+
 func (d *dockerDriver) RunDevContainer(
 	ctx context.Context,
 	workspaceId string,
@@ -245,6 +266,40 @@ func (d *dockerDriver) RunDockerDevContainer(
 	if err != nil {
 		return err
 	}
+
+	// In case we're using podman, let's use userns to keep
+	// the ID of the user (vscode) inside the container as
+	// the same of the external user.
+	// This will avoid problems of mismatching chowns on the
+	// project files.
+	extraArgs := []string{}
+	if d.Docker.IsPodman() && os.Getuid() != 0 {
+		extraArgs = append(extraArgs, "--userns", "keep-id")
+	}
+
+	err = d.runContainer(ctx, workspaceId, options, parsedConfig, ide, ideOptions, extraArgs)
+	if err != nil {
+		return err
+	}
+
+	if runtime.GOOS == "linux" && ((parsedConfig.ContainerUser != "" || parsedConfig.RemoteUser != "") &&
+		(parsedConfig.UpdateRemoteUserUID == nil || *parsedConfig.UpdateRemoteUserUID)) {
+		return d.updateRemoteUserUID(ctx, workspaceId, parsedConfig)
+	}
+
+	return nil
+}
+
+// runContainer starts the devcontainer with docker run, extraArgs are added before the run args of the devcontainer.json
+func (d *dockerDriver) runContainer(
+	ctx context.Context,
+	workspaceId string,
+	options *driver.RunOptions,
+	parsedConfig *config.DevContainerConfig,
+	ide string,
+	ideOptions map[string]config2.OptionValue,
+	extraArgs []string,
+) error {
 	helper, err := d.DockerHelper()
 	if err != nil {
 		return err
@@ -290,15 +345,7 @@ func (d *dockerDriver) RunDockerDevContainer(
 		args = append(args, "--privileged")
 	}
 
-	// In case we're using podman, let's use userns to keep
-	// the ID of the user (vscode) inside the container as
-	// the same of the external user.
-	// This will avoid problems of mismatching chowns on the
-	// project files.
-	if d.Docker.IsPodman() && os.Getuid() != 0 {
-		args = append(args, "--userns", "keep-id")
-	}
-
+	args = append(args, extraArgs...)
 	for _, capAdd := range options.CapAdd {
 		args = append(args, "--cap-add", capAdd)
 	}
@@ -308,6 +355,11 @@ func (d *dockerDriver) RunDockerDevContainer(
 
 	// mounts
 	for _, mount := range options.Mounts {
+		if mount.IsKubernetesMount() {
+			d.Log.Warnf("Mount type '%s' of mount '%s' is only supported by the kubernetes driver, will skip", mount.Type, mount.String())
+			continue
+		}
+
 		args = append(args, "--mount", mount.String())
 	}
 
@@ -382,186 +434,186 @@ func (d *dockerDriver) RunDockerDevContainer(
 	writer := d.Log.Writer(logrus.InfoLevel, false)
 	defer writer.Close()
 
-	err = d.Docker.Run(ctx, args, nil, writer, writer)
+	return d.Docker.Run(ctx, args, nil, writer, writer)
+}
+
+// updateRemoteUserUID changes the uid and gid of the container user to the ones of the local user
+func (d *dockerDriver) updateRemoteUserUID(ctx context.Context, workspaceId string, parsedConfig *config.DevContainerConfig) error {
+	writer := d.Log.Writer(logrus.InfoLevel, false)
+	defer writer.Close()
+
+	// Retrieve local user UID and GID
+	localUser, err := user.Current()
 	if err != nil {
 		return err
 	}
+	localUid := localUser.Uid
+	localGid := localUser.Gid
 
-	if runtime.GOOS == "linux" && ((parsedConfig.ContainerUser != "" || parsedConfig.RemoteUser != "") &&
-		(parsedConfig.UpdateRemoteUserUID == nil || *parsedConfig.UpdateRemoteUserUID)) {
-		// Retrieve local user UID and GID
-		localUser, err := user.Current()
-		if err != nil {
-			return err
-		}
-		localUid := localUser.Uid
-		localGid := localUser.Gid
-
-		// Retrieve user to update
-		containerUser := parsedConfig.RemoteUser
-		if containerUser == "" {
-			containerUser = parsedConfig.ContainerUser
-		}
-		if containerUser == "" {
-			return nil
-		}
-		container, err := d.FindDevContainer(ctx, workspaceId)
-		if err != nil {
-			return err
-		} else if container == nil {
-			return nil
-		}
-
-		// Create temporary files to store /etc/passwd and /etc/group
-		containerPasswdFileIn, err := os.CreateTemp("", "devpod_container_passwd_in")
-		if err != nil {
-			return err
-		}
-		defer os.Remove(containerPasswdFileIn.Name())
+	// Retrieve user to update
+	containerUser := parsedConfig.RemoteUser
+	if containerUser == "" {
+		containerUser = parsedConfig.ContainerUser
+	}
+	if containerUser == "" {
+		return nil
+	}
+	container, err := d.FindDevContainer(ctx, workspaceId)
+	if err != nil {
+		return err
+	} else if container == nil {
+		return nil
+	}
 
-		containerGroupFileIn, err := os.CreateTemp("", "devpod_container_group_in")
-		if err != nil {
-			return err
-		}
-		defer os.Remove(containerGroupFileIn.Name())
+	// Create temporary files to store /etc/passwd and /etc/group
+	containerPasswdFileIn, err := os.CreateTemp("", "devpod_container_passwd_in")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(containerPasswdFileIn.Name())
 
-		containerPasswdFileOut, err := os.CreateTemp("", "devpod_container_passwd_out")
-		if err != nil {
-			return err
-		}
-		defer os.Remove(containerPasswdFileOut.Name())
+	containerGroupFileIn, err := os.CreateTemp("", "devpod_container_group_in")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(containerGroupFileIn.Name())
 
-		containerGroupFileOut, err := os.CreateTemp("", "devpod_container_group_out")
-		if err != nil {
-			return err
-		}
-		defer os.Remove(containerGroupFileOut.Name())
+	containerPasswdFileOut, err := os.CreateTemp("", "devpod_container_passwd_out")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(containerPasswdFileOut.Name())
 
-		// Copy /etc/passwd and /etc/group from the container to the temporary files
-		args = []string{"cp", fmt.Sprintf("%s:/etc/passwd", container.ID), containerPasswdFileIn.Name()}
-		d.Log.Debugf("Running docker command: %s %s", d.Docker.DockerCommand, strings.Join(args, " "))
-		err = d.Docker.Run(ctx, args, nil, writer, writer)
-		if err != nil {
-			return err
-		}
+	containerGroupFileOut, err := os.CreateTemp("", "devpod_container_group_out")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(containerGroupFileOut.Name())
 
-		args = []string{"cp", fmt.Sprintf("%s:/etc/group", container.ID), containerGroupFileIn.Name()}
-		d.Log.Debugf("Running docker command: %s %s", d.Docker.DockerCommand, strings.Join(args, " "))
-		err = d.Docker.Run(ctx, args, nil, writer, writer)
-		if err != nil {
-			return err
-		}
+	// Copy /etc/passwd and /etc/group from the container to the temporary files
+	args := []string{"cp", fmt.Sprintf("%s:/etc/passwd", container.ID), containerPasswdFileIn.Name()}
+	d.Log.Debugf("Running docker command: %s %s", d.Docker.DockerCommand, strings.Join(args, " "))
+	err = d.Docker.Run(ctx, args, nil, writer, writer)
+	if err != nil {
+		return err
+	}
 
-		containerPasswdFileIn, err = os.Open(containerPasswdFileIn.Name())
-		if err != nil {
-			return err
-		}
-		defer containerPasswdFileIn.Close()
-		// Update /etc/passwd and /etc/group with the new user UID and GID
-		scanner := bufio.NewScanner(containerPasswdFileIn)
-		containerUid := ""
-		containerGid := ""
-		containerHome := ""
-
-		re := regexp.MustCompile(fmt.Sprintf(`^%s:(?P<password>x?):(?P<uid>.*):(?P<gid>.*):(?P<gcos>.*):(?P<home>.*):(?P<shell>.*)$`, containerUser))
-		for scanner.Scan() {
-			match := re.FindStringSubmatch(scanner.Text())
-			if match == nil {
-				_, err := containerPasswdFileOut.WriteString(fmt.Sprintf("%s\n", scanner.Text()))
-				if err != nil {
-					return err
-				}
-				continue
-			}
-			result := make(map[string]string)
-			for i, name := range re.SubexpNames() {
-				if i != 0 && name != "" {
-					result[name] = match[i]
-				}
-			}
-			containerUid = result["uid"]
-			containerGid = result["gid"]
-			containerHome = result["home"]
+	args = []string{"cp", fmt.Sprintf("%s:/etc/group", container.ID), containerGroupFileIn.Name()}
+	d.Log.Debugf("Running docker command: %s %s", d.Docker.DockerCommand, strings.Join(args, " "))
+	err = d.Docker.Run(ctx, args, nil, writer, writer)
+	if err != nil {
+		return err
+	}
 
-			_, err := containerPasswdFileOut.WriteString(fmt.Sprintf("%s:%s:%s:%s:%s:%s:%s\n", containerUser, result["password"], localUid, localGid, result["gcos"], result["home"], result["shell"]))
+	containerPasswdFileIn, err = os.Open(containerPasswdFileIn.Name())
+	if err != nil {
+		return err
+	}
+	defer containerPasswdFileIn.Close()
+	// Update /etc/passwd and /etc/group with the new user UID and GID
+	scanner := bufio.NewScanner(containerPasswdFileIn)
+	containerUid := ""
+	containerGid := ""
+	containerHome := ""
+
+	re := regexp.MustCompile(fmt.Sprintf(`^%s:(?P<password>x?):(?P<uid>.*):(?P<gid>.*):(?P<gcos>.*):(?P<home>.*):(?P<shell>.*)$`, containerUser))
+	for scanner.Scan() {
+		match := re.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			_, err := containerPasswdFileOut.WriteString(fmt.Sprintf("%s\n", scanner.Text()))
 			if err != nil {
 				return err
 			}
+			continue
 		}
-
-		if err := scanner.Err(); err != nil {
-			return err
-		}
-
-		if localUid == "0" || containerUid == "0" || (localUid == containerUid && localGid == containerGid) {
-			return nil
+		result := make(map[string]string)
+		for i, name := range re.SubexpNames() {
+			if i != 0 && name != "" {
+				result[name] = match[i]
+			}
 		}
+		containerUid = result["uid"]
+		containerGid = result["gid"]
+		containerHome = result["home"]
 
-		containerGroupFileIn, err = os.Open(containerGroupFileIn.Name())
+		_, err := containerPasswdFileOut.WriteString(fmt.Sprintf("%s:%s:%s:%s:%s:%s:%s\n", containerUser, result["password"], localUid, localGid, result["gcos"], result["home"], result["shell"]))
 		if err != nil {
 			return err
 		}
-		defer containerGroupFileIn.Close()
-
-		scanner = bufio.NewScanner(containerGroupFileIn)
-
-		re = regexp.MustCompile(fmt.Sprintf(`^(?P<group>.*):(?P<password>x?):%s:(?P<group_list>.*)$`, containerGid))
-		for scanner.Scan() {
-			match := re.FindStringSubmatch(scanner.Text())
-			if match == nil {
-				_, err := containerGroupFileOut.WriteString(fmt.Sprintf("%s\n", scanner.Text()))
-				if err != nil {
-					return err
-				}
-				continue
-			}
-			result := make(map[string]string)
-			for i, name := range re.SubexpNames() {
-				if i != 0 && name != "" {
-					result[name] = match[i]
-				}
-			}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if localUid == "0" || containerUid == "0" || (localUid == containerUid && localGid == containerGid) {
+		return nil
+	}
+
+	containerGroupFileIn, err = os.Open(containerGroupFileIn.Name())
+	if err != nil {
+		return err
+	}
+	defer containerGroupFileIn.Close()
+
+	scanner = bufio.NewScanner(containerGroupFileIn)
 
-			_, err := containerGroupFileOut.WriteString(fmt.Sprintf("%s:%s:%s:%s\n", result["group"], result["password"], localGid, result["group_list"]))
+	re = regexp.MustCompile(fmt.Sprintf(`^(?P<group>.*):(?P<password>x?):%s:(?P<group_list>.*)$`, containerGid))
+	for scanner.Scan() {
+		match := re.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			_, err := containerGroupFileOut.WriteString(fmt.Sprintf("%s\n", scanner.Text()))
 			if err != nil {
 				return err
 			}
+			continue
 		}
-
-		if err := scanner.Err(); err != nil {
-			return err
+		result := make(map[string]string)
+		for i, name := range re.SubexpNames() {
+			if i != 0 && name != "" {
+				result[name] = match[i]
+			}
 		}
 
-		d.Log.Debugf("Updating container user uid")
-
-		// Copy /etc/passwd and /etc/group back to the container
-		args = []string{"cp", containerPasswdFileOut.Name(), fmt.Sprintf("%s:/etc/passwd", container.ID)}
-		d.Log.Debugf("Running docker command: %s %s", d.Docker.DockerCommand, strings.Join(args, " "))
-		err = d.Docker.Run(ctx, args, nil, writer, writer)
+		_, err := containerGroupFileOut.WriteString(fmt.Sprintf("%s:%s:%s:%s\n", result["group"], result["password"], localGid, result["group_list"]))
 		if err != nil {
 			return err
 		}
+	}
 
-		args = []string{"cp", containerGroupFileOut.Name(), fmt.Sprintf("%s:/etc/group", container.ID)}
-		d.Log.Debugf("Running docker command: %s %s", d.Docker.DockerCommand, strings.Join(args, " "))
-		err = d.Docker.Run(ctx, args, nil, writer, writer)
-		if err != nil {
-			return err
-		}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
 
-		args = []string{"exec", "-u", "root", container.ID, "chmod", "644", "/etc/passwd", "/etc/group"}
-		d.Log.Debugf("Running docker command: %s %s", d.Docker.DockerCommand, strings.Join(args, " "))
-		err = d.Docker.Run(ctx, args, nil, writer, writer)
-		if err != nil {
-			return err
-		}
+	d.Log.Debugf("Updating container user uid")
 
-		args = []string{"exec", "-u", "root", container.ID, "chown", "-R", fmt.Sprintf("%s:%s", localUid, localGid), containerHome}
-		d.Log.Debugf("Running docker command: %s %s", d.Docker.DockerCommand, strings.Join(args, " "))
-		err = d.Docker.Run(ctx, args, nil, writer, writer)
-		if err != nil {
-			return err
-		}
+	// Copy /etc/passwd and /etc/group back to the container
+	args = []string{"cp", containerPasswdFileOut.Name(), fmt.Sprintf("%s:/etc/passwd", container.ID)}
+	d.Log.Debugf("Running docker command: %s %s", d.Docker.DockerCommand, strings.Join(args, " "))
+	err = d.Docker.Run(ctx, args, nil, writer, writer)
+	if err != nil {
+		return err
+	}
+
+	args = []string{"cp", containerGroupFileOut.Name(), fmt.Sprintf("%s:/etc/group", container.ID)}
+	d.Log.Debugf("Running docker command: %s %s", d.Docker.DockerCommand, strings.Join(args, " "))
+	err = d.Docker.Run(ctx, args, nil, writer, writer)
+	if err != nil {
+		return err
+	}
+
+	args = []string{"exec", "-u", "root", container.ID, "chmod", "644", "/etc/passwd", "/etc/group"}
+	d.Log.Debugf("Running docker command: %s %s", d.Docker.DockerCommand, strings.Join(args, " "))
+	err = d.Docker.Run(ctx, args, nil, writer, writer)
+	if err != nil {
+		return err
+	}
+
+	args = []string{"exec", "-u", "root", container.ID, "chown", "-R", fmt.Sprintf("%s:%s", localUid, localGid), containerHome}
+	d.Log.Debugf("Running docker command: %s %s", d.Docker.DockerCommand, strings.Join(args, " "))
+	err = d.Docker.Run(ctx, args, nil, writer, writer)
+	if err != nil {
+		return err
 	}
 
 	return nil
@@ -605,13 +657,29 @@ func (d *dockerDriver) EnsurePath(path *config.Mount) *config.Mount {
 	return path
 }
 
-func (d *dockerDriver) GetDevContainerLogs(ctx context.Context, workspaceId string, stdout io.Writer, stderr io.Writer) error {
+func (d *dockerDriver) GetDevContainerLogs(ctx context.Context, workspaceId string, options *driver.LogsOptions, stdout io.Writer, stderr io.Writer) error {
 	container, err := d.FindDevContainer(ctx, workspaceId)
 	if err != nil {
 		return err
 	} else if container == nil {
 		return fmt.Errorf("container not found")
+	} else if options == nil {
+		return d.Docker.GetContainerLogs(ctx, container.ID, stdout, stderr)
+	}
+
+	logsOptions := dockercontainer.LogsOptions{
+		Follow:     options.Follow,
+		Since:      options.Since,
+		Timestamps: options.Timestamps,
+	}
+	if options.Tail >= 0 {
+		logsOptions.Tail = strconv.Itoa(options.Tail)
+	}
+
+	err = d.Docker.StreamContainerLogs(ctx, container.ID, logsOptions, stdout, stderr)
+	if err != nil && ctx.Err() == nil {
+		return err
 	}
 
-	return d.Docker.GetContainerLogs(ctx, container.ID, stdout, stderr)
+	return nil
 }
@@ -0,0 +1,396 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	config2 "github.com/loft-sh/devpod/pkg/config"
+	"github.com/loft-sh/devpod/pkg/devcontainer/config"
+	"github.com/loft-sh/devpod/pkg/docker"
+	"github.com/loft-sh/devpod/pkg/driver"
+	"github.com/loft-sh/devpod/pkg/passwd"
+	provider2 "github.com/loft-sh/devpod/pkg/provider"
+	"github.com/loft-sh/devpod/pkg/types"
+	"github.com/loft-sh/log"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// NewPodmanDriver creates a driver that runs the devcontainer with podman. In contrast to podman through the docker
+// driver, it maps the local user to the container user in rootless mode and runs docker compose services in a pod.
+func NewPodmanDriver(workspaceInfo *provider2.AgentWorkspaceInfo, log log.Logger) (driver.DockerDriver, error) {
+	podmanCommand := "podman"
+	if workspaceInfo.Agent.Podman.Path != "" {
+		podmanCommand = workspaceInfo.Agent.Podman.Path
+	}
+
+	builder, err := docker.DockerBuilderFromString(workspaceInfo.Agent.Podman.Builder)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Using podman command '%s'", podmanCommand)
+	return &podmanDriver{
+		dockerDriver: &dockerDriver{
+			Docker: &docker.DockerHelper{
+				DockerCommand: podmanCommand,
+				Environment:   makeEnvironment(workspaceInfo.Agent.Podman.Env, log),
+				ContainerID:   workspaceInfo.Workspace.Source.Container,
+				Builder:       builder,
+				Log:           log,
+			},
+			Log: log,
+		},
+	}, nil
+}
+
+type podmanDriver struct {
+	*dockerDriver
+}
+
+// SupportsSidecars returns true as sidecars run in a pod together with the devcontainer
+func (p *podmanDriver) SupportsSidecars() bool {
+	return true
+}
+
+func (p *podmanDriver) RunDockerDevContainer(
+	ctx context.Context,
+	workspaceId string,
+	options *driver.RunOptions,
+	parsedConfig *config.DevContainerConfig,
+	init *bool,
+	ide string,
+	ideOptions map[string]config2.OptionValue,
+) error {
+	err := p.EnsureImage(ctx, options)
+	if err != nil {
+		return err
+	}
+
+	// rootless podman maps the local user to the container user instead of rewriting /etc/passwd
+	userNamespace, user := p.userNamespace(ctx, options, parsedConfig)
+	extraArgs := []string{}
+	if user != "" {
+		extraArgs = append(extraArgs, "-u", user)
+	}
+	if len(options.Sidecars) == 0 {
+		if userNamespace != "" {
+			extraArgs = append(extraArgs, "--userns", userNamespace)
+		}
+		err = p.runContainer(ctx, workspaceId, options, parsedConfig, ide, ideOptions, extraArgs)
+		if err != nil {
+			return err
+		}
+
+		return p.updateRemoteUserUIDWithoutMapping(ctx, workspaceId, parsedConfig, userNamespace)
+	}
+
+	// the pod publishes the ports and shares the network and user namespace with all containers
+	err = p.createPod(ctx, workspaceId, parsedConfig.AppPort, options.Sidecars, userNamespace)
+	if err != nil {
+		return errors.Wrap(err, "create pod")
+	}
+	for _, sidecar := range options.Sidecars {
+		err = p.runSidecar(ctx, workspaceId, sidecar)
+		if err != nil {
+			return errors.Wrapf(err, "run sidecar %s", sidecar.Name)
+		}
+	}
+
+	podConfig := config.CloneDevContainerConfig(parsedConfig)
+	podConfig.AppPort = nil
+	extraArgs = append(extraArgs, "--pod", getPodName(workspaceId))
+	err = p.runContainer(ctx, workspaceId, options, podConfig, ide, ideOptions, extraArgs)
+	if err != nil {
+		return err
+	}
+
+	return p.updateRemoteUserUIDWithoutMapping(ctx, workspaceId, parsedConfig, userNamespace)
+}
+
+// updateRemoteUserUIDWithoutMapping falls back to rewriting the uid of the remote user like the docker driver,
+// if the local user couldn't be mapped, e.g. because podman runs rootful
+func (p *podmanDriver) updateRemoteUserUIDWithoutMapping(ctx context.Context, workspaceId string, parsedConfig *config.DevContainerConfig, userNamespace string) error {
+	if userNamespace != "" || runtime.GOOS != "linux" || (parsedConfig.ContainerUser == "" && parsedConfig.RemoteUser == "") ||
+		(parsedConfig.UpdateRemoteUserUID != nil && !*parsedConfig.UpdateRemoteUserUID) {
+		return nil
+	}
+
+	return p.updateRemoteUserUID(ctx, workspaceId, parsedConfig)
+}
+
+// isRootless returns true if podman runs rootless, which is required for keep-id. Podman might run rootful
+// even for non-root users, e.g. through sudo or a rootful podman machine.
+func (p *podmanDriver) isRootless(ctx context.Context) bool {
+	stdout := &bytes.Buffer{}
+	err := p.Docker.Run(ctx, []string{"info", "--format", "{{.Host.Security.Rootless}}"}, nil, stdout, io.Discard)
+	if err != nil {
+		p.Log.Debugf("Error checking if podman runs rootless: %v", err)
+		return os.Getuid() != 0
+	}
+
+	return strings.TrimSpace(stdout.String()) == "true"
+}
+
+// userNamespace returns the keep-id user namespace that maps the local user to the uid and gid of the remote user.
+// Nothing is mapped for root users, as rootless podman already maps the local user to root, and for rootful podman,
+// which doesn't support keep-id. As keep-id also changes the user the container runs as, the default user of the
+// image is returned if no container user is configured.
+func (p *podmanDriver) userNamespace(ctx context.Context, options *driver.RunOptions, parsedConfig *config.DevContainerConfig) (string, string) {
+	if hasRunArg(parsedConfig.RunArgs, "--userns") ||
+		(parsedConfig.UpdateRemoteUserUID != nil && !*parsedConfig.UpdateRemoteUserUID) || !p.isRootless(ctx) {
+		return "", ""
+	}
+
+	user := ""
+	if options.User == "" {
+		imageDetails, err := p.Docker.InspectImage(ctx, options.Image, false)
+		if err != nil {
+			p.Log.Debugf("Error inspecting image %s: %v", options.Image, err)
+			return "", ""
+		}
+
+		user = imageDetails.Config.User
+		if user == "" {
+			user = "root"
+		}
+	}
+
+	containerUser := parsedConfig.RemoteUser
+	if containerUser == "" {
+		containerUser = options.User
+	}
+	if containerUser == "" {
+		containerUser = user
+	}
+
+	uid, gid, err := p.lookupUser(ctx, options.Image, containerUser)
+	if err != nil {
+		p.Log.Warnf("Error looking up user %s in image %s, files in the workspace might not be owned by the user: %v", containerUser, options.Image, err)
+		return "", ""
+	} else if uid == "0" {
+		return "", ""
+	}
+
+	return fmt.Sprintf("keep-id:uid=%s,gid=%s", uid, gid), user
+}
+
+// lookupUser returns the uid and gid of the given user from the /etc/passwd of the image
+func (p *podmanDriver) lookupUser(ctx context.Context, image, containerUser string) (string, string, error) {
+	name, group, _ := strings.Cut(containerUser, ":")
+	if name == "" || name == "root" {
+		return "0", "0", nil
+	} else if _, err := strconv.Atoi(name); err == nil && group != "" {
+		return name, group, nil
+	}
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	err := p.Docker.Run(ctx, []string{"run", "--rm", "--entrypoint", "cat", image, "/etc/passwd"}, nil, stdout, stderr)
+	if err != nil {
+		return "", "", fmt.Errorf("read /etc/passwd: %s: %w", strings.TrimSpace(stderr.String()), err)
+	}
+
+	uid, gid, ok := passwd.LookupUser(stdout, name)
+	if !ok {
+		return "", "", fmt.Errorf("user not found")
+	}
+	if group != "" {
+		gid = group
+	}
+
+	return uid, gid, nil
+}
+
+func hasRunArg(runArgs []string, flag string) bool {
+	for _, arg := range runArgs {
+		if arg == flag || strings.HasPrefix(arg, flag+"=") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func getPodName(workspaceId string) string {
+	return "devpod-" + workspaceId
+}
+
+func (p *podmanDriver) createPod(ctx context.Context, workspaceId string, appPorts types.StrIntArray, sidecars []*driver.SidecarOptions, userNamespace string) error {
+	// remove a leftover pod of a previous run
+	podName := getPodName(workspaceId)
+	if p.podExists(ctx, workspaceId) {
+		err := p.runPodman(ctx, "pod", "rm", "-f", podName)
+		if err != nil {
+			return err
+		}
+	}
+
+	args := []string{"pod", "create", "--name", podName}
+	for _, appPort := range appPorts {
+		intPort, err := strconv.Atoi(appPort)
+		if err != nil {
+			args = append(args, "-p", appPort)
+		} else {
+			args = append(args, "-p", fmt.Sprintf("127.0.0.1:%d:%d", intPort, intPort))
+		}
+	}
+
+	// containers in a pod share the network, so the services are reachable through localhost
+	for _, sidecar := range sidecars {
+		args = append(args, "--add-host", sidecar.Name+":127.0.0.1")
+	}
+	if userNamespace != "" {
+		args = append(args, "--userns", userNamespace)
+	}
+
+	return p.runPodman(ctx, args...)
+}
+
+func (p *podmanDriver) runSidecar(ctx context.Context, workspaceId string, sidecar *driver.SidecarOptions) error {
+	args := []string{"run", "-d", "--pod", getPodName(workspaceId), "--name", getPodName(workspaceId) + "-" + sidecar.Name}
+	if sidecar.User != "" {
+		args = append(args, "-u", sidecar.User)
+	}
+	for k, v := range sidecar.Env {
+		args = append(args, "-e", k+"="+v)
+	}
+	for _, capAdd := range sidecar.CapAdd {
+		args = append(args, "--cap-add", capAdd)
+	}
+	if sidecar.Privileged != nil && *sidecar.Privileged {
+		args = append(args, "--privileged")
+	}
+	for _, mount := range sidecar.Mounts {
+		switch mount.Type {
+		case "bind", "volume":
+			args = append(args, "--mount", fmt.Sprintf("type=%s,src=%s,dst=%s", mount.Type, mount.Source, mount.Target))
+		case "tmpfs":
+			args = append(args, "--tmpfs", mount.Target)
+		default:
+			p.Log.Warnf("Skipping %s mount %s of sidecar %s, it is not supported by podman", mount.Type, mount.Target, sidecar.Name)
+		}
+	}
+	if sidecar.Healthcheck != nil && len(sidecar.Healthcheck.Command) > 0 {
+		healthCmd, err := json.Marshal(append([]string{"CMD"}, sidecar.Healthcheck.Command...))
+		if err != nil {
+			return err
+		}
+		args = append(args, "--health-cmd", string(healthCmd))
+		if sidecar.Healthcheck.Interval > 0 {
+			args = append(args, "--health-interval", sidecar.Healthcheck.Interval.String())
+		}
+		if sidecar.Healthcheck.Timeout > 0 {
+			args = append(args, "--health-timeout", sidecar.Healthcheck.Timeout.String())
+		}
+		if sidecar.Healthcheck.Retries > 0 {
+			args = append(args, "--health-retries", strconv.Itoa(sidecar.Healthcheck.Retries))
+		}
+	}
+	if len(sidecar.Entrypoint) > 0 {
+		entrypoint, err := json.Marshal(sidecar.Entrypoint)
+		if err != nil {
+			return err
+		}
+		args = append(args, "--entrypoint", string(entrypoint))
+	}
+	args = append(args, sidecar.Image)
+	args = append(args, sidecar.Cmd...)
+
+	return p.runPodman(ctx, args...)
+}
+
+func (p *podmanDriver) podExists(ctx context.Context, workspaceId string) bool {
+	return p.Docker.Run(ctx, []string{"pod", "exists", getPodName(workspaceId)}, nil, io.Discard, io.Discard) == nil
+}
+
+func (p *podmanDriver) runPodman(ctx context.Context, args ...string) error {
+	writer := p.Log.Writer(logrus.DebugLevel, false)
+	defer writer.Close()
+
+	p.Log.Debugf("Running podman command: %s %s", p.Docker.DockerCommand, strings.Join(args, " "))
+	stderr := &bytes.Buffer{}
+	err := p.Docker.Run(ctx, args, nil, writer, io.MultiWriter(writer, stderr))
+	if err != nil {
+		return fmt.Errorf("%s: %w", strings.TrimSpace(stderr.String()), err)
+	}
+
+	return nil
+}
+
+func (p *podmanDriver) StartDevContainer(ctx context.Context, workspaceId string) error {
+	if !p.podExists(ctx, workspaceId) {
+		return p.dockerDriver.StartDevContainer(ctx, workspaceId)
+	}
+
+	return p.runPodman(ctx, "pod", "start", getPodName(workspaceId))
+}
+
+func (p *podmanDriver) StopDevContainer(ctx context.Context, workspaceId string) error {
+	if !p.podExists(ctx, workspaceId) {
+		return p.dockerDriver.StopDevContainer(ctx, workspaceId)
+	}
+
+	return p.runPodman(ctx, "pod", "stop", getPodName(workspaceId))
+}
+
+func (p *podmanDriver) DeleteDevContainer(ctx context.Context, workspaceId string) error {
+	if !p.podExists(ctx, workspaceId) {
+		return p.dockerDriver.DeleteDevContainer(ctx, workspaceId)
+	}
+
+	return p.runPodman(ctx, "pod", "rm", "-f", getPodName(workspaceId))
+}
+
+// GenerateKube writes the kubernetes manifests of the pod or the devcontainer to stdout
+func (p *podmanDriver) GenerateKube(ctx context.Context, workspaceId string, stdout io.Writer) error {
+	name := getPodName(workspaceId)
+	if !p.podExists(ctx, workspaceId) {
+		container, err := p.FindDevContainer(ctx, workspaceId)
+		if err != nil {
+			return err
+		} else if container == nil {
+			return fmt.Errorf("container not found")
+		}
+
+		name = container.ID
+	}
+
+	stderr := &bytes.Buffer{}
+	err := p.Docker.Run(ctx, []string{"generate", "kube", name}, nil, stdout, stderr)
+	if err != nil {
+		return fmt.Errorf("generate kube: %s: %w", strings.TrimSpace(stderr.String()), err)
+	}
+
+	return nil
+}
+
+// HostResources returns the resources of the podman machine or host, podman info doesn't use the docker format
+func (p *podmanDriver) HostResources(ctx context.Context, workspaceId string) (*driver.HostResources, error) {
+	stdout := &bytes.Buffer{}
+	err := p.Docker.Run(ctx, []string{"info", "--format", "{{json .Host}}"}, nil, stdout, io.Discard)
+	if err != nil {
+		return nil, errors.Wrap(err, "podman info")
+	}
+
+	host := struct {
+		CPUs     int   `json:"cpus,omitempty"`
+		MemTotal int64 `json:"memTotal,omitempty"`
+	}{}
+	err = json.Unmarshal(stdout.Bytes(), &host)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse podman info")
+	}
+
+	return &driver.HostResources{
+		CPUs:   float64(host.CPUs),
+		Memory: host.MemTotal,
+	}, nil
+}
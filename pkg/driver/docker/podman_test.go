@@ -0,0 +1,13 @@
+package docker
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestHasRunArg(t *testing.T) {
+	assert.Assert(t, hasRunArg([]string{"--cap-add", "SYS_PTRACE", "--userns=host"}, "--userns"))
+	assert.Assert(t, hasRunArg([]string{"--userns", "host"}, "--userns"))
+	assert.Assert(t, !hasRunArg([]string{"--usernsx"}, "--userns"))
+}
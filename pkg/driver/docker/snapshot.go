@@ -0,0 +1,236 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/loft-sh/devpod/pkg/driver"
+	"github.com/loft-sh/devpod/pkg/extract"
+	"github.com/pkg/errors"
+)
+
+const (
+	SnapshotWorkspaceLabel = "devpod.snapshot.workspace"
+	SnapshotNameLabel      = "devpod.snapshot.name"
+	SnapshotCreatedLabel   = "devpod.snapshot.created"
+)
+
+var _ driver.SnapshotDriver = (*dockerDriver)(nil)
+
+type snapshotImage struct {
+	ID       string
+	RepoTags []string
+	Size     int64
+	Config   struct {
+		Labels map[string]string
+	}
+}
+
+// CreateSnapshot commits the devcontainer into an image and exports the workspace folder, which isn't part of the commit
+func (d *dockerDriver) CreateSnapshot(ctx context.Context, workspaceID string, options *driver.SnapshotOptions) (*driver.Snapshot, error) {
+	container, err := d.FindDevContainer(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	} else if container == nil {
+		return nil, fmt.Errorf("container not found")
+	}
+
+	created := time.Now().UTC()
+	imageName := getSnapshotImage(workspaceID, options.Name)
+	args := []string{
+		"commit",
+		"--pause",
+		"--change", "LABEL " + SnapshotWorkspaceLabel + "=" + workspaceID,
+		"--change", "LABEL " + SnapshotNameLabel + "=" + options.Name,
+		"--change", "LABEL " + SnapshotCreatedLabel + "=" + created.Format(time.RFC3339),
+		container.ID,
+		imageName,
+	}
+	d.Log.Infof("Commit container %s into image %s", container.ID, imageName)
+	out := &bytes.Buffer{}
+	err = d.Docker.Run(ctx, args, nil, out, out)
+	if err != nil {
+		return nil, errors.Wrapf(err, "commit container: %s", out.String())
+	}
+
+	// export the workspace folder
+	if options.WorkspaceFolder != "" && options.ArchiveDir != "" {
+		d.Log.Infof("Export workspace folder %s", options.WorkspaceFolder)
+		err = exportWorkspaceFolder(options.WorkspaceFolder, getSnapshotArchive(options.ArchiveDir, options.Name))
+		if err != nil {
+			return nil, errors.Wrap(err, "export workspace folder")
+		}
+	}
+
+	snapshots, err := d.findSnapshots(ctx, workspaceID, options.Name, options.ArchiveDir)
+	if err != nil {
+		return nil, err
+	} else if len(snapshots) == 0 {
+		return nil, fmt.Errorf("couldn't find snapshot image %s", imageName)
+	}
+
+	return snapshots[0], nil
+}
+
+func (d *dockerDriver) ListSnapshots(ctx context.Context, workspaceID string, options *driver.SnapshotOptions) ([]*driver.Snapshot, error) {
+	return d.findSnapshots(ctx, workspaceID, "", options.ArchiveDir)
+}
+
+func (d *dockerDriver) DeleteSnapshot(ctx context.Context, workspaceID string, options *driver.SnapshotOptions) error {
+	snapshots, err := d.findSnapshots(ctx, workspaceID, options.Name, "")
+	if err != nil {
+		return err
+	} else if len(snapshots) == 0 {
+		return fmt.Errorf("snapshot %s not found", options.Name)
+	}
+
+	out := &bytes.Buffer{}
+	err = d.Docker.Run(ctx, []string{"rmi", snapshots[0].Image}, nil, out, out)
+	if err != nil {
+		return errors.Wrapf(err, "remove snapshot image: %s", out.String())
+	}
+
+	if options.ArchiveDir != "" {
+		err = os.Remove(getSnapshotArchive(options.ArchiveDir, options.Name))
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "remove workspace folder export")
+		}
+	}
+
+	return nil
+}
+
+// RestoreSnapshot imports the exported workspace folder, the devcontainer itself is started from the returned snapshot image
+func (d *dockerDriver) RestoreSnapshot(ctx context.Context, workspaceID string, options *driver.SnapshotOptions) (*driver.Snapshot, error) {
+	snapshots, err := d.findSnapshots(ctx, options.SourceWorkspaceID, options.Name, options.ArchiveDir)
+	if err != nil {
+		return nil, err
+	} else if len(snapshots) == 0 {
+		return nil, fmt.Errorf("snapshot %s of workspace %s not found", options.Name, options.SourceWorkspaceID)
+	}
+
+	if options.WorkspaceFolder != "" && options.ArchiveDir != "" {
+		archive, err := os.Open(getSnapshotArchive(options.ArchiveDir, options.Name))
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return nil, err
+			}
+
+			d.Log.Warnf("Snapshot %s has no workspace folder export, the workspace folder is created from the workspace source", options.Name)
+		} else {
+			defer archive.Close()
+
+			d.Log.Infof("Import workspace folder into %s", options.WorkspaceFolder)
+			err = os.MkdirAll(options.WorkspaceFolder, 0o777)
+			if err != nil {
+				return nil, err
+			}
+
+			err = extract.Extract(archive, options.WorkspaceFolder)
+			if err != nil {
+				return nil, errors.Wrap(err, "import workspace folder")
+			}
+		}
+	}
+
+	return snapshots[0], nil
+}
+
+func (d *dockerDriver) findSnapshots(ctx context.Context, workspaceID, name, archiveDir string) ([]*driver.Snapshot, error) {
+	args := []string{"images", "-q", "--no-trunc", "--filter", "label=" + SnapshotWorkspaceLabel + "=" + workspaceID}
+	if name != "" {
+		args = append(args, "--filter", "label="+SnapshotNameLabel+"="+name)
+	}
+
+	out := &bytes.Buffer{}
+	err := d.Docker.Run(ctx, args, nil, out, out)
+	if err != nil {
+		return nil, errors.Wrapf(err, "find snapshot images: %s", out.String())
+	}
+
+	ids := []string{}
+	for _, id := range strings.Fields(out.String()) {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	images := []snapshotImage{}
+	err = d.Docker.Inspect(ctx, ids, "image", &images)
+	if err != nil {
+		return nil, err
+	}
+
+	return toSnapshots(workspaceID, images, archiveDir), nil
+}
+
+// toSnapshots converts the snapshot images of the workspace. Images built from a snapshot inherit its labels, so
+// only the images that are tagged as the snapshot are returned.
+func toSnapshots(workspaceID string, images []snapshotImage, archiveDir string) []*driver.Snapshot {
+	snapshots := []*driver.Snapshot{}
+	for _, image := range images {
+		snapshot := &driver.Snapshot{
+			Name:        image.Config.Labels[SnapshotNameLabel],
+			WorkspaceID: workspaceID,
+			Image:       getSnapshotImage(workspaceID, image.Config.Labels[SnapshotNameLabel]),
+			Size:        image.Size,
+			Ready:       true,
+		}
+		if !slices.ContainsFunc(image.RepoTags, func(tag string) bool {
+			// podman prefixes local images with localhost/
+			return strings.TrimPrefix(tag, "localhost/") == snapshot.Image
+		}) {
+			continue
+		}
+
+		snapshot.Created, _ = time.Parse(time.RFC3339, image.Config.Labels[SnapshotCreatedLabel])
+		if archiveDir != "" {
+			stat, err := os.Stat(getSnapshotArchive(archiveDir, snapshot.Name))
+			if err == nil {
+				snapshot.Size += stat.Size()
+			}
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots
+}
+
+func exportWorkspaceFolder(workspaceFolder, archivePath string) error {
+	err := os.MkdirAll(filepath.Dir(archivePath), 0o755)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	err = extract.WriteTar(file, workspaceFolder, true)
+	if err != nil {
+		_ = os.Remove(archivePath)
+		return err
+	}
+
+	return nil
+}
+
+func getSnapshotImage(workspaceID, name string) string {
+	return "devpod-snapshot-" + strings.ToLower(workspaceID) + ":" + name
+}
+
+func getSnapshotArchive(archiveDir, name string) string {
+	return filepath.Join(archiveDir, name+".tar.gz")
+}
@@ -0,0 +1,65 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devpod/pkg/extract"
+	"gotest.tools/assert"
+)
+
+func TestToSnapshots(t *testing.T) {
+	archiveDir := t.TempDir()
+	err := os.WriteFile(getSnapshotArchive(archiveDir, "before-upgrade"), []byte("archive"), 0o644)
+	assert.NilError(t, err)
+
+	newImage := func(name string, tags ...string) snapshotImage {
+		image := snapshotImage{RepoTags: tags, Size: 100}
+		image.Config.Labels = map[string]string{
+			SnapshotWorkspaceLabel: "my-workspace",
+			SnapshotNameLabel:      name,
+			SnapshotCreatedLabel:   "2024-01-02T03:04:05Z",
+		}
+		return image
+	}
+	snapshots := toSnapshots("My-Workspace", []snapshotImage{
+		newImage("before-upgrade", "devpod-snapshot-my-workspace:before-upgrade"),
+		newImage("podman", "localhost/devpod-snapshot-my-workspace:podman"),
+		// built from a snapshot and inherited its labels
+		newImage("before-upgrade", "vsc-my-workspace-features:latest"),
+		newImage("before-upgrade"),
+	}, archiveDir)
+
+	assert.Equal(t, len(snapshots), 2)
+	assert.Equal(t, snapshots[0].Name, "before-upgrade")
+	assert.Equal(t, snapshots[0].Image, "devpod-snapshot-my-workspace:before-upgrade")
+	assert.Equal(t, snapshots[0].Size, int64(100+len("archive")))
+	assert.Assert(t, snapshots[0].Created.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+	assert.Equal(t, snapshots[1].Name, "podman")
+	assert.Equal(t, snapshots[1].Size, int64(100))
+}
+
+func TestExportWorkspaceFolder(t *testing.T) {
+	workspaceFolder := t.TempDir()
+	err := os.MkdirAll(filepath.Join(workspaceFolder, "src"), 0o755)
+	assert.NilError(t, err)
+	err = os.WriteFile(filepath.Join(workspaceFolder, "src", "main.go"), []byte("package main"), 0o644)
+	assert.NilError(t, err)
+
+	archive := getSnapshotArchive(filepath.Join(t.TempDir(), "snapshots"), "test")
+	err = exportWorkspaceFolder(workspaceFolder, archive)
+	assert.NilError(t, err)
+
+	file, err := os.Open(archive)
+	assert.NilError(t, err)
+	defer file.Close()
+
+	restoreFolder := t.TempDir()
+	err = extract.Extract(file, restoreFolder)
+	assert.NilError(t, err)
+	out, err := os.ReadFile(filepath.Join(restoreFolder, "src", "main.go"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(out), "package main")
+}
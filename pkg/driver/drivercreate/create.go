@@ -15,12 +15,14 @@ func NewDriver(workspaceInfo *provider2.AgentWorkspaceInfo, log log.Logger) (dri
 	driver := workspaceInfo.Agent.Driver
 	if driver == "" || driver == provider2.DockerDriver {
 		return docker.NewDockerDriver(workspaceInfo, log)
+	} else if driver == provider2.PodmanDriver {
+		return docker.NewPodmanDriver(workspaceInfo, log)
 	} else if driver == provider2.CustomDriver {
-		return custom.NewCustomDriver(workspaceInfo, log), nil
+		return custom.NewCustomDriver(workspaceInfo, log)
 	} else if driver == provider2.KubernetesDriver {
 		return kubernetes.NewKubernetesDriver(workspaceInfo, log)
 	}
 
-	return nil, fmt.Errorf("unrecognized driver '%s', possible values are %s, %s or %s",
-		driver, provider2.DockerDriver, provider2.CustomDriver, provider2.KubernetesDriver)
+	return nil, fmt.Errorf("unrecognized driver '%s', possible values are %s, %s, %s or %s",
+		driver, provider2.DockerDriver, provider2.PodmanDriver, provider2.CustomDriver, provider2.KubernetesDriver)
 }
@@ -79,10 +79,14 @@ func (c *Client) FullLogs(ctx context.Context, namespace, pod, container string)
 }
 
 func (c *Client) Logs(ctx context.Context, namespace, pod, container string, follow bool) (io.ReadCloser, error) {
-	return c.client.CoreV1().Pods(namespace).GetLogs(pod, &corev1.PodLogOptions{
+	return c.LogsWithOptions(ctx, namespace, pod, &corev1.PodLogOptions{
 		Container: container,
 		Follow:    follow,
-	}).Stream(ctx)
+	})
+}
+
+func (c *Client) LogsWithOptions(ctx context.Context, namespace, pod string, options *corev1.PodLogOptions) (io.ReadCloser, error) {
+	return c.client.CoreV1().Pods(namespace).GetLogs(pod, options).Stream(ctx)
 }
 
 type ExecStreamOptions struct {
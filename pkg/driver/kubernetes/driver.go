@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/loft-sh/devpod/pkg/driver"
 	provider2 "github.com/loft-sh/devpod/pkg/provider"
@@ -17,6 +18,10 @@ import (
 
 // NewKubernetesDriver constructs a struct capable of provisioning a workspace and it's resources using kubernetes
 func NewKubernetesDriver(workspaceInfo *provider2.AgentWorkspaceInfo, log log.Logger) (driver.ReprovisioningDriver, error) {
+	return newKubernetesDriver(workspaceInfo, log)
+}
+
+func newKubernetesDriver(workspaceInfo *provider2.AgentWorkspaceInfo, log log.Logger) (*KubernetesDriver, error) {
 	options := workspaceInfo.Agent.Kubernetes
 	if options.KubernetesConfig != "" {
 		log.Debugf("Use Kubernetes Config '%s'", options.KubernetesConfig)
@@ -169,16 +174,39 @@ func (k *KubernetesDriver) CommandDevContainer(ctx context.Context, workspaceId,
 	})
 }
 
-func (k *KubernetesDriver) GetDevContainerLogs(ctx context.Context, workspaceID string, stdout io.Writer, stderr io.Writer) error {
+func (k *KubernetesDriver) GetDevContainerLogs(ctx context.Context, workspaceID string, options *driver.LogsOptions, stdout io.Writer, stderr io.Writer) error {
 	workspaceID = getID(workspaceID)
+	if options == nil {
+		// the logs of the pod were always followed
+		options = &driver.LogsOptions{Follow: true, Tail: -1}
+	}
+
+	logOptions := &corev1.PodLogOptions{
+		Container:  "devpod",
+		Follow:     options.Follow,
+		Timestamps: options.Timestamps,
+	}
+	if options.Since != "" {
+		since, err := driver.ParseLogsSince(options.Since, time.Now())
+		if err != nil {
+			return err
+		}
+
+		logOptions.SinceTime = &metav1.Time{Time: since}
+	}
+	if options.Tail >= 0 {
+		tail := int64(options.Tail)
+		logOptions.TailLines = &tail
+	}
 
-	logs, err := k.client.Logs(ctx, k.namespace, workspaceID, "devpod", true)
+	logs, err := k.client.LogsWithOptions(ctx, k.namespace, workspaceID, logOptions)
 	if err != nil {
 		return perrors.Wrap(err, "get logs")
 	}
+	defer logs.Close()
 
 	_, err = io.Copy(stdout, logs)
-	if err != nil {
+	if err != nil && ctx.Err() == nil {
 		return perrors.Wrap(err, "copy logs")
 	}
 
@@ -0,0 +1,107 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/loft-sh/devpod/pkg/devcontainer/config"
+	"github.com/loft-sh/log"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// getExtraVolumes translates the kubernetes mounts of the devcontainer and the extra mounts option into pod volumes.
+// Extra mounts are separated by semicolons or new lines and use the same format as devcontainer.json mounts,
+// e.g. type=k8s-pvc,source=maven-cache,target=/root/.m2,readonly
+func getExtraVolumes(mounts []*config.Mount, rawExtraMounts string, log log.Logger) ([]corev1.Volume, []corev1.VolumeMount, error) {
+	kubernetesMounts := []*config.Mount{}
+	for _, mount := range mounts {
+		if mount.IsKubernetesMount() {
+			kubernetesMounts = append(kubernetesMounts, mount)
+		}
+	}
+	for _, rawMount := range strings.FieldsFunc(rawExtraMounts, func(r rune) bool { return r == ';' || r == '\n' }) {
+		rawMount = strings.TrimSpace(rawMount)
+		if rawMount == "" {
+			continue
+		}
+
+		mount := config.ParseMount(rawMount)
+		if !mount.IsKubernetesMount() {
+			log.Warnf("Unsupported mount type '%s' in extra mount '%s', will skip", mount.Type, rawMount)
+			continue
+		}
+		kubernetesMounts = append(kubernetesMounts, &mount)
+	}
+
+	volumes := []corev1.Volume{}
+	volumeMounts := []corev1.VolumeMount{}
+	for idx, mount := range kubernetesMounts {
+		volume, volumeMount, err := getMountVolume(fmt.Sprintf("devpod-mount-%d", idx), mount)
+		if err != nil {
+			return nil, nil, fmt.Errorf("mount '%s': %w", mount.String(), err)
+		}
+
+		volumes = append(volumes, volume)
+		volumeMounts = append(volumeMounts, volumeMount)
+	}
+
+	return volumes, volumeMounts, nil
+}
+
+func getMountVolume(name string, mount *config.Mount) (corev1.Volume, corev1.VolumeMount, error) {
+	if mount.Target == "" {
+		return corev1.Volume{}, corev1.VolumeMount{}, fmt.Errorf("target is required")
+	} else if mount.Source == "" && mount.Type != config.MountTypeEmptyDir {
+		return corev1.Volume{}, corev1.VolumeMount{}, fmt.Errorf("source is required")
+	}
+
+	volume := corev1.Volume{Name: name}
+	volumeMount := corev1.VolumeMount{Name: name, MountPath: mount.Target}
+	size := ""
+	medium := corev1.StorageMediumDefault
+	for _, option := range mount.Other {
+		key, value, _ := strings.Cut(option, "=")
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "readonly", "ro":
+			volumeMount.ReadOnly = value == "" || value == "true" || value == "1"
+		case "subpath":
+			volumeMount.SubPath = value
+		case "size":
+			size = value
+		case "medium":
+			if strings.EqualFold(value, string(corev1.StorageMediumMemory)) {
+				medium = corev1.StorageMediumMemory
+			} else if value != "" {
+				return corev1.Volume{}, corev1.VolumeMount{}, fmt.Errorf("unsupported medium '%s', expected Memory", value)
+			}
+		}
+	}
+
+	switch mount.Type {
+	case config.MountTypeKubernetesSecret:
+		volume.Secret = &corev1.SecretVolumeSource{SecretName: mount.Source}
+	case config.MountTypeKubernetesConfigMap:
+		volume.ConfigMap = &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: mount.Source},
+		}
+	case config.MountTypeKubernetesPVC:
+		volume.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{
+			ClaimName: mount.Source,
+			ReadOnly:  volumeMount.ReadOnly,
+		}
+	case config.MountTypeEmptyDir:
+		volume.EmptyDir = &corev1.EmptyDirVolumeSource{Medium: medium}
+		if size != "" {
+			quantity, err := resource.ParseQuantity(size)
+			if err != nil {
+				return corev1.Volume{}, corev1.VolumeMount{}, fmt.Errorf("parse size: %w", err)
+			}
+			volume.EmptyDir.SizeLimit = &quantity
+		}
+	default:
+		return corev1.Volume{}, corev1.VolumeMount{}, fmt.Errorf("unsupported mount type '%s'", mount.Type)
+	}
+
+	return volume, volumeMount, nil
+}
@@ -0,0 +1,36 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/loft-sh/devpod/pkg/devcontainer/config"
+	"github.com/loft-sh/log"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestGetExtraVolumes(t *testing.T) {
+	mounts := []*config.Mount{
+		{Type: "volume", Source: "cache", Target: "/cache"},
+		{Type: config.MountTypeKubernetesSecret, Source: "npmrc", Target: "/home/node/.npmrc", Other: []string{"subPath=.npmrc"}},
+		{Type: config.MountTypeEmptyDir, Target: "/tmp/scratch", Other: []string{"size=1Gi", "medium=memory"}},
+	}
+
+	volumes, volumeMounts, err := getExtraVolumes(mounts, "type=k8s-pvc,source=maven-cache,target=/root/.m2,readonly; type=bind,source=/tmp,target=/tmp", log.Discard)
+	assert.NilError(t, err)
+	sizeLimit := resource.MustParse("1Gi")
+	assert.DeepEqual(t, volumes, []corev1.Volume{
+		{Name: "devpod-mount-0", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "npmrc"}}},
+		{Name: "devpod-mount-1", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory, SizeLimit: &sizeLimit}}},
+		{Name: "devpod-mount-2", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "maven-cache", ReadOnly: true}}},
+	})
+	assert.DeepEqual(t, volumeMounts, []corev1.VolumeMount{
+		{Name: "devpod-mount-0", MountPath: "/home/node/.npmrc", SubPath: ".npmrc"},
+		{Name: "devpod-mount-1", MountPath: "/tmp/scratch"},
+		{Name: "devpod-mount-2", MountPath: "/root/.m2", ReadOnly: true},
+	})
+
+	_, _, err = getExtraVolumes([]*config.Mount{{Type: config.MountTypeKubernetesConfigMap, Target: "/config"}}, "", log.Discard)
+	assert.ErrorContains(t, err, "source is required")
+}
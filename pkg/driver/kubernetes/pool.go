@@ -0,0 +1,383 @@
+package kubernetes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/loft-sh/devpod/pkg/driver"
+	"github.com/loft-sh/devpod/pkg/random"
+	perrors "github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	DevPodPoolLabel        = "devpod.sh/pool"
+	DevPodPoolClaimedLabel = "devpod.sh/pool-claimed"
+)
+
+// poolPodScript keeps the pool pod idle until it is claimed
+const poolPodScript = `trap "exit 0" 15
+while sleep 3600 & wait $!; do :; done`
+
+// poolSize returns the number of idle pods to keep per pool
+func (k *KubernetesDriver) poolSize() int {
+	if k.options.PoolSize == "" {
+		return 0
+	}
+
+	size, err := strconv.Atoi(k.options.PoolSize)
+	if err != nil || size < 0 {
+		k.Log.Warnf("Invalid pool size '%s', expected a positive number", k.options.PoolSize)
+		return 0
+	}
+
+	return size
+}
+
+// getPoolKey identifies the pool of a devcontainer, only workspaces with the same image, pod template,
+// resources and volume settings can take over each others pool pods
+func (k *KubernetesDriver) getPoolKey(options *driver.RunOptions) (string, error) {
+	raw, err := json.Marshal([]interface{}{
+		options.Image,
+		options.HostRequirements,
+		k.options.PodManifestTemplate,
+		k.options.Resources,
+		k.options.NodeSelector,
+		k.options.StorageClass,
+		k.options.DiskSize,
+		k.options.PvcAccessMode,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(raw)
+	return hex.EncodeToString(hash[:])[:16], nil
+}
+
+// claimPoolPod takes over the persistent volume of an idle pool pod and returns the node it ran on, so the devcontainer
+// can be scheduled where the image was already pulled
+func (k *KubernetesDriver) claimPoolPod(ctx context.Context, id, poolKey string, options *driver.RunOptions) (string, bool) {
+	pods, err := k.client.Client().CoreV1().Pods(k.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: DevPodPoolLabel + "=" + poolKey + ",!" + DevPodPoolClaimedLabel,
+	})
+	if err != nil {
+		k.Log.Warnf("Error listing pool pods: %v", err)
+		return "", false
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+
+		// the update fails with a conflict if another workspace claimed the pod first
+		pod.Labels[DevPodPoolClaimedLabel] = "true"
+		_, err = k.client.Client().CoreV1().Pods(k.namespace).Update(ctx, pod, metav1.UpdateOptions{})
+		if err != nil {
+			k.Log.Debugf("Error claiming pool pod '%s': %v", pod.Name, err)
+			continue
+		}
+
+		k.Log.Infof("Claim pool pod '%s'", pod.Name)
+		err = k.rebindPoolVolume(ctx, pod.Name, id, options)
+		if err != nil {
+			k.Log.Warnf("Error taking over the volume of pool pod '%s', creating a new one: %v", pod.Name, err)
+			k.deletePoolPod(ctx, pod.Name)
+			return "", false
+		}
+
+		return pod.Spec.NodeName, true
+	}
+
+	k.Log.Debugf("No idle pod found in pool '%s'", poolKey)
+	return "", false
+}
+
+// rebindPoolVolume deletes the pool pod and binds its already provisioned persistent volume to a new claim of the workspace
+func (k *KubernetesDriver) rebindPoolVolume(ctx context.Context, name, id string, options *driver.RunOptions) (err error) {
+	poolPvc, err := k.client.Client().CoreV1().PersistentVolumeClaims(k.namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return perrors.Wrap(err, "get pool persistent volume claim")
+	} else if poolPvc.Status.Phase != corev1.ClaimBound || poolPvc.Spec.VolumeName == "" {
+		return fmt.Errorf("persistent volume claim '%s' isn't bound yet", name)
+	}
+
+	// keep the volume when the pool claim is deleted
+	volumeName := poolPvc.Spec.VolumeName
+	reclaimPolicy := corev1.PersistentVolumeReclaimDelete
+	err = k.updatePersistentVolume(ctx, volumeName, func(pv *corev1.PersistentVolume) {
+		reclaimPolicy = pv.Spec.PersistentVolumeReclaimPolicy
+		pv.Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimRetain
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			// a released volume with the original policy gets cleaned up by kubernetes
+			_ = k.updatePersistentVolume(context.Background(), volumeName, func(pv *corev1.PersistentVolume) {
+				pv.Spec.PersistentVolumeReclaimPolicy = reclaimPolicy
+			})
+		}
+	}()
+
+	// release the volume
+	k.deletePoolPod(ctx, name)
+	err = wait.PollUntilContextTimeout(ctx, time.Second, time.Minute*2, true, func(ctx context.Context) (bool, error) {
+		_, err := k.client.Client().CoreV1().PersistentVolumeClaims(k.namespace).Get(ctx, name, metav1.GetOptions{})
+		return kerrors.IsNotFound(err), nil
+	})
+	if err != nil {
+		return fmt.Errorf("timeout waiting for persistent volume claim '%s' to be deleted", name)
+	}
+
+	// create the workspace claim and point the volume to it
+	pvc, err := k.buildPersistentVolumeClaim(id, options)
+	if err != nil {
+		return err
+	}
+	pvc.Spec.VolumeName = volumeName
+	k.Log.Infof("Create Persistent Volume Claim '%s' from pool volume '%s'", id, volumeName)
+	pvc, err = k.client.Client().CoreV1().PersistentVolumeClaims(k.namespace).Create(ctx, pvc, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("create pvc: %w", err)
+	}
+
+	err = k.updatePersistentVolume(ctx, volumeName, func(pv *corev1.PersistentVolume) {
+		pv.Spec.PersistentVolumeReclaimPolicy = reclaimPolicy
+		pv.Spec.ClaimRef = &corev1.ObjectReference{
+			Kind:       "PersistentVolumeClaim",
+			APIVersion: "v1",
+			Namespace:  k.namespace,
+			Name:       pvc.Name,
+			UID:        pvc.UID,
+		}
+	})
+	if err != nil {
+		_ = k.client.Client().CoreV1().PersistentVolumeClaims(k.namespace).Delete(context.Background(), pvc.Name, metav1.DeleteOptions{})
+		return err
+	}
+
+	return nil
+}
+
+func (k *KubernetesDriver) updatePersistentVolume(ctx context.Context, name string, update func(pv *corev1.PersistentVolume)) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		pv, err := k.client.Client().CoreV1().PersistentVolumes().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		update(pv)
+		_, err = k.client.Client().CoreV1().PersistentVolumes().Update(ctx, pv, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return perrors.Wrapf(err, "update persistent volume '%s'", name)
+	}
+
+	return nil
+}
+
+// replenishPool creates or deletes pool pods until the pool has the configured size. It doesn't wait for the pods
+// to come up, pulling the image and provisioning the volume happens in the background.
+func (k *KubernetesDriver) replenishPool(ctx context.Context, poolKey string, options *driver.RunOptions) error {
+	pods, err := k.client.Client().CoreV1().Pods(k.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: DevPodPoolLabel + "=" + poolKey + ",!" + DevPodPoolClaimedLabel,
+	})
+	if err != nil {
+		return perrors.Wrap(err, "list pool pods")
+	}
+
+	idle := []string{}
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp != nil {
+			continue
+		} else if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			k.Log.Debugf("Delete pool pod '%s', because it is %s", pod.Name, pod.Status.Phase)
+			k.deletePoolPod(ctx, pod.Name)
+			continue
+		}
+
+		idle = append(idle, pod.Name)
+	}
+
+	size := k.poolSize()
+	for len(idle) > size {
+		k.deletePoolPod(ctx, idle[0])
+		idle = idle[1:]
+	}
+	for i := len(idle); i < size; i++ {
+		err = k.createPoolPod(ctx, "devpod-pool-"+poolKey[:8]+"-"+random.String(5), poolKey, options)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removePools deletes the idle pods of all pools and their pull secrets, e.g. because the pool size was lowered to 0
+func (k *KubernetesDriver) removePools(ctx context.Context) error {
+	pods, err := k.client.Client().CoreV1().Pods(k.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: DevPodPoolLabel + ",!" + DevPodPoolClaimedLabel,
+	})
+	if err != nil {
+		return perrors.Wrap(err, "list pool pods")
+	}
+
+	poolKeys := map[string]bool{}
+	for _, pod := range pods.Items {
+		k.Log.Debugf("Delete pool pod '%s', because the pool is disabled", pod.Name)
+		k.deletePoolPod(ctx, pod.Name)
+		poolKeys[pod.Labels[DevPodPoolLabel]] = true
+	}
+	for poolKey := range poolKeys {
+		k.deletePoolPullSecret(ctx, poolKey)
+	}
+
+	return nil
+}
+
+// deletePoolPullSecret deletes the pull secret of the pool pods, it isn't used by the workspaces themselves
+func (k *KubernetesDriver) deletePoolPullSecret(ctx context.Context, poolKey string) {
+	err := k.DeleteSecret(ctx, getPullSecretsName("pool-"+poolKey))
+	if err != nil {
+		k.Log.Debugf("Error deleting pull secret of pool '%s': %v", poolKey, err)
+	}
+}
+
+func (k *KubernetesDriver) createPoolPod(ctx context.Context, name, poolKey string, options *driver.RunOptions) error {
+	pvc, err := k.buildPersistentVolumeClaim(name, options)
+	if err != nil {
+		return err
+	}
+	delete(pvc.Labels, DevPodWorkspaceUIDLabel)
+	delete(pvc.Annotations, DevPodInfoAnnotation)
+	pvc.Labels[DevPodPoolLabel] = poolKey
+
+	pod, err := k.buildPoolPod(ctx, name, poolKey, options)
+	if err != nil {
+		return err
+	}
+
+	k.Log.Debugf("Create pool pod '%s'", name)
+	_, err = k.client.Client().CoreV1().PersistentVolumeClaims(k.namespace).Create(ctx, pvc, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("create pool pvc: %w", err)
+	}
+	_, err = k.client.Client().CoreV1().Pods(k.namespace).Create(ctx, pod, metav1.CreateOptions{})
+	if err != nil {
+		_ = k.client.Client().CoreV1().PersistentVolumeClaims(k.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+		return fmt.Errorf("create pool pod: %w", err)
+	}
+
+	return nil
+}
+
+// buildPoolPod builds an idle pod that pulls the image of the devcontainer and mounts a persistent volume claim,
+// so the volume gets provisioned
+func (k *KubernetesDriver) buildPoolPod(ctx context.Context, name, poolKey string, options *driver.RunOptions) (*corev1.Pod, error) {
+	var err error
+	pod := &corev1.Pod{}
+	if len(k.options.PodManifestTemplate) > 0 {
+		pod, err = getPodTemplate(k.options.PodManifestTemplate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	labels, err := getLabels(pod, k.options.Labels)
+	if err != nil {
+		return nil, err
+	}
+	labels[DevPodPoolLabel] = poolKey
+	nodeSelector, err := getNodeSelector(pod, k.options.NodeSelector)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := k.getResources(pod, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// ensure pull secrets
+	if k.options.KubernetesPullSecretsEnabled == "true" {
+		pullSecretName := getPullSecretsName("pool-" + poolKey)
+		created, err := k.EnsurePullSecret(ctx, pullSecretName, options.Image)
+		if err != nil {
+			return nil, err
+		} else if created {
+			pod.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: pullSecretName}}
+		}
+	}
+
+	// only keep the devcontainer of the template, sidecars would only take up resources
+	volumeMounts := []corev1.VolumeMount{{Name: "devpod", MountPath: "/devpod-pool"}}
+	containers := getContainers(pod, options.Image, "sh", []string{"-c", poolPodScript}, nil, volumeMounts, nil, resources, options.Privileged, k.options.StrictSecurity, "")
+
+	pod.ObjectMeta = metav1.ObjectMeta{
+		Name:        name,
+		Labels:      labels,
+		Annotations: map[string]string{ClusterAutoscalerSaveToEvictAnnotation: "true"},
+	}
+	pod.Spec.NodeSelector = nodeSelector
+	pod.Spec.InitContainers = nil
+	pod.Spec.Containers = containers[len(containers)-1:]
+	pod.Spec.Volumes = []corev1.Volume{{
+		Name: "devpod",
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: name},
+		},
+	}}
+	pod.Spec.RestartPolicy = corev1.RestartPolicyNever
+
+	return pod, nil
+}
+
+func (k *KubernetesDriver) deletePoolPod(ctx context.Context, name string) {
+	err := k.client.Client().CoreV1().Pods(k.namespace).Delete(ctx, name, metav1.DeleteOptions{
+		GracePeriodSeconds: &[]int64{1}[0],
+	})
+	if err != nil && !kerrors.IsNotFound(err) {
+		k.Log.Debugf("Error deleting pool pod '%s': %v", name, err)
+	}
+
+	err = k.client.Client().CoreV1().PersistentVolumeClaims(k.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		k.Log.Debugf("Error deleting pool persistent volume claim '%s': %v", name, err)
+	}
+}
+
+// addPreferredNode makes the scheduler prefer the given node, e.g. because the image was already pulled there
+func addPreferredNode(pod *corev1.Pod, nodeName string) {
+	if pod.Spec.Affinity == nil {
+		pod.Spec.Affinity = &corev1.Affinity{}
+	}
+	if pod.Spec.Affinity.NodeAffinity == nil {
+		pod.Spec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+
+	nodeAffinity := pod.Spec.Affinity.NodeAffinity
+	nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution, corev1.PreferredSchedulingTerm{
+		Weight: 100,
+		Preference: corev1.NodeSelectorTerm{
+			MatchFields: []corev1.NodeSelectorRequirement{{
+				Key:      "metadata.name",
+				Operator: corev1.NodeSelectorOpIn,
+				Values:   []string{nodeName},
+			}},
+		},
+	})
+}
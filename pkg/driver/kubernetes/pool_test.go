@@ -0,0 +1,61 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/loft-sh/devpod/pkg/driver"
+	provider2 "github.com/loft-sh/devpod/pkg/provider"
+	"github.com/loft-sh/log"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGetPoolKey(t *testing.T) {
+	k := &KubernetesDriver{options: &provider2.ProviderKubernetesDriverConfig{DiskSize: "10Gi"}, Log: log.Discard}
+
+	key, err := k.getPoolKey(&driver.RunOptions{Image: "golang:1.23", UID: "a"})
+	assert.NilError(t, err)
+	assert.Equal(t, len(key), 16)
+
+	// the workspace doesn't matter, only what the pod and volume look like
+	sameKey, err := k.getPoolKey(&driver.RunOptions{Image: "golang:1.23", UID: "b"})
+	assert.NilError(t, err)
+	assert.Equal(t, key, sameKey)
+
+	otherKey, err := k.getPoolKey(&driver.RunOptions{Image: "node:22", UID: "a"})
+	assert.NilError(t, err)
+	assert.Assert(t, key != otherKey)
+
+	k.options.DiskSize = "20Gi"
+	otherKey, err = k.getPoolKey(&driver.RunOptions{Image: "golang:1.23", UID: "a"})
+	assert.NilError(t, err)
+	assert.Assert(t, key != otherKey)
+}
+
+func TestBuildPoolPod(t *testing.T) {
+	k := &KubernetesDriver{options: &provider2.ProviderKubernetesDriverConfig{
+		Labels:              "team=platform",
+		PodManifestTemplate: "spec:\n  tolerations:\n  - key: devpod\n    operator: Exists\n  containers:\n  - name: devpod\n  - name: sidecar\n    image: busybox\n",
+	}, Log: log.Discard}
+
+	pod, err := k.buildPoolPod(context.Background(), "devpod-pool-abc", "abc", &driver.RunOptions{Image: "golang:1.23"})
+	assert.NilError(t, err)
+	assert.Equal(t, pod.Name, "devpod-pool-abc")
+	assert.Equal(t, pod.Labels[DevPodPoolLabel], "abc")
+	assert.Equal(t, pod.Labels["team"], "platform")
+	assert.Equal(t, len(pod.Spec.Tolerations), 1)
+	assert.Equal(t, len(pod.Spec.Containers), 1)
+	assert.Equal(t, pod.Spec.Containers[0].Name, DevContainerName)
+	assert.Equal(t, pod.Spec.Containers[0].Image, "golang:1.23")
+	assert.Equal(t, pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName, "devpod-pool-abc")
+}
+
+func TestAddPreferredNode(t *testing.T) {
+	pod := &corev1.Pod{}
+	addPreferredNode(pod, "node-1")
+
+	terms := pod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+	assert.Equal(t, len(terms), 1)
+	assert.DeepEqual(t, terms[0].Preference.MatchFields[0].Values, []string{"node-1"})
+}
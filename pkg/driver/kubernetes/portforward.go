@@ -0,0 +1,250 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	provider2 "github.com/loft-sh/devpod/pkg/provider"
+	"github.com/loft-sh/log"
+	perrors "github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// PortForwardProtocolV1Name is the subprotocol used for port forwarding
+const PortForwardProtocolV1Name = "portforward.k8s.io"
+
+// NativeConnectEnabled returns if the cli should talk to the devcontainer pod through the kubernetes api directly
+// instead of going through the agent and ssh
+func NativeConnectEnabled(agentConfig *provider2.ProviderAgentConfig) bool {
+	return agentConfig.Driver == provider2.KubernetesDriver && agentConfig.Kubernetes.NativeConnect == "true"
+}
+
+// PortForwarder opens connections to the ports of the devcontainer pod through the port-forward api of kubernetes
+type PortForwarder struct {
+	m sync.Mutex
+
+	client    *Client
+	namespace string
+	pod       string
+
+	conn      httpstream.Connection
+	requestID int
+	log       log.Logger
+}
+
+// NewPortForwarder connects to the pod of the given workspace with the kube config of the workspace info
+func NewPortForwarder(ctx context.Context, workspaceInfo *provider2.AgentWorkspaceInfo, workspaceId string, log log.Logger) (*PortForwarder, error) {
+	k, err := newKubernetesDriver(workspaceInfo, log)
+	if err != nil {
+		return nil, err
+	}
+
+	workspaceId = getID(workspaceId)
+	pod, err := k.getPod(ctx, workspaceId)
+	if err != nil {
+		return nil, err
+	} else if pod == nil || pod.Status.Phase != corev1.PodRunning {
+		return nil, fmt.Errorf("pod '%s' is not running", workspaceId)
+	}
+
+	portForwarder := &PortForwarder{
+		client:    k.client,
+		namespace: k.namespace,
+		pod:       workspaceId,
+		log:       log,
+	}
+
+	// connect right away to find out if port forwarding is allowed
+	portForwarder.m.Lock()
+	defer portForwarder.m.Unlock()
+	_, err = portForwarder.connection()
+	if err != nil {
+		return nil, err
+	}
+
+	return portForwarder, nil
+}
+
+// Dial opens a connection to a tcp port of the pod, only loopback addresses are supported
+func (p *PortForwarder) Dial(network, addr string) (net.Conn, error) {
+	if !CanPortForward(network, addr) {
+		return nil, fmt.Errorf("can't forward %s/%s through kubernetes, only tcp ports on localhost are supported", network, addr)
+	}
+	_, port, _ := net.SplitHostPort(addr)
+	portNumber, err := strconv.Atoi(port)
+	if err != nil {
+		return nil, fmt.Errorf("parse port %s: %w", port, err)
+	}
+
+	p.m.Lock()
+	conn, err := p.connection()
+	p.requestID++
+	requestID := p.requestID
+	p.m.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	// the error stream is created first and only read from
+	headers := http.Header{}
+	headers.Set(corev1.StreamType, corev1.StreamTypeError)
+	headers.Set(corev1.PortHeader, strconv.Itoa(portNumber))
+	headers.Set(corev1.PortForwardRequestIDHeader, strconv.Itoa(requestID))
+	errorStream, err := conn.CreateStream(headers)
+	if err != nil {
+		return nil, perrors.Wrap(err, "create error stream")
+	}
+	_ = errorStream.Close()
+
+	headers.Set(corev1.StreamType, corev1.StreamTypeData)
+	dataStream, err := conn.CreateStream(headers)
+	if err != nil {
+		conn.RemoveStreams(errorStream)
+		return nil, perrors.Wrap(err, "create data stream")
+	}
+
+	streamConn := &streamConn{
+		Stream:      dataStream,
+		conn:        conn,
+		errorStream: errorStream,
+		remoteAddr:  &podAddr{pod: p.pod, port: portNumber},
+	}
+	go streamConn.readErrors(p.log)
+	return streamConn, nil
+}
+
+// Close closes the connection to the kubernetes api
+func (p *PortForwarder) Close() error {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	if p.conn == nil {
+		return nil
+	}
+
+	err := p.conn.Close()
+	p.conn = nil
+	return err
+}
+
+// connection returns the current streaming connection and reconnects if it was closed
+func (p *PortForwarder) connection() (httpstream.Connection, error) {
+	if p.conn != nil {
+		select {
+		case <-p.conn.CloseChan():
+			p.log.Debugf("Port forwarding connection to pod '%s' was closed, reconnecting", p.pod)
+		default:
+			return p.conn, nil
+		}
+	}
+
+	transport, upgrader, err := spdy.RoundTripperFor(p.client.Config())
+	if err != nil {
+		return nil, err
+	}
+
+	req := p.client.Client().CoreV1().RESTClient().
+		Post().
+		Resource("pods").
+		Namespace(p.namespace).
+		Name(p.pod).
+		SubResource("portforward")
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+	conn, protocol, err := dialer.Dial(PortForwardProtocolV1Name)
+	if err != nil {
+		return nil, perrors.Wrap(err, "upgrade connection")
+	} else if protocol != PortForwardProtocolV1Name {
+		_ = conn.Close()
+		return nil, fmt.Errorf("unable to negotiate protocol: client supports %q, server returned %q", PortForwardProtocolV1Name, protocol)
+	}
+
+	p.conn = conn
+	return conn, nil
+}
+
+// streamConn is a net.Conn on top of the data stream of a forwarded port
+type streamConn struct {
+	httpstream.Stream
+
+	conn        httpstream.Connection
+	errorStream httpstream.Stream
+	remoteAddr  net.Addr
+
+	closeOnce sync.Once
+}
+
+// readErrors closes the connection if kubernetes reports an error, e.g. because nothing listens on the port
+func (c *streamConn) readErrors(log log.Logger) {
+	message, err := io.ReadAll(c.errorStream)
+	if err != nil {
+		log.Debugf("Error reading port forward error stream: %v", err)
+	} else if len(message) > 0 {
+		log.Debugf("Error forwarding port %s: %s", c.remoteAddr.String(), strings.TrimSpace(string(message)))
+		_ = c.Close()
+	}
+}
+
+func (c *streamConn) Close() error {
+	c.closeOnce.Do(func() {
+		_ = c.Stream.Close()
+		c.conn.RemoveStreams(c.Stream, c.errorStream)
+	})
+
+	return nil
+}
+
+func (c *streamConn) LocalAddr() net.Addr {
+	return &podAddr{}
+}
+
+func (c *streamConn) RemoteAddr() net.Addr {
+	return c.remoteAddr
+}
+
+func (c *streamConn) SetDeadline(time.Time) error {
+	return nil
+}
+
+func (c *streamConn) SetReadDeadline(time.Time) error {
+	return nil
+}
+
+func (c *streamConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
+
+type podAddr struct {
+	pod  string
+	port int
+}
+
+func (a *podAddr) Network() string {
+	return "tcp"
+}
+
+func (a *podAddr) String() string {
+	return a.pod + ":" + strconv.Itoa(a.port)
+}
+
+// CanPortForward returns if the address can be reached through the port-forward api, which only connects to
+// tcp ports on the loopback interface of the pod
+func CanPortForward(network, addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil || network != "tcp" {
+		return false
+	} else if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && (ip.IsLoopback() || ip.IsUnspecified())
+}
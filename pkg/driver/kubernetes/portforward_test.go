@@ -0,0 +1,18 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestCanPortForward(t *testing.T) {
+	assert.Assert(t, CanPortForward("tcp", "localhost:8080"))
+	assert.Assert(t, CanPortForward("tcp", "127.0.0.1:8080"))
+	assert.Assert(t, CanPortForward("tcp", "0.0.0.0:8080"))
+	assert.Assert(t, CanPortForward("tcp", "[::1]:8080"))
+	assert.Assert(t, !CanPortForward("tcp", "10.0.0.5:8080"))
+	assert.Assert(t, !CanPortForward("tcp", "example.com:8080"))
+	assert.Assert(t, !CanPortForward("tcp", "8080"))
+	assert.Assert(t, !CanPortForward("unix", "/tmp/docker.sock"))
+}
@@ -46,6 +46,16 @@ func (k *KubernetesDriver) buildPersistentVolumeClaim(
 		return nil, errors.Wrapf(err, "parse persistent volume size '%s'", size)
 	}
 
+	// the workspace lives on the persistent volume, so it needs to hold the storage host requirement
+	if options.HostRequirements != nil {
+		storage, err := options.HostRequirements.GetStorage()
+		if err != nil {
+			return nil, errors.Wrap(err, "parse storage host requirement")
+		} else if storage > quantity.Value() {
+			quantity = *resource.NewQuantity(storage, resource.BinarySI)
+		}
+	}
+
 	var storageClassName *string
 	if k.options.StorageClass != "" {
 		storageClassName = &k.options.StorageClass
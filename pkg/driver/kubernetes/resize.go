@@ -0,0 +1,167 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/loft-sh/devpod/pkg/driver"
+	perrors "github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ResizeDevContainer changes the cpu and memory of the devcontainer. The new resources are stored with the persistent
+// volume claim and replace the resources option from then on. A running pod is resized in place if the cluster supports
+// it and recreated otherwise.
+func (k *KubernetesDriver) ResizeDevContainer(ctx context.Context, workspaceId string, options *driver.ResizeOptions) error {
+	workspaceId = getID(workspaceId)
+	pvc, containerInfo, err := k.getDevContainerPvc(ctx, workspaceId)
+	if err != nil {
+		return err
+	} else if containerInfo == nil {
+		return fmt.Errorf("persistent volume '%s' not found", workspaceId)
+	}
+	k.applyResizedResources(containerInfo)
+
+	pod, err := k.getPod(ctx, workspaceId)
+	if err != nil {
+		return err
+	}
+
+	// get the current resources of the devcontainer
+	resources := corev1.ResourceRequirements{}
+	if k.options.Resources != "" {
+		resources = parseResources(k.options.Resources, k.Log)
+	} else if pod != nil {
+		container, err := getContainer(pod.Spec.Containers, DevContainerName)
+		if err != nil {
+			return err
+		}
+		resources = *container.Resources.DeepCopy()
+	} else if containerInfo.Options != nil {
+		resources, err = getHostRequirementsResources(containerInfo.Options.HostRequirements)
+		if err != nil {
+			return err
+		}
+	}
+	resizeResources(&resources, options)
+
+	// store the resources with the workspace
+	containerInfo.Resources = formatResources(resources)
+	rawContainerInfo, err := json.Marshal(containerInfo)
+	if err != nil {
+		return err
+	}
+	pvc.Annotations[DevPodInfoAnnotation] = string(rawContainerInfo)
+	_, err = k.client.Client().CoreV1().PersistentVolumeClaims(k.namespace).Update(ctx, pvc, metav1.UpdateOptions{})
+	if err != nil {
+		return perrors.Wrap(err, "update persistent volume claim")
+	}
+	k.options.Resources = containerInfo.Resources
+	if pod == nil {
+		k.Log.Infof("Pod '%s' isn't running, the new resources are used on the next start", workspaceId)
+		return nil
+	}
+
+	err = k.resizePod(ctx, pod, resources)
+	if err == nil {
+		return nil
+	}
+
+	// recreate the pod, the workspace is kept on the persistent volume claim
+	k.Log.Infof("Pod '%s' can't be resized in place, recreating it: %v", workspaceId, err)
+	err = k.waitPodDeleted(ctx, workspaceId)
+	if err != nil {
+		return perrors.Wrap(err, "delete pod")
+	}
+
+	return k.runContainer(ctx, workspaceId, containerInfo.Options, false, "")
+}
+
+// applyResizedResources makes the resources of a previous resize take precedence over the resources option
+func (k *KubernetesDriver) applyResizedResources(containerInfo *DevContainerInfo) {
+	if containerInfo != nil && containerInfo.Resources != "" {
+		k.options.Resources = containerInfo.Resources
+	}
+}
+
+func (k *KubernetesDriver) resizePod(ctx context.Context, pod *corev1.Pod, resources corev1.ResourceRequirements) error {
+	pod = pod.DeepCopy()
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == DevContainerName {
+			pod.Spec.Containers[i].Resources = resources
+		}
+	}
+
+	// clusters before v1.32 allow resizing through the pod itself if the InPlacePodVerticalScaling feature is enabled
+	pods := k.client.Client().CoreV1().Pods(k.namespace)
+	_, err := pods.UpdateResize(ctx, pod.Name, pod, metav1.UpdateOptions{})
+	if kerrors.IsNotFound(err) || kerrors.IsMethodNotSupported(err) {
+		_, err = pods.Update(ctx, pod, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+
+	// remember the applied options, so the pod isn't recreated on the next start
+	lastAppliedConfigRaw, err := json.Marshal(k.options)
+	if err != nil {
+		return err
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{DevPodLastAppliedAnnotation: string(lastAppliedConfigRaw)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = pods.Patch(ctx, pod.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		k.Log.Warnf("Error updating annotations of pod '%s': %v", pod.Name, err)
+	}
+
+	k.Log.Infof("Resized pod '%s'", pod.Name)
+	return nil
+}
+
+// resizeResources sets the requests and limits of cpu and memory
+func resizeResources(resources *corev1.ResourceRequirements, options *driver.ResizeOptions) {
+	if resources.Requests == nil {
+		resources.Requests = corev1.ResourceList{}
+	}
+	if resources.Limits == nil {
+		resources.Limits = corev1.ResourceList{}
+	}
+
+	if options.CPUs > 0 {
+		cpus := *resource.NewMilliQuantity(int64(options.CPUs*1000), resource.DecimalSI)
+		resources.Requests[corev1.ResourceCPU] = cpus
+		resources.Limits[corev1.ResourceCPU] = cpus
+	}
+	if options.Memory > 0 {
+		memory := *resource.NewQuantity(options.Memory, resource.BinarySI)
+		resources.Requests[corev1.ResourceMemory] = memory
+		resources.Limits[corev1.ResourceMemory] = memory
+	}
+}
+
+// formatResources is the inverse of parseResources
+func formatResources(resources corev1.ResourceRequirements) string {
+	parts := []string{}
+	for name, quantity := range resources.Requests {
+		parts = append(parts, requestsPrefix+string(name)+"="+quantity.String())
+	}
+	for name, quantity := range resources.Limits {
+		parts = append(parts, limitsPrefix+string(name)+"="+quantity.String())
+	}
+	sort.Strings(parts)
+
+	return strings.Join(parts, ",")
+}
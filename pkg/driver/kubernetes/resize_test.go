@@ -0,0 +1,20 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/loft-sh/devpod/pkg/driver"
+	"github.com/loft-sh/log"
+	"gotest.tools/assert"
+)
+
+func TestResizeResources(t *testing.T) {
+	resources := parseResources("requests.cpu=500m,limits.cpu=1,requests.ephemeral-storage=10Gi", log.Discard)
+	resizeResources(&resources, &driver.ResizeOptions{Memory: 8 * 1024 * 1024 * 1024})
+	assert.Equal(t, formatResources(resources), "limits.cpu=1,limits.memory=8Gi,requests.cpu=500m,requests.ephemeral-storage=10Gi,requests.memory=8Gi")
+
+	resizeResources(&resources, &driver.ResizeOptions{CPUs: 2.5})
+	resized := formatResources(resources)
+	assert.Equal(t, resized, "limits.cpu=2500m,limits.memory=8Gi,requests.cpu=2500m,requests.ephemeral-storage=10Gi,requests.memory=8Gi")
+	assert.Equal(t, formatResources(parseResources(resized, log.Discard)), resized)
+}
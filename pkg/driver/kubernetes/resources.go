@@ -0,0 +1,162 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/loft-sh/devpod/pkg/devcontainer/config"
+	"github.com/loft-sh/devpod/pkg/driver"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// HostResources returns the largest free resources of the nodes the dev container could be scheduled on, capped by
+// the configured resource limits. Free resources are the allocatable resources minus the requests of the pods on the
+// node. Storage is the size of the persistent volume claim of the workspace.
+func (k *KubernetesDriver) HostResources(ctx context.Context, workspaceId string) (*driver.HostResources, error) {
+	nodeSelector, err := getNodeSelector(&corev1.Pod{}, k.options.NodeSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := k.client.Client().CoreV1().Nodes().List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(nodeSelector).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list nodes: %w", err)
+	}
+
+	// listing pods of all namespaces might not be allowed, so fall back to the allocatable resources
+	pods, err := k.client.Client().CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: "spec.nodeName!=,status.phase!=Succeeded,status.phase!=Failed",
+	})
+	if err != nil {
+		k.Log.Debugf("Error listing pods, using the allocatable resources of the nodes: %v", err)
+		pods = &corev1.PodList{}
+	}
+
+	resources := getFreeResources(nodes.Items, pods.Items)
+	if k.options.DiskSize != "" {
+		diskSize, err := resource.ParseQuantity(k.options.DiskSize)
+		if err != nil {
+			k.Log.Debugf("Error parsing disk size %s: %v", k.options.DiskSize, err)
+		} else {
+			resources.Storage = diskSize.Value()
+		}
+	}
+
+	if k.options.Resources != "" {
+		limits := parseResources(k.options.Resources, k.Log).Limits
+		if cpu, ok := limits[corev1.ResourceCPU]; ok {
+			resources.CPUs = minKnown(resources.CPUs, cpu.AsApproximateFloat64())
+		}
+		if memory, ok := limits[corev1.ResourceMemory]; ok {
+			resources.Memory = minKnown(resources.Memory, memory.Value())
+		}
+	}
+
+	return resources, nil
+}
+
+// getFreeResources returns the largest free cpus and memory of the schedulable nodes
+func getFreeResources(nodes []corev1.Node, pods []corev1.Pod) *driver.HostResources {
+	requested := map[string]corev1.ResourceList{}
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+
+		nodeRequests := requested[pod.Spec.NodeName]
+		if nodeRequests == nil {
+			nodeRequests = corev1.ResourceList{}
+			requested[pod.Spec.NodeName] = nodeRequests
+		}
+		for name, quantity := range getPodRequests(&pod) {
+			total := nodeRequests[name]
+			total.Add(quantity)
+			nodeRequests[name] = total
+		}
+	}
+
+	resources := &driver.HostResources{}
+	for _, node := range nodes {
+		if node.Spec.Unschedulable {
+			continue
+		}
+
+		nodeRequests := requested[node.Name]
+		cpu := node.Status.Allocatable.Cpu().DeepCopy()
+		cpu.Sub(*nodeRequests.Cpu())
+		memory := node.Status.Allocatable.Memory().DeepCopy()
+		memory.Sub(*nodeRequests.Memory())
+		resources.CPUs = max(resources.CPUs, cpu.AsApproximateFloat64())
+		resources.Memory = max(resources.Memory, memory.Value())
+	}
+
+	return resources
+}
+
+// getPodRequests returns the requests of a pod like the scheduler calculates them, init containers run one after
+// another before the other containers
+func getPodRequests(pod *corev1.Pod) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		for name, quantity := range container.Resources.Requests {
+			total := requests[name]
+			total.Add(quantity)
+			requests[name] = total
+		}
+	}
+	for _, container := range pod.Spec.InitContainers {
+		for name, quantity := range container.Resources.Requests {
+			if total, ok := requests[name]; !ok || quantity.Cmp(total) > 0 {
+				requests[name] = quantity.DeepCopy()
+			}
+		}
+	}
+	for name, quantity := range pod.Spec.Overhead {
+		total := requests[name]
+		total.Add(quantity)
+		requests[name] = total
+	}
+
+	return requests
+}
+
+// getHostRequirementsResources translates the cpu and memory host requirements into resource requests, storage
+// is provided by the persistent volume claim
+func getHostRequirementsResources(hostRequirements *config.HostRequirements) (corev1.ResourceRequirements, error) {
+	requests := corev1.ResourceList{}
+	if hostRequirements.IsEmpty() {
+		return corev1.ResourceRequirements{}, nil
+	}
+
+	if hostRequirements.CPUs > 0 {
+		requests[corev1.ResourceCPU] = *resource.NewQuantity(int64(hostRequirements.CPUs), resource.DecimalSI)
+	}
+
+	memory, err := hostRequirements.GetMemory()
+	if err != nil {
+		return corev1.ResourceRequirements{}, fmt.Errorf("parse memory host requirement: %w", err)
+	} else if memory > 0 {
+		requests[corev1.ResourceMemory] = *resource.NewQuantity(memory, resource.BinarySI)
+	}
+	if len(requests) == 0 {
+		return corev1.ResourceRequirements{}, nil
+	}
+
+	return corev1.ResourceRequirements{Requests: requests}, nil
+}
+
+// minKnown returns the smaller value, treating 0 as unknown
+func minKnown[T int64 | float64](a, b T) T {
+	if a == 0 {
+		return b
+	} else if b == 0 {
+		return a
+	}
+
+	return min(a, b)
+}
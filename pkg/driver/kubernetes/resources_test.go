@@ -0,0 +1,80 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/loft-sh/devpod/pkg/devcontainer/config"
+	"github.com/loft-sh/devpod/pkg/driver"
+	provider2 "github.com/loft-sh/devpod/pkg/provider"
+	"github.com/loft-sh/log"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetFreeResources(t *testing.T) {
+	node := func(name, cpu, memory string, unschedulable bool) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+			Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			}},
+		}
+	}
+	pod := func(nodeName string, phase corev1.PodPhase, cpu, memory string) corev1.Pod {
+		return corev1.Pod{
+			Spec: corev1.PodSpec{
+				NodeName: nodeName,
+				Containers: []corev1.Container{{Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse(memory),
+				}}}},
+				InitContainers: []corev1.Container{{Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("100m"),
+				}}}},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+
+	resources := getFreeResources([]corev1.Node{
+		node("full", "8", "32Gi", false),
+		node("half", "4", "16Gi", false),
+		node("cordoned", "16", "64Gi", true),
+	}, []corev1.Pod{
+		pod("full", corev1.PodRunning, "7", "30Gi"),
+		pod("half", corev1.PodRunning, "1", "4Gi"),
+		pod("half", corev1.PodRunning, "1", "4Gi"),
+		pod("half", corev1.PodSucceeded, "2", "8Gi"),
+	})
+	assert.Equal(t, resources.CPUs, float64(2))
+	assert.Equal(t, resources.Memory, int64(8*1024*1024*1024))
+	assert.Equal(t, resources.Storage, int64(0))
+}
+
+func TestGetHostRequirementsResources(t *testing.T) {
+	resources, err := getHostRequirementsResources(&config.HostRequirements{CPUs: 2, Memory: "4gb", Storage: "100gb"})
+	assert.NilError(t, err)
+	assert.Equal(t, resources.Requests.Cpu().String(), "2")
+	_, ok := resources.Requests[corev1.ResourceEphemeralStorage]
+	assert.Assert(t, !ok)
+
+	resources, err = getHostRequirementsResources(&config.HostRequirements{Storage: "100gb"})
+	assert.NilError(t, err)
+	assert.Equal(t, len(resources.Requests), 0)
+}
+
+func TestBuildPersistentVolumeClaimSize(t *testing.T) {
+	k := &KubernetesDriver{options: &provider2.ProviderKubernetesDriverConfig{DiskSize: "10Gi"}, Log: log.Discard}
+
+	pvc, err := k.buildPersistentVolumeClaim("workspace", &driver.RunOptions{HostRequirements: &config.HostRequirements{Storage: "5gb"}})
+	assert.NilError(t, err)
+	assert.Equal(t, pvc.Spec.Resources.Requests.Storage().String(), "10Gi")
+
+	pvc, err = k.buildPersistentVolumeClaim("workspace", &driver.RunOptions{HostRequirements: &config.HostRequirements{Storage: "32gb"}})
+	assert.NilError(t, err)
+	assert.Equal(t, pvc.Spec.Resources.Requests.Storage().String(), "32Gi")
+}
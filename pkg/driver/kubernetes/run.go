@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -38,6 +39,9 @@ var ExtraDevPodLabels = map[string]string{
 type DevContainerInfo struct {
 	WorkspaceID string
 	Options     *driver.RunOptions
+
+	// Resources are set by resizing the devcontainer and take precedence over the resources option
+	Resources string `json:",omitempty"`
 }
 
 func (k *KubernetesDriver) RunDevContainer(
@@ -58,6 +62,8 @@ func (k *KubernetesDriver) RunDevContainer(
 
 	// check if persistent volume claim already exists
 	initialize := false
+	poolKey := ""
+	preferredNode := ""
 	pvc, containerInfo, err := k.getDevContainerPvc(ctx, workspaceId)
 	if err != nil {
 		return err
@@ -66,10 +72,23 @@ func (k *KubernetesDriver) RunDevContainer(
 			return fmt.Errorf("no options provided and no persistent volume claim found for workspace '%s'", workspaceId)
 		}
 
+		// try to take over the volume of a pre-warmed pod
+		claimed := false
+		if k.poolSize() > 0 {
+			poolKey, err = k.getPoolKey(options)
+			if err != nil {
+				return err
+			}
+
+			preferredNode, claimed = k.claimPoolPod(ctx, workspaceId, poolKey, options)
+		}
+
 		// create persistent volume claim
-		err = k.createPersistentVolumeClaim(ctx, workspaceId, options)
-		if err != nil {
-			return err
+		if !claimed {
+			err = k.createPersistentVolumeClaim(ctx, workspaceId, options)
+			if err != nil {
+				return err
+			}
 		}
 
 		initialize = true
@@ -79,13 +98,27 @@ func (k *KubernetesDriver) RunDevContainer(
 	if options == nil && containerInfo != nil && containerInfo.Options != nil {
 		options = containerInfo.Options
 	}
+	k.applyResizedResources(containerInfo)
 
 	// create dev container
-	err = k.runContainer(ctx, workspaceId, options, initialize)
+	err = k.runContainer(ctx, workspaceId, options, initialize, preferredNode)
 	if err != nil {
 		return err
 	}
 
+	// refill the pool after the devcontainer is running, so the new pods don't compete with it for the node
+	if poolKey != "" {
+		err = k.replenishPool(ctx, poolKey, options)
+		if err != nil {
+			k.Log.Warnf("Error replenishing workspace pool: %v", err)
+		}
+	} else if k.poolSize() == 0 {
+		err = k.removePools(ctx)
+		if err != nil {
+			k.Log.Debugf("Error removing workspace pools: %v", err)
+		}
+	}
+
 	return nil
 }
 
@@ -94,12 +127,14 @@ func (k *KubernetesDriver) runContainer(
 	id string,
 	options *driver.RunOptions,
 	initialize bool,
+	preferredNode string,
 ) (err error) {
 	// get workspace mount
 	mount := options.WorkspaceMount
 	if mount.Target == "" {
 		return fmt.Errorf("workspace mount target is empty")
 	}
+	workspaceTarget := mount.Target
 	if k.options.WorkspaceVolumeMount != "" {
 		// Ensure workspace volume mount option is parent or same dir as workspace mount
 		rel, err := filepath.Rel(k.options.WorkspaceVolumeMount, mount.Target)
@@ -136,6 +171,10 @@ func (k *KubernetesDriver) runContainer(
 	// loop over volume mounts
 	volumeMounts := []corev1.VolumeMount{getVolumeMount(0, mount)}
 	for idx, mount := range options.Mounts {
+		if mount.IsKubernetesMount() {
+			continue
+		}
+
 		volumeMount := getVolumeMount(idx+1, mount)
 		if mount.Type == "bind" || mount.Type == "volume" {
 			volumeMounts = append(volumeMounts, volumeMount)
@@ -144,6 +183,13 @@ func (k *KubernetesDriver) runContainer(
 		}
 	}
 
+	// secrets, config maps, existing persistent volume claims and empty dirs become volumes of the pod
+	extraVolumes, extraVolumeMounts, err := getExtraVolumes(options.Mounts, k.options.ExtraMounts, k.Log)
+	if err != nil {
+		return err
+	}
+	volumeMounts = append(volumeMounts, extraVolumeMounts...)
+
 	// capabilities
 	var capabilities *corev1.Capabilities
 	if len(options.CapAdd) > 0 {
@@ -194,12 +240,9 @@ func (k *KubernetesDriver) runContainer(
 	}
 
 	// parse resources
-	resources := corev1.ResourceRequirements{}
-	if len(pod.Spec.Containers) > 0 {
-		resources = pod.Spec.Containers[0].Resources
-	}
-	if k.options.Resources != "" {
-		resources = parseResources(k.options.Resources, k.Log)
+	resources, err := k.getResources(pod, options)
+	if err != nil {
+		return err
 	}
 
 	// ensure daemon config secret
@@ -229,7 +272,18 @@ func (k *KubernetesDriver) runContainer(
 	pod.Spec.NodeSelector = nodeSelector
 	pod.Spec.InitContainers = initContainers
 	pod.Spec.Containers = getContainers(pod, options.Image, options.Entrypoint, options.Cmd, envVars, volumeMounts, capabilities, resources, options.Privileged, k.options.StrictSecurity, daemonConfigSecretName)
-	pod.Spec.Volumes = getVolumes(pod, id, daemonConfigSecretName)
+	pod.Spec.Volumes = append(getVolumes(pod, id, daemonConfigSecretName), extraVolumes...)
+	if len(options.Sidecars) > 0 {
+		// the workspace folder is a sub folder of the volume mount if a parent was mounted
+		workspaceSubPath := "devpod/0"
+		if rel, err := filepath.Rel(mount.Target, workspaceTarget); err == nil && rel != "." {
+			workspaceSubPath = path.Join(workspaceSubPath, filepath.ToSlash(rel))
+		}
+
+		sidecarContainers, sidecarVolumes := getSidecars(options.Sidecars, mount.Source, workspaceSubPath, k.options.StrictSecurity == "true", k.Log)
+		pod.Spec.Containers = append(pod.Spec.Containers, sidecarContainers...)
+		pod.Spec.Volumes = append(pod.Spec.Volumes, sidecarVolumes...)
+	}
 	// avoids a problem where attaching volumes with large repositories would cause an extremely long pod startup time
 	// because changing the ownership of all files takes longer than the kubelet expects it to
 	if pod.Spec.SecurityContext == nil {
@@ -240,6 +294,10 @@ func (k *KubernetesDriver) runContainer(
 	if k.options.KubernetesPullSecretsEnabled == "true" && pullSecretsCreated {
 		pod.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: getPullSecretsName(id)}}
 	}
+	// the image is already pulled on the node of a claimed pool pod
+	if preferredNode != "" {
+		addPreferredNode(pod, preferredNode)
+	}
 	pod.Spec.RestartPolicy = corev1.RestartPolicyNever
 	// try to get existing pod
 	existingPod, err := k.getPod(ctx, id)
@@ -316,6 +374,20 @@ func (k *KubernetesDriver) runPod(ctx context.Context, id string, pod *corev1.Po
 	return nil
 }
 
+func (k *KubernetesDriver) getResources(pod *corev1.Pod, options *driver.RunOptions) (corev1.ResourceRequirements, error) {
+	resources := corev1.ResourceRequirements{}
+	if len(pod.Spec.Containers) > 0 {
+		resources = pod.Spec.Containers[0].Resources
+	}
+	if k.options.Resources != "" {
+		return parseResources(k.options.Resources, k.Log), nil
+	} else if len(resources.Requests) == 0 && len(resources.Limits) == 0 {
+		return getHostRequirementsResources(options.HostRequirements)
+	}
+
+	return resources, nil
+}
+
 func getContainers(
 	pod *corev1.Pod,
 	imageName,
@@ -484,12 +556,14 @@ func (k *KubernetesDriver) StartDevContainer(ctx context.Context, workspaceId st
 	} else if containerInfo == nil {
 		return fmt.Errorf("persistent volume '%s' not found", workspaceId)
 	}
+	k.applyResizedResources(containerInfo)
 
 	return k.runContainer(
 		ctx,
 		workspaceId,
 		containerInfo.Options,
 		false,
+		"",
 	)
 }
 
@@ -506,18 +580,22 @@ func getDaemonSecretName(workspaceID string) string {
 }
 
 func optionsEqual(a, b *provider2.ProviderKubernetesDriverConfig) bool {
-	// copy a and b and the compare them without the context, config, namespace and podTimeout
+	// copy a and b and the compare them without the context, config, namespace, podTimeout, nativeConnect and poolSize
 	aCopy := *a
 	aCopy.KubernetesContext = ""
 	aCopy.KubernetesConfig = ""
 	aCopy.KubernetesNamespace = ""
 	aCopy.PodTimeout = ""
+	aCopy.NativeConnect = ""
+	aCopy.PoolSize = ""
 
 	bCopy := *b
 	bCopy.KubernetesContext = ""
 	bCopy.KubernetesConfig = ""
 	bCopy.KubernetesNamespace = ""
 	bCopy.PodTimeout = ""
+	bCopy.NativeConnect = ""
+	bCopy.PoolSize = ""
 	return aCopy == bCopy
 }
 
@@ -0,0 +1,193 @@
+package kubernetes
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/loft-sh/devpod/pkg/devcontainer/config"
+	"github.com/loft-sh/devpod/pkg/driver"
+	"github.com/loft-sh/log"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var invalidContainerNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// SupportsSidecars returns true as sidecars run as additional containers in the devcontainer pod
+func (k *KubernetesDriver) SupportsSidecars() bool {
+	return true
+}
+
+// getSidecars returns the containers and ephemeral volumes for the sidecars of the devcontainer. Volume mounts
+// share the workspace persistent volume claim with the devcontainer and bind mounts of the workspace folder mount
+// the workspace from workspaceSubPath.
+func getSidecars(sidecars []*driver.SidecarOptions, workspaceSource, workspaceSubPath string, strictSecurity bool, log log.Logger) ([]corev1.Container, []corev1.Volume) {
+	containers := []corev1.Container{}
+	volumes := []corev1.Volume{}
+	for _, sidecar := range sidecars {
+		name := getSidecarName(sidecar.Name)
+		container := corev1.Container{
+			Name:    name,
+			Image:   sidecar.Image,
+			Command: sidecar.Entrypoint,
+			Args:    sidecar.Cmd,
+			Env:     getSidecarEnv(sidecar.Env),
+		}
+
+		for idx, mount := range sidecar.Mounts {
+			volumeMount, ok := getSidecarVolumeMount(mount, workspaceSource, workspaceSubPath)
+			if !ok {
+				if mount.Type == "bind" {
+					log.Warnf("Bind mount %s of sidecar %s is outside of the workspace, using an empty directory instead", mount.Source, sidecar.Name)
+				}
+
+				// everything else is ephemeral
+				volume := corev1.Volume{
+					Name:         fmt.Sprintf("%s-%d", name, idx),
+					VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+				}
+				if mount.Type == "tmpfs" {
+					volume.EmptyDir.Medium = corev1.StorageMediumMemory
+				}
+				volumes = append(volumes, volume)
+				volumeMount = corev1.VolumeMount{Name: volume.Name, MountPath: mount.Target}
+			}
+
+			container.VolumeMounts = append(container.VolumeMounts, volumeMount)
+		}
+
+		if !strictSecurity {
+			container.SecurityContext = getSidecarSecurityContext(sidecar, log)
+		}
+
+		if sidecar.Healthcheck != nil && len(sidecar.Healthcheck.Command) > 0 {
+			container.ReadinessProbe = &corev1.Probe{
+				ProbeHandler: corev1.ProbeHandler{
+					Exec: &corev1.ExecAction{Command: sidecar.Healthcheck.Command},
+				},
+				PeriodSeconds:    durationSeconds(sidecar.Healthcheck.Interval),
+				TimeoutSeconds:   durationSeconds(sidecar.Healthcheck.Timeout),
+				FailureThreshold: int32(sidecar.Healthcheck.Retries),
+			}
+		}
+
+		containers = append(containers, container)
+	}
+
+	return containers, volumes
+}
+
+func getSidecarVolumeMount(mount *config.Mount, workspaceSource, workspaceSubPath string) (corev1.VolumeMount, bool) {
+	switch mount.Type {
+	case "volume":
+		if mount.Source == "" {
+			return corev1.VolumeMount{}, false
+		}
+
+		return getVolumeMount(0, mount), true
+	case "bind":
+		if workspaceSource == "" {
+			return corev1.VolumeMount{}, false
+		}
+
+		// a file or folder within the workspace
+		rel, err := filepath.Rel(workspaceSource, mount.Source)
+		if err == nil && !isOutside(rel) {
+			return corev1.VolumeMount{
+				Name:      "devpod",
+				MountPath: mount.Target,
+				SubPath:   path.Join(workspaceSubPath, filepath.ToSlash(rel)),
+			}, true
+		}
+
+		// a parent of the workspace, e.g. ..:/workspaces
+		rel, err = filepath.Rel(mount.Source, workspaceSource)
+		if err == nil && !isOutside(rel) {
+			return corev1.VolumeMount{
+				Name:      "devpod",
+				MountPath: path.Join(mount.Target, filepath.ToSlash(rel)),
+				SubPath:   workspaceSubPath,
+			}, true
+		}
+	}
+
+	return corev1.VolumeMount{}, false
+}
+
+func getSidecarSecurityContext(sidecar *driver.SidecarOptions, log log.Logger) *corev1.SecurityContext {
+	securityContext := &corev1.SecurityContext{
+		Privileged: sidecar.Privileged,
+	}
+	if len(sidecar.CapAdd) > 0 {
+		securityContext.Capabilities = &corev1.Capabilities{}
+		for _, capability := range sidecar.CapAdd {
+			securityContext.Capabilities.Add = append(securityContext.Capabilities.Add, corev1.Capability(capability))
+		}
+	}
+
+	// kubernetes only understands numeric user and group ids
+	if sidecar.User != "" {
+		user, group, _ := strings.Cut(sidecar.User, ":")
+		uid, err := strconv.ParseInt(user, 10, 64)
+		if err != nil {
+			log.Warnf("Ignoring user %s of sidecar %s, only numeric user ids are supported", sidecar.User, sidecar.Name)
+			return securityContext
+		}
+		securityContext.RunAsUser = &uid
+
+		if group != "" {
+			gid, err := strconv.ParseInt(group, 10, 64)
+			if err == nil {
+				securityContext.RunAsGroup = &gid
+			}
+		}
+	}
+
+	return securityContext
+}
+
+func getSidecarEnv(env map[string]string) []corev1.EnvVar {
+	envVars := []corev1.EnvVar{}
+	for k, v := range env {
+		envVars = append(envVars, corev1.EnvVar{Name: k, Value: v})
+	}
+	sort.Slice(envVars, func(i, j int) bool {
+		return envVars[i].Name < envVars[j].Name
+	})
+
+	return envVars
+}
+
+// getSidecarName converts a docker compose service name into a valid container name that doesn't clash with the devcontainer
+func getSidecarName(name string) string {
+	name = strings.Trim(invalidContainerNameChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if len(name) > 53 {
+		name = strings.TrimRight(name[:53], "-")
+	}
+	if name == "" {
+		name = "sidecar"
+	} else if name == DevContainerName || name == InitContainerName {
+		name = "sidecar-" + name
+	}
+
+	return name
+}
+
+func durationSeconds(duration time.Duration) int32 {
+	if duration <= 0 {
+		return 0
+	} else if duration < time.Second {
+		return 1
+	}
+
+	return int32(duration / time.Second)
+}
+
+func isOutside(rel string) bool {
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,61 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devpod/pkg/devcontainer/config"
+	"github.com/loft-sh/devpod/pkg/driver"
+	"github.com/loft-sh/log"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGetSidecars(t *testing.T) {
+	sidecars := []*driver.SidecarOptions{
+		{
+			Name:  "db_primary",
+			Image: "postgres:16",
+			User:  "999:999",
+			Env:   map[string]string{"POSTGRES_PASSWORD": "secret"},
+			Mounts: []*config.Mount{
+				{Type: "volume", Source: "pgdata", Target: "/var/lib/postgresql/data"},
+				{Type: "bind", Source: "/repo/init.sql", Target: "/docker-entrypoint-initdb.d/init.sql"},
+				{Type: "bind", Source: "/", Target: "/workspaces"},
+				{Type: "bind", Source: "/etc/hosts", Target: "/etc/hosts"},
+				{Type: "tmpfs", Target: "/tmp"},
+			},
+			Healthcheck: &driver.Healthcheck{
+				Command:  []string{"pg_isready"},
+				Interval: 5 * time.Second,
+				Retries:  10,
+			},
+		},
+	}
+
+	containers, volumes := getSidecars(sidecars, "/repo", "devpod/0", false, log.Discard)
+	assert.Equal(t, len(containers), 1)
+	container := containers[0]
+	assert.Equal(t, container.Name, "db-primary")
+	assert.Equal(t, *container.SecurityContext.RunAsUser, int64(999))
+	assert.Equal(t, *container.SecurityContext.RunAsGroup, int64(999))
+	assert.DeepEqual(t, container.Env, []corev1.EnvVar{{Name: "POSTGRES_PASSWORD", Value: "secret"}})
+	assert.DeepEqual(t, container.VolumeMounts, []corev1.VolumeMount{
+		{Name: "devpod", MountPath: "/var/lib/postgresql/data", SubPath: "devpod/pgdata"},
+		{Name: "devpod", MountPath: "/docker-entrypoint-initdb.d/init.sql", SubPath: "devpod/0/init.sql"},
+		{Name: "devpod", MountPath: "/workspaces/repo", SubPath: "devpod/0"},
+		{Name: "db-primary-3", MountPath: "/etc/hosts"},
+		{Name: "db-primary-4", MountPath: "/tmp"},
+	})
+	assert.Equal(t, container.ReadinessProbe.PeriodSeconds, int32(5))
+	assert.Equal(t, container.ReadinessProbe.FailureThreshold, int32(10))
+
+	assert.Equal(t, len(volumes), 2)
+	assert.Equal(t, volumes[1].EmptyDir.Medium, corev1.StorageMediumMemory)
+}
+
+func TestGetSidecarName(t *testing.T) {
+	assert.Equal(t, getSidecarName("Redis_Cache"), "redis-cache")
+	assert.Equal(t, getSidecarName(DevContainerName), "sidecar-devpod")
+	assert.Equal(t, getSidecarName("__"), "sidecar")
+}
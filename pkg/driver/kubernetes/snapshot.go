@@ -0,0 +1,213 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/loft-sh/devpod/pkg/driver"
+	perrors "github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const DevPodSnapshotLabel = "devpod.sh/snapshot"
+
+var volumeSnapshotResource = schema.GroupVersionResource{
+	Group:    "snapshot.storage.k8s.io",
+	Version:  "v1",
+	Resource: "volumesnapshots",
+}
+
+var _ driver.SnapshotDriver = (*KubernetesDriver)(nil)
+
+// CreateSnapshot takes a VolumeSnapshot of the persistent volume claim of the workspace
+func (k *KubernetesDriver) CreateSnapshot(ctx context.Context, workspaceID string, options *driver.SnapshotOptions) (*driver.Snapshot, error) {
+	workspaceID = getID(workspaceID)
+	pvc, _, err := k.getDevContainerPvc(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	} else if pvc == nil {
+		return nil, fmt.Errorf("persistent volume claim for workspace %s not found", workspaceID)
+	}
+
+	snapshots, err := k.snapshotClient()
+	if err != nil {
+		return nil, err
+	}
+
+	volumeSnapshot := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": volumeSnapshotResource.GroupVersion().String(),
+		"kind":       "VolumeSnapshot",
+		"metadata": map[string]interface{}{
+			"name": getSnapshotName(workspaceID, options.Name),
+			"labels": map[string]interface{}{
+				DevPodCreatedLabel:   "true",
+				DevPodWorkspaceLabel: workspaceID,
+				DevPodSnapshotLabel:  options.Name,
+			},
+			"annotations": map[string]interface{}{
+				DevPodInfoAnnotation: pvc.Annotations[DevPodInfoAnnotation],
+			},
+		},
+		"spec": map[string]interface{}{
+			"source": map[string]interface{}{
+				"persistentVolumeClaimName": pvc.Name,
+			},
+		},
+	}}
+
+	k.Log.Infof("Create Volume Snapshot '%s'", volumeSnapshot.GetName())
+	volumeSnapshot, err = snapshots.Create(ctx, volumeSnapshot, metav1.CreateOptions{})
+	if err != nil {
+		return nil, perrors.Wrap(err, "create volume snapshot")
+	}
+
+	return toSnapshot(volumeSnapshot), nil
+}
+
+func (k *KubernetesDriver) ListSnapshots(ctx context.Context, workspaceID string, options *driver.SnapshotOptions) ([]*driver.Snapshot, error) {
+	workspaceID = getID(workspaceID)
+	snapshots, err := k.snapshotClient()
+	if err != nil {
+		return nil, err
+	}
+
+	volumeSnapshots, err := snapshots.List(ctx, metav1.ListOptions{
+		LabelSelector: DevPodWorkspaceLabel + "=" + workspaceID + "," + DevPodSnapshotLabel,
+	})
+	if err != nil {
+		return nil, perrors.Wrap(err, "list volume snapshots")
+	}
+
+	retSnapshots := []*driver.Snapshot{}
+	for i := range volumeSnapshots.Items {
+		retSnapshots = append(retSnapshots, toSnapshot(&volumeSnapshots.Items[i]))
+	}
+
+	return retSnapshots, nil
+}
+
+func (k *KubernetesDriver) DeleteSnapshot(ctx context.Context, workspaceID string, options *driver.SnapshotOptions) error {
+	workspaceID = getID(workspaceID)
+	snapshots, err := k.snapshotClient()
+	if err != nil {
+		return err
+	}
+
+	name := getSnapshotName(workspaceID, options.Name)
+	k.Log.Infof("Delete Volume Snapshot '%s'", name)
+	err = snapshots.Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return fmt.Errorf("snapshot %s not found", options.Name)
+		}
+
+		return perrors.Wrap(err, "delete volume snapshot")
+	}
+
+	return nil
+}
+
+// RestoreSnapshot creates the persistent volume claim of the workspace from the VolumeSnapshot, the
+// devcontainer will then reuse it instead of initializing a new one
+func (k *KubernetesDriver) RestoreSnapshot(ctx context.Context, workspaceID string, options *driver.SnapshotOptions) (*driver.Snapshot, error) {
+	workspaceID = getID(workspaceID)
+	snapshots, err := k.snapshotClient()
+	if err != nil {
+		return nil, err
+	}
+
+	volumeSnapshot, err := snapshots.Get(ctx, getSnapshotName(getID(options.SourceWorkspaceID), options.Name), metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, fmt.Errorf("snapshot %s of workspace %s not found", options.Name, options.SourceWorkspaceID)
+		}
+
+		return nil, perrors.Wrap(err, "get volume snapshot")
+	}
+
+	existingPvc, _, err := k.getDevContainerPvc(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	} else if existingPvc != nil {
+		return nil, fmt.Errorf("persistent volume claim for workspace %s already exists", workspaceID)
+	}
+
+	// reuse the run options of the workspace the snapshot was taken from
+	containerInfo := &DevContainerInfo{}
+	err = json.Unmarshal([]byte(volumeSnapshot.GetAnnotations()[DevPodInfoAnnotation]), containerInfo)
+	if err != nil || containerInfo.Options == nil {
+		return nil, fmt.Errorf("volume snapshot %s is missing dev container info", volumeSnapshot.GetName())
+	}
+	containerInfo.Options.UID = options.WorkspaceUID
+
+	pvc, err := k.buildPersistentVolumeClaim(workspaceID, containerInfo.Options)
+	if err != nil {
+		return nil, err
+	}
+	apiGroup := volumeSnapshotResource.Group
+	pvc.Spec.DataSource = &corev1.TypedLocalObjectReference{
+		APIGroup: &apiGroup,
+		Kind:     "VolumeSnapshot",
+		Name:     volumeSnapshot.GetName(),
+	}
+
+	// the restored volume has to be at least as big as the snapshot
+	restoreSize, _, _ := unstructured.NestedString(volumeSnapshot.Object, "status", "restoreSize")
+	if restoreSize != "" {
+		quantity, err := resource.ParseQuantity(restoreSize)
+		if err == nil && quantity.Cmp(pvc.Spec.Resources.Requests[corev1.ResourceStorage]) > 0 {
+			pvc.Spec.Resources.Requests[corev1.ResourceStorage] = quantity
+		}
+	}
+
+	k.Log.Infof("Create Persistent Volume Claim '%s' from Volume Snapshot '%s'", workspaceID, volumeSnapshot.GetName())
+	_, err = k.client.Client().CoreV1().PersistentVolumeClaims(k.namespace).Create(ctx, pvc, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("create pvc: %w", err)
+	}
+
+	return toSnapshot(volumeSnapshot), nil
+}
+
+func (k *KubernetesDriver) snapshotClient() (dynamic.ResourceInterface, error) {
+	client, err := dynamic.NewForConfig(k.client.Config())
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Resource(volumeSnapshotResource).Namespace(k.namespace), nil
+}
+
+func toSnapshot(volumeSnapshot *unstructured.Unstructured) *driver.Snapshot {
+	snapshot := &driver.Snapshot{
+		Name:        volumeSnapshot.GetLabels()[DevPodSnapshotLabel],
+		WorkspaceID: volumeSnapshot.GetLabels()[DevPodWorkspaceLabel],
+		Created:     volumeSnapshot.GetCreationTimestamp().Time,
+	}
+	if snapshot.Created.IsZero() {
+		snapshot.Created = time.Now()
+	}
+
+	snapshot.Ready, _, _ = unstructured.NestedBool(volumeSnapshot.Object, "status", "readyToUse")
+	restoreSize, _, _ := unstructured.NestedString(volumeSnapshot.Object, "status", "restoreSize")
+	if restoreSize != "" {
+		quantity, err := resource.ParseQuantity(restoreSize)
+		if err == nil {
+			snapshot.Size = quantity.Value()
+		}
+	}
+
+	return snapshot
+}
+
+func getSnapshotName(workspaceID, name string) string {
+	return workspaceID + "-" + name
+}
@@ -0,0 +1,32 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestToSnapshot(t *testing.T) {
+	volumeSnapshot := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":              "my-workspace-onboarding",
+			"creationTimestamp": "2024-01-02T03:04:05Z",
+			"labels": map[string]interface{}{
+				DevPodWorkspaceLabel: "my-workspace",
+				DevPodSnapshotLabel:  "onboarding",
+			},
+		},
+		"status": map[string]interface{}{
+			"readyToUse":  true,
+			"restoreSize": "10Gi",
+		},
+	}}
+
+	snapshot := toSnapshot(volumeSnapshot)
+	assert.Equal(t, snapshot.Name, "onboarding")
+	assert.Equal(t, snapshot.WorkspaceID, "my-workspace")
+	assert.Equal(t, snapshot.Created.Unix(), int64(1704164645))
+	assert.Equal(t, snapshot.Size, int64(10*1024*1024*1024))
+	assert.Assert(t, snapshot.Ready)
+}
@@ -0,0 +1,349 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/loft-sh/devpod/pkg/devcontainer/config"
+	"github.com/loft-sh/devpod/pkg/driver"
+	"github.com/loft-sh/log"
+	perrors "github.com/pkg/errors"
+)
+
+const stdinChunkSize = 32 * 1024
+
+var _ driver.ReprovisioningDriver = (*Client)(nil)
+
+// Client is a driver that forwards all calls to a plugin speaking the plugin protocol
+type Client struct {
+	log log.Logger
+
+	writeLock sync.Mutex
+	writer    io.Writer
+
+	nextID    atomic.Int64
+	callsLock sync.Mutex
+	calls     map[int64]*call
+
+	done chan struct{}
+	err  error
+
+	canReprovision bool
+}
+
+type call struct {
+	stdout   io.Writer
+	stderr   io.Writer
+	response chan *Message
+}
+
+// NewClient starts reading messages from the plugin and exchanges the protocol version with it
+func NewClient(ctx context.Context, reader io.Reader, writer io.Writer, log log.Logger) (*Client, error) {
+	client := &Client{
+		log:    log,
+		writer: writer,
+		calls:  map[int64]*call{},
+		done:   make(chan struct{}),
+	}
+	go client.read(reader)
+
+	result := &InitializeResult{}
+	err := client.call(ctx, MethodInitialize, &InitializeParams{ProtocolVersion: ProtocolVersion}, result, nil, nil, nil)
+	if err != nil {
+		return nil, perrors.Wrap(err, "initialize plugin")
+	} else if result.ProtocolVersion != ProtocolVersion {
+		return nil, fmt.Errorf("plugin speaks protocol version %d, but DevPod requires version %d", result.ProtocolVersion, ProtocolVersion)
+	}
+
+	client.canReprovision = result.CanReprovision
+	return client, nil
+}
+
+// FindDevContainer returns a running devcontainer details
+func (c *Client) FindDevContainer(ctx context.Context, workspaceID string) (*config.ContainerDetails, error) {
+	result := &FindDevContainerResult{}
+	err := c.call(ctx, MethodFindDevContainer, &WorkspaceParams{WorkspaceID: workspaceID}, result, nil, nil, nil)
+	if err != nil {
+		return nil, perrors.Wrap(err, "find dev container")
+	}
+
+	return result.Container, nil
+}
+
+// CommandDevContainer runs the given command inside the devcontainer
+func (c *Client) CommandDevContainer(ctx context.Context, workspaceID, user, command string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	result := &CommandDevContainerResult{}
+	err := c.call(ctx, MethodCommandDevContainer, &CommandDevContainerParams{
+		WorkspaceID: workspaceID,
+		User:        user,
+		Command:     command,
+		Stdin:       stdin != nil,
+	}, result, stdin, stdout, stderr)
+	if err != nil {
+		return err
+	} else if result.ExitCode != 0 {
+		return &ExitError{Code: result.ExitCode}
+	}
+
+	return nil
+}
+
+// RunDevContainer runs a devcontainer
+func (c *Client) RunDevContainer(ctx context.Context, workspaceID string, options *driver.RunOptions) error {
+	err := c.call(ctx, MethodRunDevContainer, &RunDevContainerParams{WorkspaceID: workspaceID, Options: options}, nil, nil, nil, nil)
+	if err != nil {
+		return perrors.Wrap(err, "run dev container")
+	}
+
+	return nil
+}
+
+// TargetArchitecture returns the architecture of the container runtime. e.g. amd64 or arm64
+func (c *Client) TargetArchitecture(ctx context.Context, workspaceID string) (string, error) {
+	result := &TargetArchitectureResult{}
+	err := c.call(ctx, MethodTargetArchitecture, &WorkspaceParams{WorkspaceID: workspaceID}, result, nil, nil, nil)
+	if err != nil {
+		return "", perrors.Wrap(err, "get target architecture")
+	} else if result.Architecture != "amd64" && result.Architecture != "arm64" {
+		return "", fmt.Errorf("invalid target architecture %s, expected either arm64 or amd64", result.Architecture)
+	}
+
+	return result.Architecture, nil
+}
+
+// DeleteDevContainer deletes the devcontainer
+func (c *Client) DeleteDevContainer(ctx context.Context, workspaceID string) error {
+	err := c.call(ctx, MethodDeleteDevContainer, &WorkspaceParams{WorkspaceID: workspaceID}, nil, nil, nil, nil)
+	if err != nil {
+		return perrors.Wrap(err, "delete dev container")
+	}
+
+	return nil
+}
+
+// StartDevContainer starts the devcontainer
+func (c *Client) StartDevContainer(ctx context.Context, workspaceID string) error {
+	err := c.call(ctx, MethodStartDevContainer, &WorkspaceParams{WorkspaceID: workspaceID}, nil, nil, nil, nil)
+	if err != nil {
+		return perrors.Wrap(err, "start dev container")
+	}
+
+	return nil
+}
+
+// StopDevContainer stops the devcontainer
+func (c *Client) StopDevContainer(ctx context.Context, workspaceID string) error {
+	err := c.call(ctx, MethodStopDevContainer, &WorkspaceParams{WorkspaceID: workspaceID}, nil, nil, nil, nil)
+	if err != nil {
+		return perrors.Wrap(err, "stop dev container")
+	}
+
+	return nil
+}
+
+// GetDevContainerLogs writes the logs of the devcontainer and keeps streaming new output if options.Follow is set
+func (c *Client) GetDevContainerLogs(ctx context.Context, workspaceID string, options *driver.LogsOptions, stdout io.Writer, stderr io.Writer) error {
+	err := c.call(ctx, MethodDevContainerLogs, &DevContainerLogsParams{WorkspaceID: workspaceID, Options: options}, nil, nil, stdout, stderr)
+	if err != nil && ctx.Err() == nil {
+		return perrors.Wrap(err, "get dev container logs")
+	}
+
+	return nil
+}
+
+// CanReprovision returns true if the plugin can reprovision the devcontainer
+func (c *Client) CanReprovision() bool {
+	return c.canReprovision
+}
+
+// call sends a request to the plugin and waits for its response. Stream notifications of the request are
+// written to stdout and stderr, stdin is forwarded to the plugin until it is closed or the request is done.
+func (c *Client) call(ctx context.Context, method string, params interface{}, result interface{}, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	id := c.nextID.Add(1)
+	pending := &call{
+		stdout:   stdout,
+		stderr:   stderr,
+		response: make(chan *Message, 1),
+	}
+	c.callsLock.Lock()
+	c.calls[id] = pending
+	c.callsLock.Unlock()
+	defer func() {
+		c.callsLock.Lock()
+		delete(c.calls, id)
+		c.callsLock.Unlock()
+	}()
+
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	err = c.send(&Message{JSONRPC: "2.0", ID: &id, Method: method, Params: rawParams})
+	if err != nil {
+		return err
+	}
+
+	requestDone := make(chan struct{})
+	defer close(requestDone)
+	if stdin != nil {
+		go c.forwardStdin(id, stdin, requestDone)
+	}
+
+	select {
+	case response := <-pending.response:
+		if response.Error != nil {
+			return response.Error
+		} else if result != nil && len(response.Result) > 0 {
+			err = json.Unmarshal(response.Result, result)
+			if err != nil {
+				return perrors.Wrapf(err, "parse %s result", method)
+			}
+		}
+
+		return nil
+	case <-ctx.Done():
+		_ = c.notify(MethodCancel, &CancelParams{ID: id})
+		return ctx.Err()
+	case <-c.done:
+		return c.err
+	}
+}
+
+func (c *Client) forwardStdin(id int64, stdin io.Reader, requestDone chan struct{}) {
+	buf := make([]byte, stdinChunkSize)
+	for {
+		n, err := stdin.Read(buf)
+		if n > 0 {
+			select {
+			case <-requestDone:
+				return
+			default:
+			}
+
+			notifyErr := c.notify(MethodStream, &StreamParams{ID: id, Stream: StreamStdin, Data: buf[:n]})
+			if notifyErr != nil {
+				return
+			}
+		}
+		if err != nil {
+			if err != io.EOF {
+				c.log.Debugf("error reading stdin: %v", err)
+			}
+
+			_ = c.notify(MethodStream, &StreamParams{ID: id, Stream: StreamStdin, EOF: true})
+			return
+		}
+	}
+}
+
+func (c *Client) read(reader io.Reader) {
+	decoder := json.NewDecoder(reader)
+	for {
+		message := &Message{}
+		err := decoder.Decode(message)
+		if err != nil {
+			if err == io.EOF {
+				err = fmt.Errorf("plugin exited unexpectedly")
+			}
+			c.err = perrors.Wrap(err, "read from plugin")
+			close(c.done)
+			return
+		}
+
+		switch {
+		case message.Method == MethodStream:
+			c.handleStream(message)
+		case message.Method == MethodLog:
+			c.handleLog(message)
+		case message.Method == "" && message.ID != nil:
+			c.callsLock.Lock()
+			pending := c.calls[*message.ID]
+			c.callsLock.Unlock()
+			if pending != nil {
+				pending.response <- message
+			}
+		default:
+			c.log.Debugf("ignoring unexpected plugin message %s", message.Method)
+		}
+	}
+}
+
+func (c *Client) handleStream(message *Message) {
+	params := &StreamParams{}
+	err := json.Unmarshal(message.Params, params)
+	if err != nil {
+		c.log.Debugf("error parsing plugin stream: %v", err)
+		return
+	}
+
+	c.callsLock.Lock()
+	pending := c.calls[params.ID]
+	c.callsLock.Unlock()
+	if pending == nil || len(params.Data) == 0 {
+		return
+	}
+
+	var writer io.Writer
+	switch params.Stream {
+	case StreamStdout:
+		writer = pending.stdout
+	case StreamStderr:
+		writer = pending.stderr
+	}
+	if writer != nil {
+		_, _ = writer.Write(params.Data)
+	}
+}
+
+func (c *Client) handleLog(message *Message) {
+	params := &LogParams{}
+	err := json.Unmarshal(message.Params, params)
+	if err != nil {
+		c.log.Debugf("error parsing plugin log: %v", err)
+		return
+	}
+
+	switch params.Level {
+	case "debug":
+		c.log.Debug(params.Message)
+	case "warn":
+		c.log.Warn(params.Message)
+	case "error":
+		c.log.Error(params.Message)
+	default:
+		c.log.Info(params.Message)
+	}
+}
+
+func (c *Client) notify(method string, params interface{}) error {
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	return c.send(&Message{JSONRPC: "2.0", Method: method, Params: rawParams})
+}
+
+func (c *Client) send(message *Message) error {
+	return writeMessage(&c.writeLock, c.writer, message)
+}
+
+func writeMessage(lock *sync.Mutex, writer io.Writer, message *Message) error {
+	out, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	_, err = writer.Write(append(out, '\n'))
+	if err != nil {
+		return perrors.Wrap(err, "write to plugin")
+	}
+
+	return nil
+}
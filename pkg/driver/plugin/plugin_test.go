@@ -0,0 +1,140 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/loft-sh/devpod/pkg/devcontainer/config"
+	"github.com/loft-sh/devpod/pkg/driver"
+	"github.com/loft-sh/log"
+	"gotest.tools/assert"
+)
+
+type fakeDriver struct {
+	driver.Driver
+
+	runOptions *driver.RunOptions
+}
+
+func (f *fakeDriver) CanReprovision() bool {
+	return true
+}
+
+func (f *fakeDriver) FindDevContainer(ctx context.Context, workspaceID string) (*config.ContainerDetails, error) {
+	if workspaceID != "test" {
+		return nil, nil
+	}
+
+	return &config.ContainerDetails{ID: "container-" + workspaceID}, nil
+}
+
+func (f *fakeDriver) RunDevContainer(ctx context.Context, workspaceID string, options *driver.RunOptions) error {
+	f.runOptions = options
+	return nil
+}
+
+func (f *fakeDriver) TargetArchitecture(ctx context.Context, workspaceID string) (string, error) {
+	return "arm64", nil
+}
+
+func (f *fakeDriver) StopDevContainer(ctx context.Context, workspaceID string) error {
+	return fmt.Errorf("stop %s failed", workspaceID)
+}
+
+func (f *fakeDriver) CommandDevContainer(ctx context.Context, workspaceID, user, command string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	switch command {
+	case "cat":
+		_, err := io.Copy(stdout, stdin)
+		return err
+	case "fail":
+		_, _ = stderr.Write([]byte("failed as " + user))
+		return &ExitError{Code: 3}
+	}
+
+	return nil
+}
+
+func (f *fakeDriver) GetDevContainerLogs(ctx context.Context, workspaceID string, options *driver.LogsOptions, stdout io.Writer, stderr io.Writer) error {
+	if options == nil {
+		_, err := fmt.Fprint(stdout, "default")
+		return err
+	} else if !options.Follow {
+		_, err := fmt.Fprintf(stdout, "tail %d", options.Tail)
+		return err
+	}
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestPlugin(t *testing.T) {
+	ctx := context.Background()
+	pluginStdin, clientWriter := io.Pipe()
+	clientReader, pluginStdout := io.Pipe()
+	fake := &fakeDriver{}
+	go func() {
+		_ = Serve(ctx, fake, pluginStdin, pluginStdout)
+		pluginStdout.Close()
+	}()
+
+	client, err := NewClient(ctx, clientReader, clientWriter, log.Discard)
+	assert.NilError(t, err)
+	assert.Assert(t, client.CanReprovision())
+
+	container, err := client.FindDevContainer(ctx, "test")
+	assert.NilError(t, err)
+	assert.Equal(t, container.ID, "container-test")
+	container, err = client.FindDevContainer(ctx, "other")
+	assert.NilError(t, err)
+	assert.Assert(t, container == nil)
+
+	err = client.RunDevContainer(ctx, "test", &driver.RunOptions{Image: "alpine", User: "root"})
+	assert.NilError(t, err)
+	assert.Equal(t, fake.runOptions.Image, "alpine")
+
+	architecture, err := client.TargetArchitecture(ctx, "test")
+	assert.NilError(t, err)
+	assert.Equal(t, architecture, "arm64")
+
+	err = client.StopDevContainer(ctx, "test")
+	assert.ErrorContains(t, err, "stop test failed")
+
+	// stdin is forwarded to the command
+	stdout := &bytes.Buffer{}
+	err = client.CommandDevContainer(ctx, "test", "root", "cat", strings.NewReader("hello world"), stdout, io.Discard)
+	assert.NilError(t, err)
+	assert.Equal(t, stdout.String(), "hello world")
+
+	stderr := &bytes.Buffer{}
+	err = client.CommandDevContainer(ctx, "test", "vscode", "fail", nil, io.Discard, stderr)
+	assert.Equal(t, err.(*ExitError).ExitCode(), 3)
+	assert.Equal(t, stderr.String(), "failed as vscode")
+
+	stdout.Reset()
+	err = client.GetDevContainerLogs(ctx, "test", &driver.LogsOptions{Tail: -1}, stdout, io.Discard)
+	assert.NilError(t, err)
+	assert.Equal(t, stdout.String(), "tail -1")
+
+	// drivers keep their default without options
+	stdout.Reset()
+	err = client.GetDevContainerLogs(ctx, "test", nil, stdout, io.Discard)
+	assert.NilError(t, err)
+	assert.Equal(t, stdout.String(), "default")
+
+	// canceling a follow stream doesn't break the connection
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	err = client.GetDevContainerLogs(cancelCtx, "test", &driver.LogsOptions{Follow: true}, io.Discard, io.Discard)
+	assert.NilError(t, err)
+	_, err = client.TargetArchitecture(ctx, "test")
+	assert.NilError(t, err)
+
+	// plugin exits once its stdin is closed
+	clientWriter.Close()
+	<-client.done
+	assert.ErrorContains(t, client.err, "plugin exited unexpectedly")
+}
@@ -0,0 +1,164 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/loft-sh/devpod/pkg/devcontainer/config"
+	"github.com/loft-sh/devpod/pkg/driver"
+)
+
+// ProtocolVersion is the version of the plugin protocol, it is increased on incompatible changes
+const ProtocolVersion = 1
+
+// Requests sent from DevPod to the plugin. Every driver method has a request with the same name.
+const (
+	MethodInitialize          = "initialize"
+	MethodFindDevContainer    = "findDevContainer"
+	MethodCommandDevContainer = "commandDevContainer"
+	MethodRunDevContainer     = "runDevContainer"
+	MethodTargetArchitecture  = "targetArchitecture"
+	MethodDeleteDevContainer  = "deleteDevContainer"
+	MethodStartDevContainer   = "startDevContainer"
+	MethodStopDevContainer    = "stopDevContainer"
+	MethodDevContainerLogs    = "devContainerLogs"
+)
+
+// Notifications that don't expect a response
+const (
+	// MethodStream carries stdin from DevPod to the plugin and stdout and stderr from the plugin to DevPod
+	MethodStream = "stream"
+	// MethodCancel is sent by DevPod if it isn't interested in the response of a request anymore
+	MethodCancel = "cancel"
+	// MethodLog is sent by the plugin to write to the DevPod log
+	MethodLog = "log"
+)
+
+// Error codes as defined by JSON-RPC 2.0
+const (
+	ErrorCodeInvalidParams  = -32602
+	ErrorCodeMethodNotFound = -32601
+	ErrorCodeInternal       = -32603
+)
+
+// Message is a JSON-RPC 2.0 request, response or notification. Messages are sent as single lines of JSON
+// over the stdin and stdout of the plugin.
+type Message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *int64          `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is the error of a failed request
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// ExitError is returned if a command in the devcontainer exits with a non-zero code
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// ExitCode returns the exit code of the command
+func (e *ExitError) ExitCode() int {
+	return e.Code
+}
+
+type InitializeParams struct {
+	// ProtocolVersion is the protocol version DevPod speaks
+	ProtocolVersion int `json:"protocolVersion"`
+}
+
+type InitializeResult struct {
+	// ProtocolVersion is the protocol version the plugin speaks, it needs to match the one of DevPod
+	ProtocolVersion int `json:"protocolVersion"`
+
+	// CanReprovision is true if the plugin can reprovision the devcontainer
+	CanReprovision bool `json:"canReprovision,omitempty"`
+}
+
+// WorkspaceParams are the params of findDevContainer, targetArchitecture, deleteDevContainer,
+// startDevContainer and stopDevContainer
+type WorkspaceParams struct {
+	WorkspaceID string `json:"workspaceId"`
+}
+
+type FindDevContainerResult struct {
+	// Container is empty if the devcontainer doesn't exist
+	Container *config.ContainerDetails `json:"container,omitempty"`
+}
+
+type TargetArchitectureResult struct {
+	// Architecture is either amd64 or arm64
+	Architecture string `json:"architecture"`
+}
+
+type RunDevContainerParams struct {
+	WorkspaceID string             `json:"workspaceId"`
+	Options     *driver.RunOptions `json:"options,omitempty"`
+}
+
+// CommandDevContainerParams are the params of commandDevContainer. Output of the command is sent as stream
+// notifications before the response, stdin is sent as stream notifications if Stdin is true.
+type CommandDevContainerParams struct {
+	WorkspaceID string `json:"workspaceId"`
+	User        string `json:"user"`
+	Command     string `json:"command"`
+	Stdin       bool   `json:"stdin,omitempty"`
+}
+
+type CommandDevContainerResult struct {
+	ExitCode int `json:"exitCode"`
+}
+
+// DevContainerLogsParams are the params of devContainerLogs. The logs are sent as stream notifications
+// before the response.
+type DevContainerLogsParams struct {
+	WorkspaceID string              `json:"workspaceId"`
+	Options     *driver.LogsOptions `json:"options,omitempty"`
+}
+
+const (
+	StreamStdin  = "stdin"
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+)
+
+// StreamParams are the params of a stream notification
+type StreamParams struct {
+	// ID is the id of the request the data belongs to
+	ID int64 `json:"id"`
+
+	// Stream is either stdin, stdout or stderr
+	Stream string `json:"stream"`
+
+	// Data is the base64 encoded chunk of the stream
+	Data []byte `json:"data,omitempty"`
+
+	// EOF is true if the stream is closed
+	EOF bool `json:"eof,omitempty"`
+}
+
+type CancelParams struct {
+	// ID is the id of the request to cancel
+	ID int64 `json:"id"`
+}
+
+type LogParams struct {
+	// Level is either debug, info, warn or error
+	Level string `json:"level"`
+
+	Message string `json:"message"`
+}
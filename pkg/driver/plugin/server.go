@@ -0,0 +1,317 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/loft-sh/devpod/pkg/driver"
+)
+
+// Serve answers requests of DevPod with the given driver until the reader is closed. It allows plugins
+// written in Go to implement the plugin protocol by implementing the driver interface.
+func Serve(ctx context.Context, d driver.Driver, reader io.Reader, writer io.Writer) error {
+	s := &server{
+		driver:   d,
+		writer:   writer,
+		requests: map[int64]*request{},
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	decoder := json.NewDecoder(reader)
+	for {
+		message := &Message{}
+		err := decoder.Decode(message)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return fmt.Errorf("read message: %w", err)
+		}
+
+		switch {
+		case message.Method == MethodStream:
+			s.handleStream(message)
+		case message.Method == MethodCancel:
+			s.handleCancel(message)
+		case message.Method != "" && message.ID != nil:
+			s.handleRequest(ctx, message)
+		}
+	}
+}
+
+type server struct {
+	driver driver.Driver
+
+	writeLock sync.Mutex
+	writer    io.Writer
+
+	requestsLock sync.Mutex
+	requests     map[int64]*request
+}
+
+type request struct {
+	cancel context.CancelFunc
+	stdin  *stdinBuffer
+}
+
+func (s *server) handleRequest(ctx context.Context, message *Message) {
+	id := *message.ID
+	ctx, cancel := context.WithCancel(ctx)
+	req := &request{cancel: cancel}
+	if message.Method == MethodCommandDevContainer {
+		req.stdin = newStdinBuffer()
+	}
+
+	s.requestsLock.Lock()
+	s.requests[id] = req
+	s.requestsLock.Unlock()
+
+	go func() {
+		defer func() {
+			s.requestsLock.Lock()
+			delete(s.requests, id)
+			s.requestsLock.Unlock()
+			cancel()
+		}()
+
+		result, err := s.dispatch(ctx, id, message, req)
+		response := &Message{JSONRPC: "2.0", ID: &id}
+		if err != nil {
+			rpcErr := &Error{}
+			if !errors.As(err, &rpcErr) {
+				rpcErr = &Error{Code: ErrorCodeInternal, Message: err.Error()}
+			}
+			response.Error = rpcErr
+		} else if result != nil {
+			response.Result, err = json.Marshal(result)
+			if err != nil {
+				response.Error = &Error{Code: ErrorCodeInternal, Message: err.Error()}
+			}
+		}
+
+		_ = writeMessage(&s.writeLock, s.writer, response)
+	}()
+}
+
+func (s *server) dispatch(ctx context.Context, id int64, message *Message, req *request) (interface{}, error) {
+	stdout := &streamWriter{server: s, id: id, stream: StreamStdout}
+	stderr := &streamWriter{server: s, id: id, stream: StreamStderr}
+	switch message.Method {
+	case MethodInitialize:
+		params := &InitializeParams{}
+		err := parseParams(message, params)
+		if err != nil {
+			return nil, err
+		}
+
+		result := &InitializeResult{ProtocolVersion: ProtocolVersion}
+		if reprovisioningDriver, ok := s.driver.(driver.ReprovisioningDriver); ok {
+			result.CanReprovision = reprovisioningDriver.CanReprovision()
+		}
+		return result, nil
+	case MethodFindDevContainer:
+		params := &WorkspaceParams{}
+		err := parseParams(message, params)
+		if err != nil {
+			return nil, err
+		}
+
+		container, err := s.driver.FindDevContainer(ctx, params.WorkspaceID)
+		if err != nil {
+			return nil, err
+		}
+		return &FindDevContainerResult{Container: container}, nil
+	case MethodCommandDevContainer:
+		params := &CommandDevContainerParams{}
+		err := parseParams(message, params)
+		if err != nil {
+			return nil, err
+		}
+
+		var stdin io.Reader
+		if params.Stdin {
+			stdin = req.stdin
+		}
+		err = s.driver.CommandDevContainer(ctx, params.WorkspaceID, params.User, params.Command, stdin, stdout, stderr)
+		if err != nil {
+			var exitErr interface{ ExitCode() int }
+			if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+				return &CommandDevContainerResult{ExitCode: exitErr.ExitCode()}, nil
+			}
+
+			return nil, err
+		}
+		return &CommandDevContainerResult{}, nil
+	case MethodRunDevContainer:
+		params := &RunDevContainerParams{}
+		err := parseParams(message, params)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, s.driver.RunDevContainer(ctx, params.WorkspaceID, params.Options)
+	case MethodTargetArchitecture:
+		params := &WorkspaceParams{}
+		err := parseParams(message, params)
+		if err != nil {
+			return nil, err
+		}
+
+		architecture, err := s.driver.TargetArchitecture(ctx, params.WorkspaceID)
+		if err != nil {
+			return nil, err
+		}
+		return &TargetArchitectureResult{Architecture: architecture}, nil
+	case MethodDeleteDevContainer, MethodStartDevContainer, MethodStopDevContainer:
+		params := &WorkspaceParams{}
+		err := parseParams(message, params)
+		if err != nil {
+			return nil, err
+		}
+
+		switch message.Method {
+		case MethodDeleteDevContainer:
+			return nil, s.driver.DeleteDevContainer(ctx, params.WorkspaceID)
+		case MethodStartDevContainer:
+			return nil, s.driver.StartDevContainer(ctx, params.WorkspaceID)
+		default:
+			return nil, s.driver.StopDevContainer(ctx, params.WorkspaceID)
+		}
+	case MethodDevContainerLogs:
+		params := &DevContainerLogsParams{}
+		err := parseParams(message, params)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, s.driver.GetDevContainerLogs(ctx, params.WorkspaceID, params.Options, stdout, stderr)
+	}
+
+	return nil, &Error{Code: ErrorCodeMethodNotFound, Message: fmt.Sprintf("method %s not found", message.Method)}
+}
+
+func (s *server) handleStream(message *Message) {
+	params := &StreamParams{}
+	err := json.Unmarshal(message.Params, params)
+	if err != nil || params.Stream != StreamStdin {
+		return
+	}
+
+	s.requestsLock.Lock()
+	req := s.requests[params.ID]
+	s.requestsLock.Unlock()
+	if req == nil || req.stdin == nil {
+		return
+	}
+
+	if len(params.Data) > 0 {
+		req.stdin.Write(params.Data)
+	}
+	if params.EOF {
+		req.stdin.Close()
+	}
+}
+
+func (s *server) handleCancel(message *Message) {
+	params := &CancelParams{}
+	err := json.Unmarshal(message.Params, params)
+	if err != nil {
+		return
+	}
+
+	s.requestsLock.Lock()
+	req := s.requests[params.ID]
+	s.requestsLock.Unlock()
+	if req != nil {
+		req.cancel()
+		if req.stdin != nil {
+			req.stdin.Close()
+		}
+	}
+}
+
+func parseParams(message *Message, params interface{}) error {
+	if len(message.Params) == 0 {
+		return nil
+	}
+
+	err := json.Unmarshal(message.Params, params)
+	if err != nil {
+		return &Error{Code: ErrorCodeInvalidParams, Message: fmt.Sprintf("invalid params: %v", err)}
+	}
+
+	return nil
+}
+
+// streamWriter sends everything written to it as stream notifications of a request
+type streamWriter struct {
+	server *server
+	id     int64
+	stream string
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	rawParams, err := json.Marshal(&StreamParams{ID: w.id, Stream: w.stream, Data: p})
+	if err != nil {
+		return 0, err
+	}
+
+	err = writeMessage(&w.server.writeLock, w.server.writer, &Message{JSONRPC: "2.0", Method: MethodStream, Params: rawParams})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// stdinBuffer buffers the stdin of a request, so that a slow command doesn't block reading other messages
+type stdinBuffer struct {
+	lock   sync.Mutex
+	cond   *sync.Cond
+	data   []byte
+	closed bool
+}
+
+func newStdinBuffer() *stdinBuffer {
+	buffer := &stdinBuffer{}
+	buffer.cond = sync.NewCond(&buffer.lock)
+	return buffer
+}
+
+func (b *stdinBuffer) Write(p []byte) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if !b.closed {
+		b.data = append(b.data, p...)
+		b.cond.Broadcast()
+	}
+}
+
+func (b *stdinBuffer) Close() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.closed = true
+	b.cond.Broadcast()
+}
+
+func (b *stdinBuffer) Read(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for len(b.data) == 0 && !b.closed {
+		b.cond.Wait()
+	}
+	if len(b.data) == 0 {
+		return 0, io.EOF
+	}
+
+	n := copy(p, b.data)
+	b.data = b.data[n:]
+	return n, nil
+}
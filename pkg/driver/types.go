@@ -2,7 +2,10 @@ package driver
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"regexp"
+	"time"
 
 	"github.com/loft-sh/devpod/pkg/devcontainer/config"
 )
@@ -30,8 +33,48 @@ type Driver interface {
 	// StopDevContainer stops the devcontainer
 	StopDevContainer(ctx context.Context, workspaceID string) error
 
-	// GetContainerLogs returns the logs of the devcontainer
-	GetDevContainerLogs(ctx context.Context, workspaceID string, stdout io.Writer, stderr io.Writer) error
+	// GetDevContainerLogs writes the logs of the devcontainer and keeps streaming new output if options.Follow is set.
+	// Without options the driver writes the logs the way it did before options existed.
+	GetDevContainerLogs(ctx context.Context, workspaceID string, options *LogsOptions, stdout io.Writer, stderr io.Writer) error
+}
+
+// LogsOptions define which logs of the devcontainer are returned
+type LogsOptions struct {
+	// Follow keeps streaming the logs until the context is done or the container exits
+	Follow bool
+
+	// Since only shows logs newer than a relative duration such as 10m or a RFC3339 timestamp
+	Since string
+
+	// Tail is the number of lines to show from the end of the logs, negative values show all lines
+	Tail int
+
+	// Timestamps prefixes each line with its timestamp
+	Timestamps bool
+}
+
+// ParseLogsSince parses a relative duration such as 10m or a RFC3339 timestamp into an absolute time
+func ParseLogsSince(since string, now time.Time) (time.Time, error) {
+	duration, err := time.ParseDuration(since)
+	if err == nil {
+		return now.Add(-duration), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, since)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid since %s, expected a duration such as 10m or a RFC3339 timestamp", since)
+	}
+
+	return t, nil
+}
+
+// Close releases the resources of drivers that outlive a single call, e.g. the process of a driver plugin
+func Close(d Driver) error {
+	if closer, ok := d.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
 }
 
 type ReprovisioningDriver interface {
@@ -41,6 +84,126 @@ type ReprovisioningDriver interface {
 	CanReprovision() bool
 }
 
+// SidecarDriver is a driver that can run RunOptions.Sidecars next to the devcontainer
+type SidecarDriver interface {
+	Driver
+
+	// SupportsSidecars returns true if the driver runs sidecars next to the devcontainer
+	SupportsSidecars() bool
+}
+
+// KubeExportDriver is a driver that can export the devcontainer as kubernetes manifests
+type KubeExportDriver interface {
+	Driver
+
+	// GenerateKube writes the kubernetes manifests of the devcontainer and its sidecars to stdout
+	GenerateKube(ctx context.Context, workspaceID string, stdout io.Writer) error
+}
+
+// HostResourcesDriver is a driver that can report the resources available to a dev container
+type HostResourcesDriver interface {
+	Driver
+
+	// HostResources returns the resources available to the devcontainer
+	HostResources(ctx context.Context, workspaceID string) (*HostResources, error)
+}
+
+// HostResources are the resources available to a dev container, zero values are unknown
+type HostResources struct {
+	// CPUs is the number of available cpus
+	CPUs float64 `json:"cpus,omitempty"`
+
+	// Memory is the available memory in bytes
+	Memory int64 `json:"memory,omitempty"`
+
+	// Storage is the available disk space in bytes
+	Storage int64 `json:"storage,omitempty"`
+}
+
+// ResizeDriver is a driver that can change the resources of an existing dev container
+type ResizeDriver interface {
+	Driver
+
+	// ResizeDevContainer changes the cpus and memory of the devcontainer, restarting it only if necessary
+	ResizeDevContainer(ctx context.Context, workspaceID string, options *ResizeOptions) error
+}
+
+// ResizeOptions are the new resources of a dev container, zero values are left unchanged
+type ResizeOptions struct {
+	// CPUs is the number of cpus the devcontainer can use
+	CPUs float64 `json:"cpus,omitempty"`
+
+	// Memory is the memory the devcontainer can use in bytes
+	Memory int64 `json:"memory,omitempty"`
+}
+
+// SnapshotDriver is a driver that can capture the state of a devcontainer and restore it into a new workspace
+type SnapshotDriver interface {
+	Driver
+
+	// CreateSnapshot captures the devcontainer and its workspace folder
+	CreateSnapshot(ctx context.Context, workspaceID string, options *SnapshotOptions) (*Snapshot, error)
+
+	// ListSnapshots returns the snapshots that were taken of the workspace
+	ListSnapshots(ctx context.Context, workspaceID string, options *SnapshotOptions) ([]*Snapshot, error)
+
+	// DeleteSnapshot deletes the snapshot with the given name
+	DeleteSnapshot(ctx context.Context, workspaceID string, options *SnapshotOptions) error
+
+	// RestoreSnapshot prepares the workspace so that its devcontainer starts from the snapshot of options.SourceWorkspaceID
+	RestoreSnapshot(ctx context.Context, workspaceID string, options *SnapshotOptions) (*Snapshot, error)
+}
+
+// SnapshotOptions are the options for creating, deleting and restoring snapshots
+type SnapshotOptions struct {
+	// Name is the name of the snapshot
+	Name string
+
+	// SourceWorkspaceID is the workspace the snapshot was taken from, only used on restore
+	SourceWorkspaceID string
+
+	// WorkspaceUID is the uid of the workspace that is restored into
+	WorkspaceUID string
+
+	// WorkspaceFolder is the local folder that is mounted into the devcontainer
+	WorkspaceFolder string
+
+	// ArchiveDir is the folder where drivers can store an export of the workspace folder
+	ArchiveDir string
+}
+
+// Snapshot is a captured state of a devcontainer
+type Snapshot struct {
+	// Name is the name of the snapshot
+	Name string `json:"name"`
+
+	// WorkspaceID is the workspace the snapshot was taken from
+	WorkspaceID string `json:"workspaceId"`
+
+	// Image is the container image the devcontainer should start from on restore
+	Image string `json:"image,omitempty"`
+
+	// Created is the time the snapshot was taken
+	Created time.Time `json:"created"`
+
+	// Size is the size of the snapshot in bytes if known
+	Size int64 `json:"size,omitempty"`
+
+	// Ready is false while the snapshot is still being taken
+	Ready bool `json:"ready"`
+}
+
+var snapshotNameRegEx = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// ValidateSnapshotName makes sure the name can be used as image tag and kubernetes resource name
+func ValidateSnapshotName(name string) error {
+	if !snapshotNameRegEx.MatchString(name) {
+		return fmt.Errorf("invalid snapshot name %s, only lowercase letters, numbers and dashes are allowed", name)
+	}
+
+	return nil
+}
+
 // RunOptions are the options for running a container
 type RunOptions struct {
 	// UID is a unique identifier for this workspace
@@ -80,4 +243,60 @@ type RunOptions struct {
 	// Bind mounts are expected to get copied from local to remote once. Volume mounts are expected
 	// to be persisted for the lifetime of the container.
 	Mounts []*config.Mount `json:"mounts,omitempty"`
+
+	// HostRequirements are the cpu, memory and storage requirements of the devcontainer
+	HostRequirements *config.HostRequirements `json:"hostRequirements,omitempty"`
+
+	// Sidecars are additional containers that run next to the devcontainer and share its network,
+	// e.g. the other services of a docker compose project. Only drivers implementing SidecarDriver run them.
+	Sidecars []*SidecarOptions `json:"sidecars,omitempty"`
+}
+
+// SidecarOptions define a container that runs next to the devcontainer
+type SidecarOptions struct {
+	// Name is the name of the container, usually the docker compose service name
+	Name string `json:"name,omitempty"`
+
+	// Image is the image to run
+	Image string `json:"image,omitempty"`
+
+	// User is the user to run the container as
+	User string `json:"user,omitempty"`
+
+	// Entrypoint overrides the entrypoint of the image
+	Entrypoint []string `json:"entrypoint,omitempty"`
+
+	// Cmd overrides the cmd of the image
+	Cmd []string `json:"cmd,omitempty"`
+
+	// Env are the environment variables of the container
+	Env map[string]string `json:"env,omitempty"`
+
+	// CapAdd are additional capabilities for the container
+	CapAdd []string `json:"capAdd,omitempty"`
+
+	// Privileged indicates if the container should run with elevated permissions
+	Privileged *bool `json:"privileged,omitempty"`
+
+	// Mounts are the mounts of the container. Volume mounts with the same source are shared with the
+	// devcontainer, bind mounts within the workspace folder mount the workspace and all others are ephemeral.
+	Mounts []*config.Mount `json:"mounts,omitempty"`
+
+	// Healthcheck determines when the container is ready
+	Healthcheck *Healthcheck `json:"healthcheck,omitempty"`
+}
+
+// Healthcheck is a command that is periodically executed in a container to determine if it is ready
+type Healthcheck struct {
+	// Command is executed in the container, a zero exit code means ready
+	Command []string `json:"command,omitempty"`
+
+	// Interval is the time between two checks
+	Interval time.Duration `json:"interval,omitempty"`
+
+	// Timeout is the time after which a check is considered failed
+	Timeout time.Duration `json:"timeout,omitempty"`
+
+	// Retries is the number of consecutive failures needed to consider the container unhealthy
+	Retries int `json:"retries,omitempty"`
 }
@@ -0,0 +1,35 @@
+package driver
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestParseLogsSince(t *testing.T) {
+	now := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		since         string
+		expected      time.Time
+		expectedError string
+	}{
+		{since: "10m", expected: now.Add(-10 * time.Minute)},
+		{since: "1h30m", expected: now.Add(-90 * time.Minute)},
+		{since: "2024-01-01T08:00:00Z", expected: time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)},
+		{since: "2024-01-01T10:00:00+02:00", expected: time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)},
+		{since: "yesterday", expectedError: "invalid since yesterday"},
+		{since: "2024-01-01", expectedError: "invalid since 2024-01-01"},
+	}
+
+	for _, testCase := range testCases {
+		since, err := ParseLogsSince(testCase.since, now)
+		if testCase.expectedError != "" {
+			assert.ErrorContains(t, err, testCase.expectedError, testCase.since)
+			continue
+		}
+
+		assert.NilError(t, err, testCase.since)
+		assert.Assert(t, since.Equal(testCase.expected), "%s: %s", testCase.since, since)
+	}
+}
@@ -0,0 +1,432 @@
+// Package filesync keeps a local folder and a folder in the container of a workspace in sync in both directions.
+// Both sides are compared with the state of the last sync, so changes can be told apart from conflicts.
+package filesync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/loft-sh/log"
+	"github.com/pkg/sftp"
+)
+
+const tempFileSuffix = ".devpod-sync.tmp"
+
+// Options holds the options of the sync
+type Options struct {
+	// LocalFolder is the local folder to sync
+	LocalFolder string
+
+	// RemoteFolder is the folder in the container to sync
+	RemoteFolder string
+
+	// StateFolder is the local folder to keep the state of the sync in
+	StateFolder string
+
+	// Excludes are additional patterns of paths to skip, in the format of a .dockerignore
+	Excludes []string
+
+	// Prefer resolves conflicts with the changes of PreferLocal or PreferRemote, conflicts are skipped if empty
+	Prefer string
+
+	// Interval is the time between two syncs
+	Interval time.Duration
+
+	// Once stops after the first sync
+	Once bool
+}
+
+// Sync syncs the folders over the sftp client until the context is done
+func Sync(ctx context.Context, client *sftp.Client, options Options, log log.Logger) error {
+	if options.Prefer != "" && options.Prefer != PreferLocal && options.Prefer != PreferRemote {
+		return fmt.Errorf("unknown conflict preference %s, choose either %s or %s", options.Prefer, PreferLocal, PreferRemote)
+	}
+
+	err := os.MkdirAll(options.StateFolder, 0755)
+	if err != nil {
+		return err
+	}
+
+	fileLock := flock.New(filepath.Join(options.StateFolder, lockFile))
+	locked, err := fileLock.TryLock()
+	if err != nil {
+		return fmt.Errorf("lock sync: %w", err)
+	} else if !locked {
+		return fmt.Errorf("the workspace is already synced by another process, see 'devpod sync status'")
+	}
+	defer func() {
+		_ = fileLock.Unlock()
+	}()
+
+	s := &syncer{
+		client:  client,
+		options: options,
+		base:    loadState(options.StateFolder, options.LocalFolder, options.RemoteFolder),
+		log:     log,
+	}
+	err = s.prepareRemoteFolder()
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Syncing %s with %s in the container", options.LocalFolder, options.RemoteFolder)
+	for {
+		err = s.sync(ctx)
+		status := &Status{
+			LocalFolder:  options.LocalFolder,
+			RemoteFolder: options.RemoteFolder,
+			LastSync:     s.lastSync,
+			Files:        len(s.base),
+			Conflicts:    s.conflicts,
+		}
+		if err != nil {
+			if errors.Is(err, sftp.ErrSSHFxConnectionLost) || options.Once {
+				return err
+			}
+
+			log.Warnf("Error syncing: %v", err)
+			status.LastError = err.Error()
+		}
+		if statusErr := saveStatus(options.StateFolder, status); statusErr != nil {
+			log.Debugf("Error saving sync status: %v", statusErr)
+		}
+		if options.Once {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(options.Interval):
+		}
+	}
+}
+
+type syncer struct {
+	client  *sftp.Client
+	options Options
+	log     log.Logger
+
+	// base is the tree of the last sync
+	base      Tree
+	conflicts []Conflict
+	lastSync  time.Time
+
+	// uid and gid own the remote folder, the sftp server might run as another user
+	uid int
+	gid int
+}
+
+func (s *syncer) prepareRemoteFolder() error {
+	err := s.client.MkdirAll(s.options.RemoteFolder)
+	if err != nil {
+		return fmt.Errorf("create remote folder %s: %w", s.options.RemoteFolder, err)
+	}
+
+	info, err := s.client.Stat(s.options.RemoteFolder)
+	if err != nil {
+		return fmt.Errorf("stat remote folder %s: %w", s.options.RemoteFolder, err)
+	}
+	s.uid, s.gid = -1, -1
+	if stat, ok := info.Sys().(*sftp.FileStat); ok {
+		s.uid, s.gid = int(stat.UID), int(stat.GID)
+	}
+
+	return nil
+}
+
+// sync runs a single sync of both folders
+func (s *syncer) sync(ctx context.Context) error {
+	// read the ignore files again, they might have been changed
+	matcher, err := newIgnoreMatcher(s.options.LocalFolder, s.options.Excludes)
+	if err != nil {
+		return err
+	}
+
+	local, err := scanLocal(s.options.LocalFolder, matcher)
+	if err != nil {
+		return fmt.Errorf("scan local folder: %w", err)
+	}
+	remote, err := scanRemote(s.client, s.options.RemoteFolder, matcher)
+	if err != nil {
+		return fmt.Errorf("scan remote folder: %w", err)
+	}
+
+	err = s.checkEmptied(local, remote)
+	if err != nil {
+		return err
+	}
+
+	actions, conflicts := reconcile(local, remote, s.base, s.options.Prefer)
+	changed := false
+	uploaded, downloaded, deleted := 0, 0, 0
+	var firstErr error
+	for _, a := range orderActions(actions) {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		applied, conflict, err := s.apply(a)
+		if err != nil {
+			s.log.Debugf("Error syncing %s: %v", a.Path, err)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s %s: %w", a.Type, a.Path, err)
+			}
+			continue
+		} else if conflict != nil {
+			conflicts = append(conflicts, *conflict)
+			continue
+		}
+
+		changed = true
+		switch applied {
+		case actionUpload:
+			uploaded++
+		case actionDownload:
+			downloaded++
+		case actionDeleteLocal, actionDeleteRemote:
+			deleted++
+		}
+	}
+
+	if changed {
+		err = saveState(s.options.StateFolder, s.options.LocalFolder, s.options.RemoteFolder, s.base)
+		if err != nil {
+			return fmt.Errorf("save sync state: %w", err)
+		}
+	}
+	if uploaded+downloaded+deleted > 0 {
+		s.log.Infof("Synced %d uploaded, %d downloaded, %d deleted", uploaded, downloaded, deleted)
+	}
+
+	// only warn about new conflicts
+	for _, conflict := range conflicts {
+		if !slices.Contains(s.conflicts, conflict) {
+			s.log.Warnf("Conflict at %s: %s locally and %s in the container, resolve it by changing one side or with --prefer", conflict.Path, conflict.Local, conflict.Remote)
+		}
+	}
+	s.conflicts = conflicts
+	if firstErr != nil {
+		return firstErr
+	}
+
+	s.lastSync = time.Now()
+	return nil
+}
+
+// checkEmptied refuses to delete all files on one side if the other side is empty, which usually means that the
+// folder was replaced, e.g. by recreating the container, instead of cleared. --prefer decides which side is kept.
+func (s *syncer) checkEmptied(local, remote Tree) error {
+	if len(s.base) == 0 || (len(local) == 0) == (len(remote) == 0) {
+		return nil
+	}
+
+	emptied, emptiedPrefer, kept, keptPrefer := "local folder "+s.options.LocalFolder, PreferLocal, "container", PreferRemote
+	if len(remote) == 0 {
+		emptied, emptiedPrefer, kept, keptPrefer = "folder "+s.options.RemoteFolder+" in the container", PreferRemote, "local folder", PreferLocal
+	}
+
+	switch s.options.Prefer {
+	case emptiedPrefer:
+		return nil
+	case keptPrefer:
+		// sync again from scratch, so the files are copied to the emptied side
+		s.log.Infof("The %s is empty, restoring %d files", emptied, len(s.base))
+		s.base = Tree{}
+		return nil
+	}
+
+	return fmt.Errorf("the %s is empty, but %d files were synced before. Use --prefer %s to restore them or --prefer %s to delete them in the %s", emptied, len(s.base), keptPrefer, emptiedPrefer, kept)
+}
+
+// apply runs the action and updates the tree of the last sync. Returns the action that was run in the end, or a
+// conflict if the contents of a compared file differ or the side to change was changed since it was scanned.
+func (s *syncer) apply(a action) (actionType, *Conflict, error) {
+	remotePath := path.Join(s.options.RemoteFolder, a.Path)
+	localPath := filepath.Join(s.options.LocalFolder, filepath.FromSlash(a.Path))
+	switch a.Type {
+	case actionUpload:
+		conflict, err := s.checkRemote(a, remotePath)
+		if err != nil || conflict != nil {
+			return "", conflict, err
+		}
+
+		// a file replaced a folder or the other way around
+		if a.RemoteExists && a.Local.IsDir != a.Remote.IsDir {
+			conflict, err := s.removeRemote(a, remotePath)
+			if err != nil || conflict != nil {
+				return "", conflict, err
+			}
+		}
+
+		err = s.upload(localPath, remotePath, a.Local)
+		if err != nil {
+			return "", nil, err
+		}
+		s.base[a.Path] = a.Local
+	case actionDownload:
+		conflict, err := s.checkLocal(a, localPath)
+		if err != nil || conflict != nil {
+			return "", conflict, err
+		}
+
+		if a.LocalExists && a.Local.IsDir != a.Remote.IsDir {
+			conflict, err := s.removeLocal(a, localPath)
+			if err != nil || conflict != nil {
+				return "", conflict, err
+			}
+		}
+
+		err = s.download(remotePath, localPath, a.Remote)
+		if err != nil {
+			return "", nil, err
+		}
+		s.base[a.Path] = a.Remote
+	case actionDeleteRemote:
+		conflict, err := s.checkRemote(a, remotePath)
+		if err != nil || conflict != nil {
+			return "", conflict, err
+		}
+
+		conflict, err = s.removeRemote(a, remotePath)
+		if err != nil || conflict != nil {
+			return "", conflict, err
+		}
+		delete(s.base, a.Path)
+	case actionDeleteLocal:
+		conflict, err := s.checkLocal(a, localPath)
+		if err != nil || conflict != nil {
+			return "", conflict, err
+		}
+
+		conflict, err = s.removeLocal(a, localPath)
+		if err != nil || conflict != nil {
+			return "", conflict, err
+		}
+		delete(s.base, a.Path)
+	case actionForget:
+		delete(s.base, a.Path)
+	case actionSynced:
+		s.base[a.Path] = a.Local
+	case actionCompare:
+		equal, err := s.equalContents(localPath, remotePath)
+		if err != nil {
+			return "", nil, err
+		} else if !equal {
+			actionType, conflict := resolveConflict(a.Path, a.Local, true, a.Remote, true, s.options.Prefer)
+			if conflict != nil {
+				return "", conflict, nil
+			}
+
+			a.Type = actionType
+			return s.apply(a)
+		}
+
+		// align the modification times, so the file isn't compared again
+		err = s.client.Chtimes(remotePath, a.Local.modTime(), a.Local.modTime())
+		if err != nil {
+			return "", nil, err
+		}
+		s.base[a.Path] = a.Local
+	}
+
+	return a.Type, nil, nil
+}
+
+// checkRemote returns a conflict if the remote path was changed since it was scanned, so it isn't overwritten or
+// deleted
+func (s *syncer) checkRemote(a action, remotePath string) (*Conflict, error) {
+	info, err := s.client.Lstat(remotePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	} else if unchanged(info, err == nil, a.Remote, a.RemoteExists) {
+		return nil, nil
+	}
+
+	return &Conflict{Path: a.Path, Local: describeChange(a.Local, a.LocalExists), Remote: "changed during the sync"}, nil
+}
+
+// checkLocal returns a conflict if the local path was changed since it was scanned, so it isn't overwritten or
+// deleted
+func (s *syncer) checkLocal(a action, localPath string) (*Conflict, error) {
+	info, err := os.Lstat(localPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	} else if unchanged(info, err == nil, a.Local, a.LocalExists) {
+		return nil, nil
+	}
+
+	return &Conflict{Path: a.Path, Local: "changed during the sync", Remote: describeChange(a.Remote, a.RemoteExists)}, nil
+}
+
+func unchanged(info os.FileInfo, exists bool, scanned Entry, scannedExists bool) bool {
+	if exists != scannedExists {
+		return false
+	}
+
+	return !exists || newEntry(info).Equal(scanned)
+}
+
+// removeRemote removes the remote file or folder. Folders only contain the files that weren't deleted, because they
+// are ignored or were changed, so they are kept and returned as conflict.
+func (s *syncer) removeRemote(a action, remotePath string) (*Conflict, error) {
+	if a.Remote.IsDir {
+		entries, err := s.client.ReadDir(remotePath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		} else if len(entries) > 0 {
+			return &Conflict{Path: a.Path, Local: describeChange(a.Local, a.LocalExists), Remote: "not empty"}, nil
+		}
+	}
+
+	err := s.client.Remove(remotePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// removeLocal removes the local file or folder, folders that aren't empty are kept and returned as conflict
+func (s *syncer) removeLocal(a action, localPath string) (*Conflict, error) {
+	if a.Local.IsDir {
+		entries, err := os.ReadDir(localPath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		} else if len(entries) > 0 {
+			return &Conflict{Path: a.Path, Local: "not empty", Remote: describeChange(a.Remote, a.RemoteExists)}, nil
+		}
+	}
+
+	err := os.Remove(localPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// orderActions deletes files before their folders and creates folders before their files. Deletions come first, so
+// a folder is gone before a file replaces it.
+func orderActions(actions []action) []action {
+	ordered := []action{}
+	for i := len(actions) - 1; i >= 0; i-- {
+		if actions[i].Type == actionDeleteLocal || actions[i].Type == actionDeleteRemote {
+			ordered = append(ordered, actions[i])
+		}
+	}
+	for _, a := range actions {
+		if a.Type != actionDeleteLocal && a.Type != actionDeleteRemote {
+			ordered = append(ordered, a)
+		}
+	}
+
+	return ordered
+}
@@ -0,0 +1,158 @@
+package filesync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devpod/pkg/ssh/sftptest"
+	"github.com/loft-sh/log"
+	"gotest.tools/assert"
+)
+
+func TestSync(t *testing.T) {
+	client := sftptest.NewClient(t)
+	options := newTestOptions(t)
+	writeFiles(t, options.LocalFolder, map[string]string{
+		".gitignore":          "node_modules/\n*.o\n",
+		"src/main.go":         "package a",
+		"node_modules/x/y.js": "ignored",
+		"sub/.gitignore":      "*.log\n",
+		"sub/debug.log":       "ignored",
+		"sub/keep.go":         "package b",
+		"build/main.c":        "int main",
+	})
+	writeFiles(t, options.RemoteFolder, map[string]string{
+		"other/remote.txt": "remote",
+	})
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 100, time.UTC)
+	assert.NilError(t, os.Chtimes(filepath.Join(options.LocalFolder, "src", "main.go"), modTime, modTime))
+
+	assert.NilError(t, Sync(context.Background(), client, options, log.Discard))
+	assertFiles(t, options.RemoteFolder, map[string]string{
+		"src/main.go":         "package a",
+		"sub/keep.go":         "package b",
+		"build/main.c":        "int main",
+		"node_modules/x/y.js": "",
+		"sub/debug.log":       "",
+	})
+	assertFiles(t, options.LocalFolder, map[string]string{
+		"other/remote.txt": "remote",
+	})
+
+	// a change with the same size within the same second
+	writeFiles(t, options.LocalFolder, map[string]string{"src/main.go": "package c"})
+	modTime = modTime.Add(time.Nanosecond)
+	assert.NilError(t, os.Chtimes(filepath.Join(options.LocalFolder, "src", "main.go"), modTime, modTime))
+
+	// deleted folders keep their ignored files
+	writeFiles(t, options.RemoteFolder, map[string]string{"build/main.o": "ignored"})
+	assert.NilError(t, os.RemoveAll(filepath.Join(options.LocalFolder, "build")))
+	assert.NilError(t, os.RemoveAll(filepath.Join(options.RemoteFolder, "other")))
+
+	assert.NilError(t, Sync(context.Background(), client, options, log.Discard))
+	assertFiles(t, options.RemoteFolder, map[string]string{
+		"src/main.go":  "package c",
+		"build/main.c": "",
+		"build/main.o": "ignored",
+	})
+	assertFiles(t, options.LocalFolder, map[string]string{
+		"other/remote.txt": "",
+	})
+
+	status, err := GetStatus(options.StateFolder)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, status.Conflicts, []Conflict{{Path: "build", Local: "deleted", Remote: "not empty"}})
+}
+
+func TestSyncEmptied(t *testing.T) {
+	client := sftptest.NewClient(t)
+	options := newTestOptions(t)
+	writeFiles(t, options.LocalFolder, map[string]string{"a.txt": "a", "dir/b.txt": "b"})
+	assert.NilError(t, Sync(context.Background(), client, options, log.Discard))
+
+	// the container was recreated
+	assert.NilError(t, os.RemoveAll(options.RemoteFolder))
+	assert.NilError(t, os.Mkdir(options.RemoteFolder, 0755))
+	err := Sync(context.Background(), client, options, log.Discard)
+	assert.ErrorContains(t, err, "--prefer local to restore them")
+	assertFiles(t, options.LocalFolder, map[string]string{"a.txt": "a", "dir/b.txt": "b"})
+
+	options.Prefer = PreferLocal
+	assert.NilError(t, Sync(context.Background(), client, options, log.Discard))
+	assertFiles(t, options.RemoteFolder, map[string]string{"a.txt": "a", "dir/b.txt": "b"})
+
+	// the local folder was cleared on purpose
+	assert.NilError(t, os.RemoveAll(filepath.Join(options.LocalFolder, "a.txt")))
+	assert.NilError(t, os.RemoveAll(filepath.Join(options.LocalFolder, "dir")))
+	assert.NilError(t, Sync(context.Background(), client, options, log.Discard))
+	assertFiles(t, options.RemoteFolder, map[string]string{"a.txt": "", "dir/b.txt": ""})
+}
+
+func TestApplyChangedDuringSync(t *testing.T) {
+	options := newTestOptions(t)
+	s := &syncer{client: sftptest.NewClient(t), options: options, base: Tree{}, log: log.Discard, uid: -1, gid: -1}
+	writeFiles(t, options.LocalFolder, map[string]string{"a.txt": "local"})
+	writeFiles(t, options.RemoteFolder, map[string]string{"a.txt": "changed remote"})
+	matcher, err := newIgnoreMatcher(options.LocalFolder, nil)
+	assert.NilError(t, err)
+	local, err := scanLocal(options.LocalFolder, matcher)
+	assert.NilError(t, err)
+	scanned := Entry{Size: 6, ModTime: 100}
+
+	// the remote file isn't overwritten
+	_, conflict, err := s.apply(action{Type: actionUpload, Path: "a.txt", Local: local["a.txt"], LocalExists: true, Remote: scanned, RemoteExists: true})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, conflict, &Conflict{Path: "a.txt", Local: "modified", Remote: "changed during the sync"})
+	assertFiles(t, options.RemoteFolder, map[string]string{"a.txt": "changed remote"})
+
+	// the local file isn't deleted
+	_, conflict, err = s.apply(action{Type: actionDeleteLocal, Path: "a.txt", Local: scanned, LocalExists: true})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, conflict, &Conflict{Path: "a.txt", Local: "changed during the sync", Remote: "deleted"})
+	assertFiles(t, options.LocalFolder, map[string]string{"a.txt": "local"})
+
+	// a file created in the meantime isn't overwritten
+	_, conflict, err = s.apply(action{Type: actionDownload, Path: "a.txt", Remote: scanned, RemoteExists: true})
+	assert.NilError(t, err)
+	assert.Assert(t, conflict != nil)
+	assertFiles(t, options.LocalFolder, map[string]string{"a.txt": "local"})
+	assert.Equal(t, len(s.base), 0)
+}
+
+func newTestOptions(t *testing.T) Options {
+	root := t.TempDir()
+	options := Options{
+		LocalFolder:  filepath.Join(root, "local"),
+		RemoteFolder: filepath.Join(root, "remote"),
+		StateFolder:  filepath.Join(root, "state"),
+		Once:         true,
+	}
+	assert.NilError(t, os.Mkdir(options.LocalFolder, 0755))
+	assert.NilError(t, os.Mkdir(options.RemoteFolder, 0755))
+	return options
+}
+
+func writeFiles(t *testing.T, folder string, files map[string]string) {
+	for name, content := range files {
+		file := filepath.Join(folder, filepath.FromSlash(name))
+		assert.NilError(t, os.MkdirAll(filepath.Dir(file), 0755))
+		assert.NilError(t, os.WriteFile(file, []byte(content), 0644))
+	}
+}
+
+// assertFiles checks the contents of the files in folder, empty contents mean that the file must not exist
+func assertFiles(t *testing.T, folder string, files map[string]string) {
+	for name, expected := range files {
+		content, err := os.ReadFile(filepath.Join(folder, filepath.FromSlash(name)))
+		if expected == "" {
+			assert.Assert(t, os.IsNotExist(err), name)
+			continue
+		}
+
+		assert.NilError(t, err, name)
+		assert.Equal(t, string(content), expected, name)
+	}
+}
@@ -0,0 +1,119 @@
+package filesync
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/moby/patternmatcher"
+	"github.com/moby/patternmatcher/ignorefile"
+)
+
+// defaultExcludes are never synced, the git folder differs on both sides and temp files are only written by the syncer
+var defaultExcludes = []string{".git", "**/*" + tempFileSuffix}
+
+// newIgnoreMatcher returns a matcher for the paths that shouldn't be synced. It combines the .gitignore files in
+// localFolder and its sub folders, the .devpodignore in localFolder and the given excludes.
+func newIgnoreMatcher(localFolder string, excludes []string) (*patternmatcher.PatternMatcher, error) {
+	// the .devpodignore and excludes take precedence over the .gitignore files
+	overrides := []string{}
+	devPodIgnore, err := os.Open(filepath.Join(localFolder, ".devpodignore"))
+	if err == nil {
+		defer devPodIgnore.Close()
+
+		devPodPatterns, err := ignorefile.ReadAll(devPodIgnore)
+		if err != nil {
+			return nil, fmt.Errorf("read .devpodignore: %w", err)
+		}
+		overrides = append(overrides, devPodPatterns...)
+	}
+	overrides = append(overrides, excludes...)
+
+	gitPatterns := []string{}
+	newMatcher := func() (*patternmatcher.PatternMatcher, error) {
+		patterns := append([]string{}, defaultExcludes...)
+		patterns = append(patterns, gitPatterns...)
+		return patternmatcher.New(append(patterns, overrides...))
+	}
+
+	// .gitignore files apply to their folder, the ones in ignored folders are skipped
+	matcher, err := newMatcher()
+	if err != nil {
+		return nil, err
+	}
+	err = filepath.WalkDir(localFolder, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		} else if !d.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(localFolder, p)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		if relPath == "." {
+			relPath = ""
+		} else if isIgnored(matcher, relPath) {
+			return filepath.SkipDir
+		}
+
+		gitIgnore, err := os.ReadFile(filepath.Join(p, ".gitignore"))
+		if err != nil {
+			return nil
+		}
+		gitPatterns = append(gitPatterns, gitIgnoreToPatterns(string(gitIgnore), relPath)...)
+		matcher, err = newMatcher()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return matcher, nil
+}
+
+// gitIgnoreToPatterns converts the lines of the .gitignore in folder to the patterns of a .dockerignore. Patterns
+// without a slash match at any depth in git, so they are prefixed with **/.
+func gitIgnoreToPatterns(gitIgnore string, folder string) []string {
+	patterns := []string{}
+	scanner := bufio.NewScanner(strings.NewReader(gitIgnore))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		negate := strings.HasPrefix(line, "!")
+		line = strings.TrimSuffix(strings.TrimPrefix(line, "!"), "/")
+		if line == "" {
+			continue
+		}
+
+		if strings.Contains(line, "/") {
+			line = path.Join(folder, strings.TrimPrefix(line, "/"))
+		} else {
+			line = path.Join(folder, "**", line)
+		}
+		if negate {
+			line = "!" + line
+		}
+
+		patterns = append(patterns, line)
+	}
+
+	return patterns
+}
+
+func isIgnored(matcher *patternmatcher.PatternMatcher, relPath string) bool {
+	ignored, err := matcher.MatchesOrParentMatches(filepath.FromSlash(relPath))
+	return err == nil && ignored
+}
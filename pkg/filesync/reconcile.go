@@ -0,0 +1,183 @@
+package filesync
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	// PreferLocal resolves conflicts with the local changes
+	PreferLocal = "local"
+	// PreferRemote resolves conflicts with the remote changes
+	PreferRemote = "remote"
+)
+
+type actionType string
+
+const (
+	actionUpload       actionType = "upload"
+	actionDownload     actionType = "download"
+	actionDeleteLocal  actionType = "delete-local"
+	actionDeleteRemote actionType = "delete-remote"
+	actionCompare      actionType = "compare"
+	actionSynced       actionType = "synced"
+	actionForget       actionType = "forget"
+)
+
+type action struct {
+	Type   actionType
+	Path   string
+	Local  Entry
+	Remote Entry
+
+	// LocalExists and RemoteExists tell if the path existed on the side when it was scanned
+	LocalExists  bool
+	RemoteExists bool
+}
+
+// Conflict is a path that was changed locally and remotely since the last sync
+type Conflict struct {
+	Path   string `json:"path"`
+	Local  string `json:"local"`
+	Remote string `json:"remote"`
+}
+
+// reconcile compares the local and remote tree with the tree of the last sync and returns the actions to bring
+// both sides in sync. Paths that were changed on both sides are returned as conflicts, unless prefer is set.
+func reconcile(local, remote, base Tree, prefer string) ([]action, []Conflict) {
+	paths := map[string]bool{}
+	for _, tree := range []Tree{local, remote, base} {
+		for p := range tree {
+			paths[p] = true
+		}
+	}
+	sortedPaths := make([]string, 0, len(paths))
+	for p := range paths {
+		sortedPaths = append(sortedPaths, p)
+	}
+	sort.Strings(sortedPaths)
+
+	actions := []action{}
+	conflicts := []Conflict{}
+	for _, p := range sortedPaths {
+		l, localExists := local[p]
+		r, remoteExists := remote[p]
+		b, baseExists := base[p]
+		localChanged := changed(l, localExists, b, baseExists)
+		remoteChanged := changed(r, remoteExists, b, baseExists)
+
+		a := action{Path: p, Local: l, Remote: r, LocalExists: localExists, RemoteExists: remoteExists}
+		switch {
+		case !localChanged && !remoteChanged:
+			continue
+		case localChanged && !remoteChanged:
+			a.Type = pushAction(localExists)
+		case remoteChanged && !localChanged:
+			a.Type = pullAction(remoteExists)
+		case !localExists && !remoteExists:
+			a.Type = actionForget
+		case localExists && remoteExists && l.Equal(r):
+			a.Type = actionSynced
+		case localExists && remoteExists && !l.IsDir && !r.IsDir && l.Size == r.Size:
+			a.Type = actionCompare
+		default:
+			conflictType, conflict := resolveConflict(p, l, localExists, r, remoteExists, prefer)
+			if conflict != nil {
+				conflicts = append(conflicts, *conflict)
+				continue
+			}
+			a.Type = conflictType
+		}
+
+		actions = append(actions, a)
+	}
+
+	return skipKeptFolders(actions, conflicts), conflicts
+}
+
+// resolveConflict returns the action for a path that was changed on both sides, or the conflict if there is no
+// preferred side
+func resolveConflict(p string, local Entry, localExists bool, remote Entry, remoteExists bool, prefer string) (actionType, *Conflict) {
+	switch prefer {
+	case PreferLocal:
+		return pushAction(localExists), nil
+	case PreferRemote:
+		return pullAction(remoteExists), nil
+	}
+
+	return "", &Conflict{
+		Path:   p,
+		Local:  describeChange(local, localExists),
+		Remote: describeChange(remote, remoteExists),
+	}
+}
+
+// skipKeptFolders removes the deletion of folders that still contain changed files or conflicts
+func skipKeptFolders(actions []action, conflicts []Conflict) []action {
+	kept := []string{}
+	for _, a := range actions {
+		if a.Type != actionDeleteLocal && a.Type != actionDeleteRemote && a.Type != actionForget {
+			kept = append(kept, a.Path)
+		}
+	}
+	for _, conflict := range conflicts {
+		kept = append(kept, conflict.Path)
+	}
+
+	ret := []action{}
+	for _, a := range actions {
+		if (a.Type == actionDeleteLocal || a.Type == actionDeleteRemote) && containsChild(kept, a.Path) {
+			continue
+		}
+
+		ret = append(ret, a)
+	}
+
+	return ret
+}
+
+func containsChild(paths []string, folder string) bool {
+	for _, p := range paths {
+		if strings.HasPrefix(p, folder+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func changed(entry Entry, exists bool, base Entry, baseExists bool) bool {
+	if exists != baseExists {
+		return true
+	} else if !exists {
+		return false
+	}
+
+	return !entry.Equal(base)
+}
+
+func pushAction(localExists bool) actionType {
+	if localExists {
+		return actionUpload
+	}
+
+	return actionDeleteRemote
+}
+
+func pullAction(remoteExists bool) actionType {
+	if remoteExists {
+		return actionDownload
+	}
+
+	return actionDeleteLocal
+}
+
+func describeChange(entry Entry, exists bool) string {
+	if !exists {
+		return "deleted"
+	} else if entry.IsDir {
+		return "folder"
+	}
+
+	return "modified"
+}
@@ -0,0 +1,176 @@
+package filesync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestReconcile(t *testing.T) {
+	file := Entry{Size: 1, ModTime: 100, Mode: 0644}
+	changedFile := Entry{Size: 2, ModTime: 200, Mode: 0644}
+	sameSizeFile := Entry{Size: 1, ModTime: 300, Mode: 0644}
+	dir := Entry{IsDir: true}
+
+	testCases := []struct {
+		name              string
+		local             Tree
+		remote            Tree
+		base              Tree
+		prefer            string
+		expectedActions   map[string]actionType
+		expectedConflicts []string
+	}{
+		{
+			name:            "Initial sync",
+			local:           Tree{"a": file, "b": file},
+			remote:          Tree{"b": file, "c": file},
+			base:            Tree{},
+			expectedActions: map[string]actionType{"a": actionUpload, "b": actionSynced, "c": actionDownload},
+		},
+		{
+			name:            "Changed on one side",
+			local:           Tree{"a": changedFile, "b": file},
+			remote:          Tree{"a": file, "b": changedFile},
+			base:            Tree{"a": file, "b": file},
+			expectedActions: map[string]actionType{"a": actionUpload, "b": actionDownload},
+		},
+		{
+			name:            "Deleted on one side",
+			local:           Tree{"b": file},
+			remote:          Tree{"a": file},
+			base:            Tree{"a": file, "b": file},
+			expectedActions: map[string]actionType{"a": actionDeleteRemote, "b": actionDeleteLocal},
+		},
+		{
+			name:            "Deleted on both sides",
+			local:           Tree{},
+			remote:          Tree{},
+			base:            Tree{"a": file},
+			expectedActions: map[string]actionType{"a": actionForget},
+		},
+		{
+			name:              "Changed on both sides",
+			local:             Tree{"a": changedFile, "b": sameSizeFile},
+			remote:            Tree{"a": Entry{Size: 3, ModTime: 300}, "b": file},
+			base:              Tree{"a": file},
+			expectedActions:   map[string]actionType{"b": actionCompare},
+			expectedConflicts: []string{"a"},
+		},
+		{
+			name:            "Changed on both sides with preference",
+			local:           Tree{},
+			remote:          Tree{"a": changedFile},
+			base:            Tree{"a": file},
+			prefer:          PreferLocal,
+			expectedActions: map[string]actionType{"a": actionDeleteRemote},
+		},
+		{
+			name:            "Deleted folder with new file",
+			local:           Tree{"dir": dir, "dir/new": file},
+			remote:          Tree{},
+			base:            Tree{"dir": dir},
+			expectedActions: map[string]actionType{"dir/new": actionUpload},
+		},
+		{
+			name:              "Deleted folder with conflict",
+			local:             Tree{},
+			remote:            Tree{"dir": dir, "dir/a": changedFile},
+			base:              Tree{"dir": dir, "dir/a": file},
+			expectedActions:   map[string]actionType{},
+			expectedConflicts: []string{"dir/a"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actions, conflicts := reconcile(testCase.local, testCase.remote, testCase.base, testCase.prefer)
+
+			actualActions := map[string]actionType{}
+			for _, a := range actions {
+				actualActions[a.Path] = a.Type
+			}
+			for p, expected := range testCase.expectedActions {
+				assert.Equal(t, actualActions[p], expected, p)
+			}
+			assert.Equal(t, len(actions), len(testCase.expectedActions))
+
+			actualConflicts := []string{}
+			for _, conflict := range conflicts {
+				actualConflicts = append(actualConflicts, conflict.Path)
+			}
+			assert.DeepEqual(t, actualConflicts, append([]string{}, testCase.expectedConflicts...))
+		})
+	}
+}
+
+func TestEntryEqual(t *testing.T) {
+	file := Entry{Size: 1, ModTime: 100, ModTimeNano: 500}
+	assert.Assert(t, file.Equal(Entry{Size: 1, ModTime: 100, ModTimeNano: 500}))
+	assert.Assert(t, !file.Equal(Entry{Size: 1, ModTime: 100, ModTimeNano: 600}))
+	assert.Assert(t, file.Equal(Entry{Size: 1, ModTime: 100}), "remote entries have no nanoseconds")
+	assert.Assert(t, !file.Equal(Entry{Size: 1, ModTime: 101}))
+	assert.Assert(t, !file.Equal(Entry{Size: 2, ModTime: 100, ModTimeNano: 500}))
+	assert.Assert(t, Entry{IsDir: true}.Equal(Entry{IsDir: true}))
+	assert.Assert(t, !file.Equal(Entry{IsDir: true}))
+}
+
+func TestOrderActions(t *testing.T) {
+	ordered := orderActions([]action{
+		{Type: actionDeleteRemote, Path: "a"},
+		{Type: actionDeleteRemote, Path: "a/b"},
+		{Type: actionUpload, Path: "c"},
+		{Type: actionUpload, Path: "c/d"},
+	})
+
+	paths := []string{}
+	for _, a := range ordered {
+		paths = append(paths, a.Path)
+	}
+	assert.DeepEqual(t, paths, []string{"a/b", "a", "c", "c/d"})
+}
+
+func TestIgnoreMatcher(t *testing.T) {
+	localFolder := t.TempDir()
+	err := os.WriteFile(filepath.Join(localFolder, ".gitignore"), []byte("# build output\nnode_modules/\n/dist\n*.log\n!keep.log\n"), 0644)
+	assert.NilError(t, err)
+	err = os.WriteFile(filepath.Join(localFolder, ".devpodignore"), []byte("secrets\n"), 0644)
+	assert.NilError(t, err)
+	err = os.MkdirAll(filepath.Join(localFolder, "web", "cache"), 0755)
+	assert.NilError(t, err)
+	err = os.WriteFile(filepath.Join(localFolder, "web", ".gitignore"), []byte("*.tmp\n/build\n!keep.tmp\n"), 0644)
+	assert.NilError(t, err)
+	err = os.MkdirAll(filepath.Join(localFolder, "node_modules", "react"), 0755)
+	assert.NilError(t, err)
+	err = os.WriteFile(filepath.Join(localFolder, "node_modules", "react", ".gitignore"), []byte("!*.log\n"), 0644)
+	assert.NilError(t, err)
+
+	matcher, err := newIgnoreMatcher(localFolder, []string{"tmp"})
+	assert.NilError(t, err)
+
+	for p, expected := range map[string]bool{
+		".git/config":                  true,
+		"node_modules":                 true,
+		"web/node_modules/react":       true,
+		"dist/app.js":                  true,
+		"web/dist/app.js":              false,
+		"debug.log":                    true,
+		"web/debug.log":                true,
+		"keep.log":                     false,
+		"secrets":                      true,
+		"tmp/file":                     true,
+		"main.go":                      false,
+		"web/cache/file.tmp":           true,
+		"web/keep.tmp":                 false,
+		"file.tmp":                     false,
+		"web/build/app.js":             true,
+		"web/cache/build/app.js":       false,
+		"node_modules/react/debug.log": true,
+		"main.go" + tempFileSuffix:     true,
+		"web/main.go" + tempFileSuffix: true,
+	} {
+		assert.Equal(t, isIgnored(matcher, p), expected, p)
+	}
+}
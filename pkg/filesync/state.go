@@ -0,0 +1,124 @@
+package filesync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gofrs/flock"
+)
+
+const (
+	statusFile = "sync_status.json"
+	lockFile   = "sync.lock"
+)
+
+// Status is the status of the sync of a workspace
+type Status struct {
+	// LocalFolder is the synced local folder
+	LocalFolder string `json:"localFolder"`
+
+	// RemoteFolder is the synced folder in the container
+	RemoteFolder string `json:"remoteFolder"`
+
+	// Running is true if the sync is currently running
+	Running bool `json:"running"`
+
+	// LastSync is the time of the last completed sync
+	LastSync time.Time `json:"lastSync,omitempty"`
+
+	// Files is the number of synced files and folders
+	Files int `json:"files"`
+
+	// Conflicts are the paths that were changed on both sides and weren't synced
+	Conflicts []Conflict `json:"conflicts,omitempty"`
+
+	// LastError is the error of the last sync, if any
+	LastError string `json:"lastError,omitempty"`
+}
+
+// state is the tree of the last sync, the common ancestor to detect changes on both sides
+type state struct {
+	LocalFolder  string `json:"localFolder"`
+	RemoteFolder string `json:"remoteFolder"`
+	Tree         Tree   `json:"tree"`
+}
+
+// GetStatus returns the status of the sync that keeps its state in stateFolder, or nil if there was none
+func GetStatus(stateFolder string) (*Status, error) {
+	out, err := os.ReadFile(filepath.Join(stateFolder, statusFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	status := &Status{}
+	err = json.Unmarshal(out, status)
+	if err != nil {
+		return nil, err
+	}
+
+	// the lock is held as long as the sync is running
+	fileLock := flock.New(filepath.Join(stateFolder, lockFile))
+	locked, err := fileLock.TryLock()
+	if err == nil && locked {
+		_ = fileLock.Unlock()
+	} else {
+		status.Running = true
+	}
+
+	return status, nil
+}
+
+// stateFile returns the file of the state of the folders, so syncing other folders doesn't lose it
+func stateFile(stateFolder, localFolder, remoteFolder string) string {
+	hash := sha256.Sum256([]byte(localFolder + "\n" + remoteFolder))
+	return filepath.Join(stateFolder, "sync_state_"+hex.EncodeToString(hash[:8])+".json")
+}
+
+// loadState returns the tree of the last sync between the folders, or an empty one if they weren't synced before
+func loadState(stateFolder, localFolder, remoteFolder string) Tree {
+	out, err := os.ReadFile(stateFile(stateFolder, localFolder, remoteFolder))
+	if err != nil {
+		return Tree{}
+	}
+
+	s := &state{}
+	err = json.Unmarshal(out, s)
+	if err != nil || s.LocalFolder != localFolder || s.RemoteFolder != remoteFolder || s.Tree == nil {
+		return Tree{}
+	}
+
+	return s.Tree
+}
+
+func saveState(stateFolder, localFolder, remoteFolder string, tree Tree) error {
+	return writeJSON(stateFile(stateFolder, localFolder, remoteFolder), &state{
+		LocalFolder:  localFolder,
+		RemoteFolder: remoteFolder,
+		Tree:         tree,
+	})
+}
+
+func saveStatus(stateFolder string, status *Status) error {
+	return writeJSON(filepath.Join(stateFolder, statusFile), status)
+}
+
+func writeJSON(file string, obj interface{}) error {
+	out, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(file+tempFileSuffix, out, 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(file+tempFileSuffix, file)
+}
@@ -0,0 +1,143 @@
+package filesync
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// upload copies the local file or folder to the container. Files are written to a temporary file first, so
+// processes in the container never see a partial file.
+func (s *syncer) upload(localPath, remotePath string, entry Entry) error {
+	if entry.IsDir {
+		err := s.client.MkdirAll(remotePath)
+		if err != nil {
+			return err
+		}
+
+		s.chown(remotePath)
+		return nil
+	}
+
+	err := s.client.MkdirAll(path.Dir(remotePath))
+	if err != nil {
+		return err
+	}
+
+	src, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tempPath := remotePath + tempFileSuffix
+	dst, err := s.client.OpenFile(tempPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+	_, err = dst.ReadFrom(src)
+	_ = dst.Close()
+	if err != nil {
+		_ = s.client.Remove(tempPath)
+		return err
+	}
+
+	s.chown(tempPath)
+	err = s.client.Chmod(tempPath, fileMode(entry))
+	if err != nil {
+		return err
+	}
+	err = s.client.Chtimes(tempPath, entry.modTime(), entry.modTime())
+	if err != nil {
+		return err
+	}
+
+	return s.client.PosixRename(tempPath, remotePath)
+}
+
+// download copies the file or folder in the container to the local folder
+func (s *syncer) download(remotePath, localPath string, entry Entry) error {
+	if entry.IsDir {
+		return os.MkdirAll(localPath, 0755)
+	}
+
+	err := os.MkdirAll(filepath.Dir(localPath), 0755)
+	if err != nil {
+		return err
+	}
+
+	src, err := s.client.Open(remotePath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tempPath := localPath + tempFileSuffix
+	dst, err := os.OpenFile(tempPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fileMode(entry))
+	if err != nil {
+		return err
+	}
+	_, err = src.WriteTo(dst)
+	_ = dst.Close()
+	if err != nil {
+		_ = os.Remove(tempPath)
+		return err
+	}
+
+	err = os.Chtimes(tempPath, entry.modTime(), entry.modTime())
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tempPath, localPath)
+}
+
+// equalContents checks if the local and remote file have the same contents
+func (s *syncer) equalContents(localPath, remotePath string) (bool, error) {
+	localFile, err := os.Open(localPath)
+	if err != nil {
+		return false, err
+	}
+	defer localFile.Close()
+
+	localHash := sha256.New()
+	_, err = io.Copy(localHash, localFile)
+	if err != nil {
+		return false, err
+	}
+
+	remoteFile, err := s.client.Open(remotePath)
+	if err != nil {
+		return false, err
+	}
+	defer remoteFile.Close()
+
+	remoteHash := sha256.New()
+	_, err = remoteFile.WriteTo(remoteHash)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(localHash.Sum(nil), remoteHash.Sum(nil)), nil
+}
+
+// chown gives the path to the owner of the remote folder, errors are ignored as the sftp server might not be
+// allowed to change the owner
+func (s *syncer) chown(remotePath string) {
+	if s.uid < 0 {
+		return
+	}
+
+	_ = s.client.Chown(remotePath, s.uid, s.gid)
+}
+
+func fileMode(entry Entry) os.FileMode {
+	if entry.Mode == 0 {
+		return 0644
+	}
+
+	return entry.Mode
+}
@@ -0,0 +1,128 @@
+package filesync
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/moby/patternmatcher"
+	"github.com/pkg/sftp"
+)
+
+// Entry is the state of a synced file or folder
+type Entry struct {
+	IsDir       bool        `json:"isDir,omitempty"`
+	Size        int64       `json:"size,omitempty"`
+	ModTime     int64       `json:"modTime,omitempty"`
+	ModTimeNano int64       `json:"modTimeNano,omitempty"`
+	Mode        fs.FileMode `json:"mode,omitempty"`
+}
+
+// Equal checks if the entries are the same. Files are compared by size and modification time. sftp only transfers
+// seconds, so the nanoseconds are only compared if both entries have them, which is the case for local files.
+func (e Entry) Equal(other Entry) bool {
+	if e.IsDir || other.IsDir {
+		return e.IsDir == other.IsDir
+	} else if e.Size != other.Size || e.ModTime != other.ModTime {
+		return false
+	}
+
+	return e.ModTimeNano == 0 || other.ModTimeNano == 0 || e.ModTimeNano == other.ModTimeNano
+}
+
+func (e Entry) modTime() time.Time {
+	return time.Unix(e.ModTime, e.ModTimeNano)
+}
+
+func newEntry(info fs.FileInfo) Entry {
+	if info.IsDir() {
+		return Entry{IsDir: true}
+	}
+
+	return Entry{
+		Size:        info.Size(),
+		ModTime:     info.ModTime().Unix(),
+		ModTimeNano: int64(info.ModTime().Nanosecond()),
+		Mode:        info.Mode().Perm(),
+	}
+}
+
+// Tree maps the slash separated paths relative to the synced folder to their entries
+type Tree map[string]Entry
+
+// scanLocal returns the files and folders in localFolder that aren't ignored. Symlinks and other special files
+// are skipped.
+func scanLocal(localFolder string, matcher *patternmatcher.PatternMatcher) (Tree, error) {
+	tree := Tree{}
+	err := filepath.WalkDir(localFolder, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		} else if p == localFolder {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(localFolder, p)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		if isIgnored(matcher, relPath) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		} else if !d.IsDir() && !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		tree[relPath] = newEntry(info)
+		return nil
+	})
+
+	return tree, err
+}
+
+// scanRemote returns the files and folders in remoteFolder that aren't ignored
+func scanRemote(client *sftp.Client, remoteFolder string, matcher *patternmatcher.PatternMatcher) (Tree, error) {
+	tree := Tree{}
+	walker := client.Walk(remoteFolder)
+	for walker.Step() {
+		err := walker.Err()
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		} else if walker.Path() == remoteFolder {
+			continue
+		}
+
+		relPath := strings.TrimPrefix(strings.TrimPrefix(walker.Path(), remoteFolder), "/")
+		info := walker.Stat()
+		if isIgnored(matcher, relPath) {
+			if info.IsDir() {
+				walker.SkipDir()
+			}
+			continue
+		} else if !info.IsDir() && !info.Mode().IsRegular() {
+			continue
+		}
+
+		tree[relPath] = newEntry(info)
+	}
+
+	return tree, nil
+}
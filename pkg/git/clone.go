@@ -3,6 +3,9 @@ package git
 import (
 	"context"
 	"fmt"
+	"io"
+	"slices"
+	"strings"
 
 	"github.com/loft-sh/log"
 	"github.com/sirupsen/logrus"
@@ -40,6 +43,19 @@ func WithRecursiveSubmodules() Option {
 	}
 }
 
+// SparsePathsFunc returns the directories of a sparse checkout after the repository was cloned
+type SparsePathsFunc func(ctx context.Context, targetDir string, extraEnv []string) ([]string, error)
+
+// WithSparseCheckout limits the working tree to directories in cone mode. Files are only checked out after the sparse
+// checkout is configured and full clones become blobless, so files outside of the directories are never downloaded.
+// getPaths returns the final directories and can extend paths with directories defined in the repository.
+func WithSparseCheckout(paths []string, getPaths SparsePathsFunc) Option {
+	return func(c *cloner) {
+		c.sparsePaths = paths
+		c.getSparsePaths = getPaths
+	}
+}
+
 func WithSkipLFS() Option {
 	return func(c *cloner) {
 		c.skipLFS = true
@@ -87,15 +103,22 @@ func (s *CloneStrategy) String() string {
 }
 
 type cloner struct {
-	extraArgs     []string
-	cloneStrategy CloneStrategy
-	skipLFS       bool
+	extraArgs      []string
+	cloneStrategy  CloneStrategy
+	skipLFS        bool
+	sparsePaths    []string
+	getSparsePaths SparsePathsFunc
 }
 
 var _ Cloner = &cloner{}
 
 func (c *cloner) initialArgs() []string {
-	switch c.cloneStrategy {
+	cloneStrategy := c.cloneStrategy
+	if cloneStrategy == FullCloneStrategy && c.sparse() {
+		cloneStrategy = BloblessCloneStrategy
+	}
+
+	switch cloneStrategy {
 	case BloblessCloneStrategy:
 		return []string{"clone", "--filter=blob:none"}
 	case TreelessCloneStrategy:
@@ -123,6 +146,9 @@ func (c *cloner) Clone(ctx context.Context, repository string, targetDir string,
 	args := c.initialArgs()
 	args = append(args, extraArgs...)
 	args = append(args, c.extraArgs...)
+	if c.sparse() {
+		args = append(args, "--no-checkout")
+	}
 	args = append(args, repository, targetDir)
 	args = append(args, "--progress")
 
@@ -134,6 +160,51 @@ func (c *cloner) Clone(ctx context.Context, repository string, targetDir string,
 	gitCommand := CommandContext(ctx, extraEnv, args...)
 	gitCommand.Stdout = w
 	gitCommand.Stderr = w
+	err := gitCommand.Run()
+	if err != nil {
+		return err
+	}
+
+	if !c.sparse() {
+		return nil
+	}
+
+	return c.sparseCheckout(ctx, targetDir, extraEnv, w)
+}
+
+// sparse returns true if the working tree of the clone is limited to the sparse checkout directories
+func (c *cloner) sparse() bool {
+	return c.cloneStrategy != BareCloneStrategy && (len(c.sparsePaths) > 0 || c.getSparsePaths != nil)
+}
+
+func (c *cloner) sparseCheckout(ctx context.Context, targetDir string, extraEnv []string, w io.Writer) error {
+	paths := c.sparsePaths
+	if c.getSparsePaths != nil {
+		var err error
+		paths, err = c.getSparsePaths(ctx, targetDir, extraEnv)
+		if err != nil {
+			return fmt.Errorf("get sparse checkout paths: %w", err)
+		}
+	}
+
+	commands := [][]string{}
+	if len(paths) > 0 {
+		commands = append(commands, append([]string{"sparse-checkout", "set", "--cone", "--"}, paths...))
+	}
+	commands = append(commands, []string{"read-tree", "-mu", "HEAD"})
+	if slices.Contains(c.extraArgs, "--recurse-submodules") {
+		commands = append(commands, []string{"submodule", "update", "--init", "--recursive"})
+	}
+	for _, args := range commands {
+		gitCommand := CommandContext(ctx, extraEnv, args...)
+		gitCommand.Dir = targetDir
+		gitCommand.Stdout = w
+		gitCommand.Stderr = w
+		err := gitCommand.Run()
+		if err != nil {
+			return fmt.Errorf("git %s: %w", strings.Join(args[:2], " "), err)
+		}
+	}
 
-	return gitCommand.Run()
+	return nil
 }
@@ -0,0 +1,87 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/loft-sh/log"
+	"gotest.tools/assert"
+)
+
+func TestCloneSparseCheckout(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repository := newTestRepository(t)
+	for _, file := range []string{"README.md", "services/api/main.go", "services/web/main.go", "libs/shared/lib.go"} {
+		assert.NilError(t, os.MkdirAll(filepath.Join(repository, filepath.Dir(file)), 0755))
+		assert.NilError(t, os.WriteFile(filepath.Join(repository, file), []byte(file), 0644))
+	}
+	gitCommand(t, repository, "add", "-A")
+	gitCommand(t, repository, "commit", "-q", "-m", "files")
+	gitCommand(t, repository, "config", "uploadpack.allowFilter", "true")
+
+	testCases := []struct {
+		Name     string
+		Options  []Option
+		Expected []string
+		Missing  []string
+		Filter   string
+	}{
+		{
+			Name:     "Full clone",
+			Expected: []string{"README.md", "services/api/main.go", "services/web/main.go", "libs/shared/lib.go"},
+		},
+		{
+			Name:     "Sparse paths",
+			Options:  []Option{WithSparseCheckout([]string{"services/api"}, nil)},
+			Expected: []string{"README.md", "services/api/main.go"},
+			Missing:  []string{"services/web/main.go", "libs/shared/lib.go"},
+			Filter:   "blob:none",
+		},
+		{
+			Name: "Sparse paths extended by the repository",
+			Options: []Option{WithSparseCheckout([]string{"services/api"}, func(ctx context.Context, targetDir string, extraEnv []string) ([]string, error) {
+				// files of the repository must not be checked out yet
+				_, err := os.Stat(filepath.Join(targetDir, "README.md"))
+				assert.Assert(t, os.IsNotExist(err))
+				return []string{"services/api", "libs/shared"}, nil
+			})},
+			Expected: []string{"README.md", "services/api/main.go", "libs/shared/lib.go"},
+			Missing:  []string{"services/web/main.go"},
+			Filter:   "blob:none",
+		},
+		{
+			Name:     "Sparse paths with treeless clone",
+			Options:  []Option{WithCloneStrategy(TreelessCloneStrategy), WithSparseCheckout([]string{"libs/shared"}, nil)},
+			Expected: []string{"README.md", "libs/shared/lib.go"},
+			Missing:  []string{"services/api/main.go", "services/web/main.go"},
+			Filter:   "tree:0",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			targetDir := filepath.Join(t.TempDir(), "clone")
+			err := NewClonerWithOpts(testCase.Options...).Clone(context.Background(), "file://"+repository, targetDir, nil, nil, log.Discard)
+			assert.NilError(t, err)
+
+			for _, file := range testCase.Expected {
+				_, err := os.Stat(filepath.Join(targetDir, file))
+				assert.NilError(t, err, file)
+			}
+			for _, file := range testCase.Missing {
+				_, err := os.Stat(filepath.Join(targetDir, file))
+				assert.Assert(t, os.IsNotExist(err), file)
+			}
+
+			filter, _ := exec.Command("git", "-C", targetDir, "config", "remote.origin.partialclonefilter").Output()
+			assert.Equal(t, strings.TrimSpace(string(filter)), testCase.Filter)
+		})
+	}
+}
@@ -252,3 +252,31 @@ func TestGetBranchNameForPRReference(t *testing.T) {
 		assert.Check(t, cmp.Equal(testCase.expectedBranch, outBranch))
 	}
 }
+
+func TestGetMirrorDir(t *testing.T) {
+	mirrorDir := GetMirrorDir("/mirrors", "https://github.com/loft-sh/devpod.git")
+	assert.Equal(t, mirrorDir, GetMirrorDir("/mirrors", "https://github.com/loft-sh/devpod"))
+	assert.Equal(t, mirrorDir, GetMirrorDir("/mirrors", "https://github.com/loft-sh/devpod/"))
+	assert.Assert(t, mirrorDir != GetMirrorDir("/mirrors", "https://github.com/loft-sh/vcluster"))
+	assert.Assert(t, cmp.Regexp(`^/mirrors/[0-9a-f]{16}\.git$`, mirrorDir))
+}
+
+func TestParseWorktrees(t *testing.T) {
+	worktrees := parseWorktrees(`worktree /home/user/devpod
+HEAD 8d3b5f7a3c5e0b6e8c9a1f2d4e6b8a0c2d4f6e8a
+branch refs/heads/main
+
+worktree /home/user/.devpod/contexts/default/worktrees/devpod-feat-x
+HEAD 1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b
+branch refs/heads/feat/x
+
+worktree /tmp/detached
+HEAD 1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b
+detached
+`)
+	assert.DeepEqual(t, worktrees, []Worktree{
+		{Path: "/home/user/devpod", Branch: "main"},
+		{Path: "/home/user/.devpod/contexts/default/worktrees/devpod-feat-x", Branch: "feat/x"},
+		{Path: "/tmp/detached"},
+	})
+}
@@ -0,0 +1,166 @@
+package git
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/loft-sh/log"
+)
+
+// WithReference borrows the objects of the repository in dir, e.g. a mirror, while cloning and copies the
+// borrowed objects afterwards, so the clone doesn't depend on dir.
+func WithReference(dir string) Option {
+	return func(c *cloner) {
+		c.extraArgs = append(c.extraArgs, "--reference-if-able", dir, "--dissociate")
+	}
+}
+
+// GetMirrorDir returns the folder of the mirror of the repository in mirrorsDir
+func GetMirrorDir(mirrorsDir, repository string) string {
+	hash := sha256.Sum256([]byte(strings.TrimSuffix(strings.TrimSuffix(repository, "/"), ".git")))
+	return filepath.Join(mirrorsDir, hex.EncodeToString(hash[:])[:16]+".git")
+}
+
+// mirrorRefspecs are the refs a mirror fetches, pull request refs of hosting providers would only bloat it
+var mirrorRefspecs = []string{"+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*"}
+
+// UpdateMirror creates a bare mirror of the branches and tags of the repository in mirrorDir or fetches the latest
+// changes if it exists. Concurrent updates of the same mirror wait for each other. The mirror is protected against
+// updates and pruning until the returned function is called, which should happen after cloning from it.
+func UpdateMirror(ctx context.Context, repository, mirrorDir, helper string, extraEnv []string, strictHostKeyChecking bool, log log.Logger) (func(), error) {
+	err := os.MkdirAll(filepath.Dir(mirrorDir), 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	fileLock := flock.New(mirrorDir + ".lock")
+	locked, err := fileLock.TryLockContext(ctx, time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("lock mirror: %w", err)
+	} else if !locked {
+		return nil, fmt.Errorf("lock mirror: timed out")
+	}
+	err = updateMirror(ctx, repository, mirrorDir, helper, extraEnv, strictHostKeyChecking, log)
+	_ = fileLock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	// keep the mirror from being pruned or updated while cloning from it
+	locked, err = fileLock.TryRLockContext(ctx, time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("lock mirror: %w", err)
+	} else if !locked {
+		return nil, fmt.Errorf("lock mirror: timed out")
+	}
+
+	return func() {
+		_ = fileLock.Unlock()
+	}, nil
+}
+
+func updateMirror(ctx context.Context, repository, mirrorDir, helper string, extraEnv []string, strictHostKeyChecking bool, log log.Logger) error {
+	// make sure to append the extra env so that they override existing env vars if set
+	extraEnv = append(GetDefaultExtraEnv(strictHostKeyChecking), extraEnv...)
+	run := func(args ...string) error {
+		out, err := CommandContext(ctx, extraEnv, args...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("update mirror: %w: %s", err, strings.TrimSpace(string(out)))
+		}
+
+		return nil
+	}
+
+	_, err := os.Stat(filepath.Join(mirrorDir, "HEAD"))
+	if err == nil {
+		log.Debugf("Fetching mirror %s", mirrorDir)
+	} else {
+		log.Infof("Creating mirror of %s", repository)
+		_ = os.RemoveAll(mirrorDir)
+		err = run("init", "--bare", "--quiet", mirrorDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the refspecs are set on every update, so mirrors of older versions stop fetching all refs
+	err = run("--git-dir", mirrorDir, "config", "remote.origin.url", repository)
+	if err != nil {
+		return err
+	}
+	err = run("--git-dir", mirrorDir, "config", "--replace-all", "remote.origin.fetch", mirrorRefspecs[0])
+	if err != nil {
+		return err
+	}
+	for _, refspec := range mirrorRefspecs[1:] {
+		err = run("--git-dir", mirrorDir, "config", "--add", "remote.origin.fetch", refspec)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the credential helper is only passed to the command, so it isn't stored in the mirror
+	args := []string{}
+	if helper != "" {
+		args = append(args, "-c", "credential.helper="+helper)
+	}
+	err = run(append(args, "--git-dir", mirrorDir, "fetch", "--prune", "--quiet", "origin")...)
+	if err != nil {
+		return err
+	}
+
+	// keep the mirror small, objects are only pruned after the grace period of git gc
+	out, err := CommandContext(ctx, extraEnv, "--git-dir", mirrorDir, "gc", "--auto", "--quiet").CombinedOutput()
+	if err != nil {
+		log.Debugf("Error running git gc in mirror %s: %v: %s", mirrorDir, err, strings.TrimSpace(string(out)))
+	}
+
+	// remember when the mirror was last used
+	now := time.Now()
+	return os.Chtimes(mirrorDir, now, now)
+}
+
+// PruneMirrors removes the mirrors in mirrorsDir that weren't updated within maxAge and aren't in use
+func PruneMirrors(mirrorsDir string, maxAge time.Duration, log log.Logger) {
+	entries, err := os.ReadDir(mirrorsDir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasSuffix(entry.Name(), ".git") {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < maxAge {
+			continue
+		}
+
+		// skip mirrors that are currently updated or cloned from
+		mirrorDir := filepath.Join(mirrorsDir, entry.Name())
+		fileLock := flock.New(mirrorDir + ".lock")
+		locked, err := fileLock.TryLock()
+		if err != nil || !locked {
+			continue
+		}
+
+		// the mirror could have been used since it was listed
+		info, err = os.Stat(mirrorDir)
+		if err == nil && time.Since(info.ModTime()) >= maxAge {
+			log.Debugf("Removing unused mirror %s", mirrorDir)
+			err = os.RemoveAll(mirrorDir)
+			if err != nil {
+				log.Debugf("Error removing mirror %s: %v", mirrorDir, err)
+			}
+		}
+		_ = fileLock.Unlock()
+	}
+}
@@ -0,0 +1,87 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/log"
+	"gotest.tools/assert"
+)
+
+func TestUpdateMirror(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	ctx := context.Background()
+	origin := newTestRepository(t)
+	gitCommand(t, origin, "update-ref", "refs/pull/1/head", "HEAD")
+
+	mirrorDir := GetMirrorDir(t.TempDir(), origin)
+	release, err := UpdateMirror(ctx, origin, mirrorDir, "", nil, false, log.Discard)
+	assert.NilError(t, err)
+	release()
+
+	// branches and tags are mirrored, pull request refs are not
+	refs := gitCommand(t, mirrorDir, "for-each-ref", "--format=%(refname)")
+	assert.Equal(t, refs, "refs/heads/main\nrefs/tags/v1")
+
+	// updates fetch new commits and prune deleted branches
+	gitCommand(t, origin, "commit", "--allow-empty", "-q", "-m", "second")
+	gitCommand(t, origin, "tag", "-d", "v1")
+	release, err = UpdateMirror(ctx, origin, mirrorDir, "", nil, false, log.Discard)
+	assert.NilError(t, err)
+	release()
+	assert.Equal(t, gitCommand(t, mirrorDir, "rev-parse", "refs/heads/main"), gitCommand(t, origin, "rev-parse", "HEAD"))
+	assert.Equal(t, gitCommand(t, mirrorDir, "for-each-ref", "--format=%(refname)"), "refs/heads/main")
+}
+
+func TestPruneMirrors(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	ctx := context.Background()
+	origin := newTestRepository(t)
+	mirrorsDir := t.TempDir()
+	mirrorDir := GetMirrorDir(mirrorsDir, origin)
+	release, err := UpdateMirror(ctx, origin, mirrorDir, "", nil, false, log.Discard)
+	assert.NilError(t, err)
+
+	// recently used mirrors are kept
+	PruneMirrors(mirrorsDir, time.Hour, log.Discard)
+	_, err = os.Stat(mirrorDir)
+	assert.NilError(t, err)
+
+	// mirrors that are cloned from are kept
+	old := time.Now().Add(-2 * time.Hour)
+	assert.NilError(t, os.Chtimes(mirrorDir, old, old))
+	PruneMirrors(mirrorsDir, time.Hour, log.Discard)
+	_, err = os.Stat(mirrorDir)
+	assert.NilError(t, err)
+
+	release()
+	PruneMirrors(mirrorsDir, time.Hour, log.Discard)
+	_, err = os.Stat(mirrorDir)
+	assert.Assert(t, os.IsNotExist(err))
+}
+
+func newTestRepository(t *testing.T) string {
+	dir := t.TempDir()
+	gitCommand(t, dir, "init", "-q", "-b", "main")
+	gitCommand(t, dir, "commit", "--allow-empty", "-q", "-m", "initial")
+	gitCommand(t, dir, "tag", "v1")
+	return dir
+}
+
+func gitCommand(t *testing.T, dir string, args ...string) string {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+	out, err := cmd.CombinedOutput()
+	assert.NilError(t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,94 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/loft-sh/log"
+)
+
+// Worktree is a working tree of a git repository
+type Worktree struct {
+	// Path is the folder of the working tree
+	Path string
+
+	// Branch is the branch checked out in the working tree, empty if HEAD is detached
+	Branch string
+}
+
+// ListWorktrees returns the working trees of the repository in repoDir. The main working tree is always the first one.
+func ListWorktrees(ctx context.Context, repoDir string) ([]Worktree, error) {
+	gitCommand := CommandContext(ctx, nil, "worktree", "list", "--porcelain")
+	gitCommand.Dir = repoDir
+	out, err := gitCommand.Output()
+	if err != nil {
+		return nil, fmt.Errorf("list worktrees of %s: %w", repoDir, err)
+	}
+
+	return parseWorktrees(string(out)), nil
+}
+
+func parseWorktrees(out string) []Worktree {
+	worktrees := []Worktree{}
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "worktree ") {
+			worktrees = append(worktrees, Worktree{Path: filepath.FromSlash(strings.TrimPrefix(line, "worktree "))})
+		} else if strings.HasPrefix(line, "branch ") && len(worktrees) > 0 {
+			worktrees[len(worktrees)-1].Branch = strings.TrimPrefix(strings.TrimPrefix(line, "branch "), "refs/heads/")
+		}
+	}
+
+	return worktrees
+}
+
+// AddWorktree checks out branch in a new working tree at dir. A branch that doesn't exist locally is created from the
+// remote branch with the same name or from HEAD if there is none.
+func AddWorktree(ctx context.Context, repoDir, dir, branch string, log log.Logger) error {
+	args := []string{"worktree", "add", dir, branch}
+	if !refExists(ctx, repoDir, "refs/heads/"+branch) && !remoteBranchExists(ctx, repoDir, branch) {
+		log.Infof("Creating branch %s from HEAD", branch)
+		args = []string{"worktree", "add", "-b", branch, dir}
+	}
+
+	gitCommand := CommandContext(ctx, nil, args...)
+	gitCommand.Dir = repoDir
+	out, err := gitCommand.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("add worktree for branch %s: %w: %s", branch, err, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+// RemoveWorktree removes the working tree at dir. Working trees with changes are only removed with force.
+func RemoveWorktree(ctx context.Context, repoDir, dir string, force bool) error {
+	args := []string{"worktree", "remove", dir}
+	if force {
+		args = append(args, "--force")
+	}
+
+	gitCommand := CommandContext(ctx, nil, args...)
+	gitCommand.Dir = repoDir
+	out, err := gitCommand.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("remove worktree %s: %w: %s", dir, err, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+func refExists(ctx context.Context, repoDir, ref string) bool {
+	gitCommand := CommandContext(ctx, nil, "rev-parse", "--verify", "--quiet", ref)
+	gitCommand.Dir = repoDir
+	return gitCommand.Run() == nil
+}
+
+func remoteBranchExists(ctx context.Context, repoDir, branch string) bool {
+	gitCommand := CommandContext(ctx, nil, "for-each-ref", "--format=%(refname)", "refs/remotes/*/"+branch)
+	gitCommand.Dir = repoDir
+	out, err := gitCommand.Output()
+	return err == nil && strings.TrimSpace(string(out)) != ""
+}
@@ -0,0 +1,60 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// NewLineWriter returns a writer that only writes complete lines to out and prepends the prefix to each of them.
+// Writers that share the same mutex can write to out concurrently without mixing up their lines.
+func NewLineWriter(out io.Writer, m *sync.Mutex, prefix string) io.WriteCloser {
+	return &lineWriter{
+		out:    out,
+		m:      m,
+		prefix: []byte(prefix),
+	}
+}
+
+type lineWriter struct {
+	out    io.Writer
+	m      *sync.Mutex
+	prefix []byte
+
+	buffer []byte
+}
+
+func (l *lineWriter) Write(p []byte) (int, error) {
+	l.buffer = append(l.buffer, p...)
+	for {
+		index := bytes.IndexByte(l.buffer, '\n')
+		if index == -1 {
+			return len(p), nil
+		}
+
+		err := l.writeLine(l.buffer[:index+1])
+		l.buffer = l.buffer[index+1:]
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Close writes the remaining incomplete line
+func (l *lineWriter) Close() error {
+	if len(l.buffer) == 0 {
+		return nil
+	}
+
+	line := append(l.buffer, '\n')
+	l.buffer = nil
+	return l.writeLine(line)
+}
+
+func (l *lineWriter) writeLine(line []byte) error {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	_, err := l.out.Write(append(append([]byte{}, l.prefix...), line...))
+	return err
+}
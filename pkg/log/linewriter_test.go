@@ -0,0 +1,29 @@
+package log
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestLineWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	m := &sync.Mutex{}
+	container := NewLineWriter(out, m, "")
+	hooks := NewLineWriter(out, m, "[lifecycle] ")
+
+	_, err := container.Write([]byte("starting"))
+	assert.NilError(t, err)
+	_, err = hooks.Write([]byte("npm install\nadded 10 "))
+	assert.NilError(t, err)
+	_, err = container.Write([]byte(" server\n"))
+	assert.NilError(t, err)
+	_, err = hooks.Write([]byte("packages"))
+	assert.NilError(t, err)
+	assert.NilError(t, hooks.Close())
+	assert.NilError(t, container.Close())
+
+	assert.Equal(t, out.String(), "[lifecycle] npm install\nstarting server\n[lifecycle] added 10 packages\n")
+}
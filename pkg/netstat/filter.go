@@ -0,0 +1,46 @@
+package netstat
+
+import "github.com/loft-sh/devpod/pkg/port"
+
+const (
+	DefaultPortRangeStart = 1024
+	DefaultPortRangeEnd   = 12000
+)
+
+// PortFilter decides which of the discovered ports are forwarded automatically
+type PortFilter struct {
+	// Range are the ports that are forwarded if Allow is empty
+	Range port.Range
+
+	// Exclude are ports that are never forwarded
+	Exclude []port.Range
+
+	// Allow replaces Range with an explicit list of ports to forward
+	Allow []port.Range
+}
+
+// DefaultPortFilter forwards all ports within 1024-12000
+func DefaultPortFilter() PortFilter {
+	return PortFilter{
+		Range: port.Range{Start: DefaultPortRangeStart, End: DefaultPortRangeEnd},
+	}
+}
+
+func (p PortFilter) Matches(portNumber int) bool {
+	for _, excluded := range p.Exclude {
+		if excluded.Contains(portNumber) {
+			return false
+		}
+	}
+
+	if len(p.Allow) == 0 {
+		return p.Range.Contains(portNumber)
+	}
+	for _, allowed := range p.Allow {
+		if allowed.Contains(portNumber) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,35 @@
+package netstat
+
+import (
+	"testing"
+
+	"github.com/loft-sh/devpod/pkg/port"
+	"gotest.tools/assert"
+)
+
+func TestPortFilter(t *testing.T) {
+	exclude, err := port.ParseRanges([]string{"5432,6000-6010"})
+	assert.NilError(t, err)
+	allow, err := port.ParseRanges([]string{"15000-16000", "80"})
+	assert.NilError(t, err)
+
+	tests := []struct {
+		name   string
+		filter PortFilter
+		port   int
+		want   bool
+	}{
+		{name: "default range", filter: DefaultPortFilter(), port: 8080, want: true},
+		{name: "outside default range", filter: DefaultPortFilter(), port: 15000, want: false},
+		{name: "excluded", filter: PortFilter{Range: port.Range{Start: 1024, End: 12000}, Exclude: exclude}, port: 6005, want: false},
+		{name: "allowed outside range", filter: PortFilter{Range: port.Range{Start: 1024, End: 12000}, Allow: allow}, port: 80, want: true},
+		{name: "not allowed within range", filter: PortFilter{Range: port.Range{Start: 1024, End: 12000}, Allow: allow}, port: 8080, want: false},
+		{name: "excluded and allowed", filter: PortFilter{Exclude: exclude, Allow: []port.Range{{Start: 5000, End: 6000}}}, port: 5432, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.filter.Matches(tt.port), tt.want)
+		})
+	}
+}
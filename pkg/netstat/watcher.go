@@ -3,6 +3,7 @@ package netstat
 import (
 	"context"
 	"fmt"
+	"net"
 	"strconv"
 	"time"
 
@@ -14,10 +15,12 @@ type Forwarder interface {
 	StopForward(port string) error
 }
 
-func NewWatcher(forwarder Forwarder, log log.Logger) *Watcher {
+func NewWatcher(forwarder Forwarder, filter PortFilter, log log.Logger) *Watcher {
 	return &Watcher{
 		forwarder:      forwarder,
+		filter:         filter,
 		forwardedPorts: map[string]bool{},
+		udpPorts:       map[string]bool{},
 		log:            log,
 	}
 }
@@ -26,7 +29,11 @@ type Watcher struct {
 	log log.Logger
 
 	forwarder      Forwarder
+	filter         PortFilter
 	forwardedPorts map[string]bool
+
+	// udpPorts are the found udp listeners, which can't be forwarded over ssh
+	udpPorts map[string]bool
 }
 
 func (w *Watcher) Run(ctx context.Context) error {
@@ -66,7 +73,8 @@ func (w *Watcher) runOnce() error {
 			w.log.Debugf("Found open port %s ready to forward", port)
 			err = w.forwarder.Forward(port)
 			if err != nil {
-				return fmt.Errorf("error forwarding port %s: %w", port, err)
+				// don't retry the port until it was closed, e.g. if its local port is required but taken
+				w.log.Errorf("Error forwarding port %s: %v", port, err)
 			}
 		}
 	}
@@ -75,6 +83,8 @@ func (w *Watcher) runOnce() error {
 	return nil
 }
 
+// findPorts returns the listening tcp ports that match the filter. Ports bound to a specific interface
+// are returned as ip:port, all other ports only as the port number.
 func (w *Watcher) findPorts() (map[string]bool, error) {
 	tcpSocks, err := TCPSocks(func(s *SockTabEntry) bool {
 		return s.State == Listen
@@ -91,15 +101,57 @@ func (w *Watcher) findPorts() (map[string]bool, error) {
 	}
 	tcpSocks = append(tcpSocks, tcp6Socks...)
 
-	// we only return ports that are within range 1024-12000 that have a program assigned
 	retSocks := map[string]bool{}
 	for _, sock := range tcpSocks {
-		if sock.LocalAddr.Port < 1024 || sock.LocalAddr.Port > 12000 || sock.LocalAddr == nil {
+		if sock.LocalAddr == nil || !w.filter.Matches(int(sock.LocalAddr.Port)) {
 			continue
 		}
 
-		retSocks[strconv.Itoa(int(sock.LocalAddr.Port))] = true
+		retSocks[portKey(sock.LocalAddr)] = true
 	}
 
+	w.findUDPPorts()
 	return retSocks, nil
 }
+
+// findUDPPorts logs newly found udp listeners once, as ssh is only able to forward tcp
+func (w *Watcher) findUDPPorts() {
+	// unconnected udp sockets have no remote port
+	isListener := func(s *SockTabEntry) bool {
+		return s.RemoteAddr != nil && s.RemoteAddr.Port == 0
+	}
+	udpSocks, err := UDPSocks(isListener)
+	if err != nil {
+		w.log.Debugf("Error finding udp ports: %v", err)
+		return
+	}
+	udp6Socks, err := UDP6Socks(isListener)
+	if err != nil {
+		w.log.Debugf("Error finding udp ports: %v", err)
+		return
+	}
+
+	udpPorts := map[string]bool{}
+	for _, sock := range append(udpSocks, udp6Socks...) {
+		if sock.LocalAddr == nil || !w.filter.Matches(int(sock.LocalAddr.Port)) {
+			continue
+		}
+
+		port := portKey(sock.LocalAddr)
+		if !w.udpPorts[port] {
+			w.log.Infof("Found udp port %s, which can't be forwarded automatically", port)
+		}
+		udpPorts[port] = true
+	}
+
+	w.udpPorts = udpPorts
+}
+
+func portKey(addr *SockAddr) string {
+	port := strconv.Itoa(int(addr.Port))
+	if addr.IP == nil || addr.IP.IsUnspecified() || addr.IP.IsLoopback() {
+		return port
+	}
+
+	return net.JoinHostPort(addr.IP.String(), port)
+}
@@ -228,6 +228,15 @@ func ResolveAgentConfig(devConfig *config.Config, provider *provider2.ProviderCo
 	agentConfig.Dockerless.RegistryCache = devConfig.ContextOption(config.ContextOptionRegistryCache)
 	agentConfig.Driver = resolver.ResolveDefaultValue(agentConfig.Driver, options)
 	agentConfig.Local = types.StrBool(resolver.ResolveDefaultValue(string(agentConfig.Local), options))
+	agentConfig.GitMirror.Disabled = types.StrBool(resolver.ResolveDefaultValue(string(agentConfig.GitMirror.Disabled), options))
+	// mirrors only pay off on machines that are shared by several workspaces
+	if devConfig.ContextOption(config.ContextOptionGitMirrorCache) == "false" || !provider.IsMachineProvider() {
+		agentConfig.GitMirror.Disabled = "true"
+	}
+	agentConfig.GitMirror.MaxAge = resolver.ResolveDefaultValue(agentConfig.GitMirror.MaxAge, options)
+	if agentConfig.GitMirror.MaxAge == "" {
+		agentConfig.GitMirror.MaxAge = devConfig.ContextOption(config.ContextOptionGitMirrorCacheMaxAge)
+	}
 
 	// docker driver
 	agentConfig.Docker.Path = resolver.ResolveDefaultValue(agentConfig.Docker.Path, options)
@@ -235,6 +244,11 @@ func ResolveAgentConfig(devConfig *config.Config, provider *provider2.ProviderCo
 	agentConfig.Docker.Install = types.StrBool(resolver.ResolveDefaultValue(string(agentConfig.Docker.Install), options))
 	agentConfig.Docker.Env = resolver.ResolveDefaultValues(agentConfig.Docker.Env, options)
 
+	// podman driver
+	agentConfig.Podman.Path = resolver.ResolveDefaultValue(agentConfig.Podman.Path, options)
+	agentConfig.Podman.Builder = resolver.ResolveDefaultValue(agentConfig.Podman.Builder, options)
+	agentConfig.Podman.Env = resolver.ResolveDefaultValues(agentConfig.Podman.Env, options)
+
 	// kubernetes driver
 	agentConfig.Kubernetes.KubernetesContext = resolver.ResolveDefaultValue(agentConfig.Kubernetes.KubernetesContext, options)
 	agentConfig.Kubernetes.KubernetesConfig = resolver.ResolveDefaultValue(agentConfig.Kubernetes.KubernetesConfig, options)
@@ -247,6 +261,7 @@ func ResolveAgentConfig(devConfig *config.Config, provider *provider2.ProviderCo
 	agentConfig.Kubernetes.NodeSelector = resolver.ResolveDefaultValue(agentConfig.Kubernetes.NodeSelector, options)
 	agentConfig.Kubernetes.Resources = resolver.ResolveDefaultValue(agentConfig.Kubernetes.Resources, options)
 	agentConfig.Kubernetes.WorkspaceVolumeMount = resolver.ResolveDefaultValue(agentConfig.Kubernetes.WorkspaceVolumeMount, options)
+	agentConfig.Kubernetes.ExtraMounts = resolver.ResolveDefaultValue(agentConfig.Kubernetes.ExtraMounts, options)
 	agentConfig.Kubernetes.PodManifestTemplate = resolver.ResolveDefaultValue(agentConfig.Kubernetes.PodManifestTemplate, options)
 	agentConfig.Kubernetes.Labels = resolver.ResolveDefaultValue(agentConfig.Kubernetes.Labels, options)
 	agentConfig.Kubernetes.StrictSecurity = resolver.ResolveDefaultValue(agentConfig.Kubernetes.StrictSecurity, options)
@@ -256,6 +271,8 @@ func ResolveAgentConfig(devConfig *config.Config, provider *provider2.ProviderCo
 	agentConfig.Kubernetes.PodTimeout = resolver.ResolveDefaultValue(agentConfig.Kubernetes.PodTimeout, options)
 	agentConfig.Kubernetes.KubernetesPullSecretsEnabled = resolver.ResolveDefaultValue(agentConfig.Kubernetes.KubernetesPullSecretsEnabled, options)
 	agentConfig.Kubernetes.DiskSize = resolver.ResolveDefaultValue(agentConfig.Kubernetes.DiskSize, options)
+	agentConfig.Kubernetes.NativeConnect = resolver.ResolveDefaultValue(agentConfig.Kubernetes.NativeConnect, options)
+	agentConfig.Kubernetes.PoolSize = resolver.ResolveDefaultValue(agentConfig.Kubernetes.PoolSize, options)
 
 	agentConfig.DataPath = resolver.ResolveDefaultValue(agentConfig.DataPath, options)
 	agentConfig.Path = resolver.ResolveDefaultValue(agentConfig.Path, options)
@@ -0,0 +1,31 @@
+// Package passwd parses the /etc/passwd file of containers and images
+package passwd
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// LookupUser finds the uid and gid of a user name or uid in an /etc/passwd file. Numeric users don't need to
+// exist in the file and use their uid as gid.
+func LookupUser(passwd io.Reader, user string) (string, string, bool) {
+	scanner := bufio.NewScanner(passwd)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) < 4 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		if fields[0] == user || fields[2] == user {
+			return fields[2], fields[3], true
+		}
+	}
+
+	if _, err := strconv.Atoi(user); err == nil {
+		return user, user, true
+	}
+
+	return "", "", false
+}
@@ -0,0 +1,30 @@
+package passwd
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestLookupUser(t *testing.T) {
+	passwd := `root:x:0:0:root:/root:/bin/bash
+# comment
+vscode:x:1000:1001:,,,:/home/vscode:/bin/bash
+`
+	uid, gid, ok := LookupUser(strings.NewReader(passwd), "vscode")
+	assert.Assert(t, ok)
+	assert.Equal(t, uid, "1000")
+	assert.Equal(t, gid, "1001")
+
+	uid, gid, ok = LookupUser(strings.NewReader(passwd), "1000")
+	assert.Assert(t, ok)
+	assert.Equal(t, uid+":"+gid, "1000:1001")
+
+	uid, gid, ok = LookupUser(strings.NewReader(passwd), "2000")
+	assert.Assert(t, ok)
+	assert.Equal(t, uid+":"+gid, "2000:2000")
+
+	_, _, ok = LookupUser(strings.NewReader(passwd), "node")
+	assert.Assert(t, !ok)
+}
@@ -8,7 +8,7 @@ import (
 )
 
 func FindAvailablePort(start int) (int, error) {
-	for i := start; i < start+1000; i++ {
+	for i := start; i < start+1000 && i <= 65535; i++ {
 		available, err := IsAvailable("localhost:" + strconv.Itoa(i))
 		if err != nil {
 			return 0, err
@@ -0,0 +1,12 @@
+package port
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestFindAvailablePortOutOfRange(t *testing.T) {
+	_, err := FindAvailablePort(65536)
+	assert.ErrorContains(t, err, "couldn't find an available port")
+}
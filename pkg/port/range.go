@@ -0,0 +1,67 @@
+package port
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Range is an inclusive range of ports, a single port has the same start and end
+type Range struct {
+	Start int
+	End   int
+}
+
+func (r Range) Contains(port int) bool {
+	return port >= r.Start && port <= r.End
+}
+
+func (r Range) String() string {
+	if r.Start == r.End {
+		return strconv.Itoa(r.Start)
+	}
+
+	return fmt.Sprintf("%d-%d", r.Start, r.End)
+}
+
+// ParseRange parses a single port such as 8080 or a range such as 3000-3010
+func ParseRange(str string) (Range, error) {
+	start, end, isRange := strings.Cut(strings.TrimSpace(str), "-")
+	if !isRange {
+		end = start
+	}
+
+	startPort, err := strconv.Atoi(strings.TrimSpace(start))
+	if err != nil {
+		return Range{}, fmt.Errorf("invalid port range %s", str)
+	}
+	endPort, err := strconv.Atoi(strings.TrimSpace(end))
+	if err != nil {
+		return Range{}, fmt.Errorf("invalid port range %s", str)
+	} else if startPort < 1 || endPort > 65535 || startPort > endPort {
+		return Range{}, fmt.Errorf("invalid port range %s", str)
+	}
+
+	return Range{Start: startPort, End: endPort}, nil
+}
+
+// ParseRanges parses a list of ports and port ranges, each entry may also be comma separated
+func ParseRanges(strs []string) ([]Range, error) {
+	ranges := []Range{}
+	for _, str := range strs {
+		for _, entry := range strings.Split(str, ",") {
+			if strings.TrimSpace(entry) == "" {
+				continue
+			}
+
+			portRange, err := ParseRange(entry)
+			if err != nil {
+				return nil, err
+			}
+
+			ranges = append(ranges, portRange)
+		}
+	}
+
+	return ranges, nil
+}
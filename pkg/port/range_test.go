@@ -0,0 +1,25 @@
+package port
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestParseRange(t *testing.T) {
+	portRange, err := ParseRange(" 3000-3010 ")
+	assert.NilError(t, err)
+	assert.Equal(t, portRange, Range{Start: 3000, End: 3010})
+	assert.Equal(t, portRange.String(), "3000-3010")
+
+	_, err = ParseRange("3010-3000")
+	assert.ErrorContains(t, err, "invalid port range")
+	_, err = ParseRange("abc")
+	assert.ErrorContains(t, err, "invalid port range")
+}
+
+func TestParseRanges(t *testing.T) {
+	ranges, err := ParseRanges([]string{"5432, 6000-6010", "", "80"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, ranges, []Range{{Start: 5432, End: 5432}, {Start: 6000, End: 6010}, {Start: 80, End: 80}})
+}
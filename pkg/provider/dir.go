@@ -60,6 +60,15 @@ func GetWorkspacesDir(context string) (string, error) {
 	return filepath.Join(configDir, "contexts", context, "workspaces"), nil
 }
 
+func GetWorktreesDir(context string) (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "contexts", context, "worktrees"), nil
+}
+
 func GetProvidersDir(context string) (string, error) {
 	configDir, err := config.GetConfigDir()
 	if err != nil {
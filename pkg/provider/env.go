@@ -34,6 +34,10 @@ const (
 	MACHINE_FOLDER   = "MACHINE_FOLDER"
 	MACHINE_PROVIDER = "MACHINE_PROVIDER"
 
+	// resize
+	RESIZE_CPUS   = "RESIZE_CPUS"
+	RESIZE_MEMORY = "RESIZE_MEMORY"
+
 	// provider
 	PROVIDER_ID      = "PROVIDER_ID"
 	PROVIDER_CONTEXT = "PROVIDER_CONTEXT"
@@ -46,6 +50,12 @@ const (
 
 const (
 	DEVCONTAINER_ID = "DEVCONTAINER_ID"
+
+	// logs
+	DEVCONTAINER_LOGS_FOLLOW     = "DEVCONTAINER_LOGS_FOLLOW"
+	DEVCONTAINER_LOGS_SINCE      = "DEVCONTAINER_LOGS_SINCE"
+	DEVCONTAINER_LOGS_TAIL       = "DEVCONTAINER_LOGS_TAIL"
+	DEVCONTAINER_LOGS_TIMESTAMPS = "DEVCONTAINER_LOGS_TIMESTAMPS"
 )
 
 func combineOptions(resolvedOptions map[string]config.OptionValue, otherOptions map[string]config.OptionValue) map[string]config.OptionValue {
@@ -197,12 +197,12 @@ func validateProviderType(config *ProviderConfig) error {
 	}
 
 	// validate driver
-	if config.Agent.Driver != "" && config.Agent.Driver != CustomDriver && config.Agent.Driver != DockerDriver && config.Agent.Driver != KubernetesDriver {
-		return fmt.Errorf("agent.driver can only be docker, kubernetes or custom")
+	if config.Agent.Driver != "" && config.Agent.Driver != CustomDriver && config.Agent.Driver != DockerDriver && config.Agent.Driver != PodmanDriver && config.Agent.Driver != KubernetesDriver {
+		return fmt.Errorf("agent.driver can only be docker, podman, kubernetes or custom")
 	}
 
 	// validate custom driver
-	if config.Agent.Driver == CustomDriver {
+	if config.Agent.Driver == CustomDriver && len(config.Agent.Custom.Plugin) == 0 {
 		if len(config.Agent.Custom.TargetArchitecture) == 0 {
 			return fmt.Errorf("agent.custom.targetArchitecture is required")
 		}
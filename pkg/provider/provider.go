@@ -112,13 +112,19 @@ type ProviderAgentConfig struct {
 	// Dockerless holds custom dockerless configuration
 	Dockerless ProviderDockerlessOptions `json:"dockerless,omitempty"`
 
+	// GitMirror holds the configuration of the git mirror cache the agent shares between workspaces
+	GitMirror ProviderGitMirrorOptions `json:"gitMirror,omitempty"`
+
 	// Driver is the driver to use for deploying the devcontainer. Currently supports
-	// docker (default) or kubernetes (experimental)
+	// docker (default), podman or kubernetes (experimental)
 	Driver string `json:"driver,omitempty"`
 
 	// Docker holds docker specific configuration
 	Docker ProviderDockerDriverConfig `json:"docker,omitempty"`
 
+	// Podman holds podman specific configuration
+	Podman ProviderPodmanDriverConfig `json:"podman,omitempty"`
+
 	// Custom holds custom driver specific configuration
 	Custom ProviderCustomDriverConfig `json:"custom,omitempty"`
 
@@ -143,17 +149,30 @@ type ProviderDockerlessOptions struct {
 	DisableDockerCredentials types.StrBool `json:"disableDockerCredentials,omitempty"`
 }
 
+type ProviderGitMirrorOptions struct {
+	// Disabled signals if git repositories are cloned without a local mirror
+	Disabled types.StrBool `json:"disabled,omitempty"`
+
+	// MaxAge is the duration after which unused mirrors are removed, e.g. 720h
+	MaxAge string `json:"maxAge,omitempty"`
+}
+
 func (a ProviderAgentConfig) IsDockerDriver() bool {
 	return a.Driver == "" || a.Driver == DockerDriver
 }
 
 const (
 	DockerDriver     = "docker"
+	PodmanDriver     = "podman"
 	KubernetesDriver = "kubernetes"
 	CustomDriver     = "custom"
 )
 
 type ProviderCustomDriverConfig struct {
+	// Plugin is started once and answers all driver calls over the plugin protocol on its stdin and stdout.
+	// If set, the other commands are ignored.
+	Plugin types.StrArray `json:"plugin,omitempty"`
+
 	// FindDevContainer is used to find an existing devcontainer
 	FindDevContainer types.StrArray `json:"findDevContainer,omitempty"`
 
@@ -182,6 +201,17 @@ type ProviderCustomDriverConfig struct {
 	GetDevContainerLogs types.StrArray `json:"getDevContainerLogs,omitempty"`
 }
 
+type ProviderPodmanDriverConfig struct {
+	// Path where to find the podman binary, defaults to 'podman'
+	Path string `json:"path,omitempty"`
+
+	// Builder to use with podman
+	Builder string `json:"builder,omitempty"`
+
+	// Environment variables to set when running podman commands
+	Env map[string]string `json:"env,omitempty"`
+}
+
 type ProviderDockerDriverConfig struct {
 	// Path where to find the docker binary, defaults to 'docker'
 	Path string `json:"path,omitempty"`
@@ -217,11 +247,18 @@ type ProviderKubernetesDriverConfig struct {
 	NodeSelector         string `json:"nodeSelector,omitempty"`
 	Resources            string `json:"resources,omitempty"`
 	WorkspaceVolumeMount string `json:"workspaceVolumeMount,omitempty"`
+	ExtraMounts          string `json:"extraMounts,omitempty"`
 
 	PodManifestTemplate string `json:"podManifestTemplate,omitempty"`
 	Labels              string `json:"labels,omitempty"`
 
 	StrictSecurity string `json:"strictSecurity,omitempty"`
+
+	// NativeConnect makes the cli use the kubernetes port-forward and exec apis instead of ssh where possible
+	NativeConnect string `json:"nativeConnect,omitempty"`
+
+	// PoolSize is the number of idle pods to keep warm per image and pod template
+	PoolSize string `json:"poolSize,omitempty"`
 }
 
 type ProviderAgentConfigExec struct {
@@ -269,6 +306,9 @@ type ProviderCommands struct {
 	// Stop stops a running server
 	Stop types.StrArray `json:"stop,omitempty"`
 
+	// Resize changes the cpus and memory of a server
+	Resize types.StrArray `json:"resize,omitempty"`
+
 	// Status retrieves the server status
 	Status types.StrArray `json:"status,omitempty"`
 
@@ -124,9 +124,15 @@ type WorkspaceSource struct {
 	// GitSubPath is the subpath in the repo to use
 	GitSubPath string `json:"gitSubDir,omitempty"`
 
+	// GitSparsePaths are the directories of the repo to check out, all directories are checked out if empty
+	GitSparsePaths []string `json:"gitSparsePaths,omitempty"`
+
 	// LocalFolder is the local folder to use
 	LocalFolder string `json:"localFolder,omitempty"`
 
+	// GitWorktreeRepository is the local repository if LocalFolder is one of its git worktrees
+	GitWorktreeRepository string `json:"gitWorktreeRepository,omitempty"`
+
 	// Image is the docker image to use
 	Image string `json:"image,omitempty"`
 
@@ -195,6 +201,9 @@ type AgentWorkspaceInfo struct {
 
 	// RegistryCache defines the registry to use for caching builds
 	RegistryCache string `json:"registryCache,omitempty"`
+
+	// HostRequirements defines if unmet host requirements should warn or fail
+	HostRequirements string `json:"hostRequirements,omitempty"`
 }
 
 type CLIOptions struct {
@@ -218,6 +227,7 @@ type CLIOptions struct {
 	DaemonInterval              string            `json:"daemonInterval,omitempty"`
 	GitCloneStrategy            git.CloneStrategy `json:"gitCloneStrategy,omitempty"`
 	GitCloneRecursiveSubmodules bool              `json:"gitCloneRecursive,omitempty"`
+	GitSparsePaths              []string          `json:"gitSparsePaths,omitempty"`
 	FallbackImage               string            `json:"fallbackImage,omitempty"`
 	GitSSHSigningKey            string            `json:"gitSshSigningKey,omitempty"`
 	SSHAuthSockID               string            `json:"sshAuthSockID,omitempty"` // ID to use when looking for SSH_AUTH_SOCK, defaults to a new random ID if not set (only used for browser IDEs)
@@ -229,6 +239,8 @@ type CLIOptions struct {
 	Platforms  []string `json:"platform,omitempty"`
 	Tag        []string `json:"tag,omitempty"`
 
+	UpgradeLockfile bool `json:"upgradeLockfile,omitempty"`
+
 	ForceBuild            bool `json:"forceBuild,omitempty"`
 	ForceDockerless       bool `json:"forceDockerless,omitempty"`
 	ForceInternalBuildKit bool `json:"forceInternalBuildKit,omitempty"`
@@ -0,0 +1,395 @@
+// Package remotecopy copies files and folders between the local machine and the container of a workspace via sftp
+package remotecopy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/loft-sh/devpod/pkg/passwd"
+	"github.com/loft-sh/log"
+	"github.com/pkg/sftp"
+)
+
+// Options holds the options of a copy
+type Options struct {
+	// Recursive allows to copy folders
+	Recursive bool
+
+	// User owns the files copied into the container, they keep the owner of the sftp server if empty
+	User string
+}
+
+// ParsePath splits a path in the format workspace:path into the workspace and the path in it. Paths without a
+// workspace are local, a single letter before the colon is treated as a windows drive.
+func ParsePath(arg string) (workspace string, remotePath string, isRemote bool) {
+	idx := strings.Index(arg, ":")
+	if idx <= 1 || strings.ContainsAny(arg[:idx], `/\`) {
+		return "", arg, false
+	}
+
+	return arg[:idx], arg[idx+1:], true
+}
+
+// Upload copies the local file or folder src to dst in the container. If dst is an existing folder, src is
+// copied into it.
+func Upload(ctx context.Context, client *sftp.Client, src, dst string, options Options, log log.Logger) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	} else if info.IsDir() && !options.Recursive {
+		return fmt.Errorf("%s is a folder, please use --recursive to copy folders", src)
+	}
+
+	dstInfo, err := client.Stat(dst)
+	if err == nil && dstInfo.IsDir() {
+		dst = path.Join(dst, filepath.Base(src))
+	}
+
+	c := &copier{client: client, log: log, start: time.Now()}
+	c.uid, c.gid = lookupUser(client, options.User, log)
+	err = filepath.WalkDir(src, func(localPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		} else if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		relPath, err := filepath.Rel(src, localPath)
+		if err != nil {
+			return err
+		}
+		remotePath := path.Join(dst, filepath.ToSlash(relPath))
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if localPath == src {
+			// the source itself is followed if it's a symlink
+			info, err = os.Stat(src)
+			if err != nil {
+				return err
+			}
+		}
+
+		return c.upload(localPath, remotePath, info)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = c.applyFolders(func(folder folder) error {
+		err := c.client.Chmod(folder.path, folder.info.Mode().Perm())
+		if err != nil {
+			return fmt.Errorf("change permissions of %s: %w", folder.path, err)
+		}
+
+		return c.client.Chtimes(folder.path, folder.info.ModTime(), folder.info.ModTime())
+	})
+	if err != nil {
+		return err
+	}
+
+	c.done()
+	return nil
+}
+
+// Download copies the file or folder src in the container to the local dst. If dst is an existing folder, src is
+// copied into it.
+func Download(ctx context.Context, client *sftp.Client, src, dst string, options Options, log log.Logger) error {
+	realPath, err := client.RealPath(src)
+	if err != nil {
+		return fmt.Errorf("%s: %w", src, err)
+	}
+	src = realPath
+
+	info, err := client.Stat(src)
+	if err != nil {
+		return fmt.Errorf("%s: %w", src, err)
+	} else if info.IsDir() && !options.Recursive {
+		return fmt.Errorf("%s is a folder, please use --recursive to copy folders", src)
+	}
+
+	dstInfo, err := os.Stat(dst)
+	if err == nil && dstInfo.IsDir() {
+		dst = filepath.Join(dst, path.Base(src))
+	}
+
+	c := &copier{client: client, log: log, start: time.Now()}
+	walker := client.Walk(src)
+	for walker.Step() {
+		if walker.Err() != nil {
+			return walker.Err()
+		} else if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		relPath := strings.TrimPrefix(strings.TrimPrefix(walker.Path(), src), "/")
+		localPath := filepath.Join(dst, filepath.FromSlash(relPath))
+		info := walker.Stat()
+		if walker.Path() == src {
+			// the source itself is followed if it's a symlink
+			info, err = client.Stat(src)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = c.download(walker.Path(), localPath, info)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.applyFolders(func(folder folder) error {
+		err := os.Chmod(folder.path, folder.info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+
+		return os.Chtimes(folder.path, folder.info.ModTime(), folder.info.ModTime())
+	})
+	if err != nil {
+		return err
+	}
+
+	c.done()
+	return nil
+}
+
+type copier struct {
+	client *sftp.Client
+	log    log.Logger
+
+	// uid and gid own the files copied into the container, -1 to keep the owner of the sftp server
+	uid int
+	gid int
+
+	// folders are copied with default permissions, their permissions and modification times are applied after
+	// their contents, so read-only folders can be filled and the modification times aren't changed by their files
+	folders []folder
+
+	start time.Time
+	files int
+	bytes int64
+}
+
+type folder struct {
+	path string
+	info fs.FileInfo
+}
+
+// applyFolders applies the attributes of the copied folders, children before their parents
+func (c *copier) applyFolders(apply func(folder folder) error) error {
+	for i := len(c.folders) - 1; i >= 0; i-- {
+		err := apply(c.folders[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *copier) upload(localPath, remotePath string, info fs.FileInfo) error {
+	switch {
+	case info.IsDir():
+		err := c.client.Mkdir(remotePath)
+		if err != nil {
+			if remoteInfo, statErr := c.client.Stat(remotePath); statErr != nil || !remoteInfo.IsDir() {
+				return fmt.Errorf("create folder %s: %w", remotePath, err)
+			}
+		}
+
+		c.folders = append(c.folders, folder{path: remotePath, info: info})
+		c.chown(remotePath)
+		return nil
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(localPath)
+		if err != nil {
+			return err
+		}
+
+		_ = c.client.Remove(remotePath)
+		err = c.client.Symlink(filepath.ToSlash(target), remotePath)
+		if err != nil {
+			return fmt.Errorf("create symlink %s: %w", remotePath, err)
+		}
+
+		return nil
+	case info.Mode().IsRegular():
+		src, err := os.Open(localPath)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		dst, err := c.client.OpenFile(remotePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+		if err != nil {
+			return fmt.Errorf("create %s: %w", remotePath, err)
+		}
+		defer dst.Close()
+
+		_, err = io.Copy(dst, c.progressReader(src, localPath, info.Size()))
+		if err != nil {
+			return fmt.Errorf("copy %s: %w", localPath, err)
+		}
+	default:
+		c.log.Debugf("Skip special file %s", localPath)
+		return nil
+	}
+
+	c.chown(remotePath)
+	err := c.client.Chmod(remotePath, info.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("change permissions of %s: %w", remotePath, err)
+	}
+
+	return c.client.Chtimes(remotePath, info.ModTime(), info.ModTime())
+}
+
+func (c *copier) chown(remotePath string) {
+	if c.uid < 0 {
+		return
+	}
+
+	err := c.client.Chown(remotePath, c.uid, c.gid)
+	if err != nil {
+		c.log.Warnf("Error changing owner of %s: %v", remotePath, err)
+	}
+}
+
+func (c *copier) download(remotePath, localPath string, info fs.FileInfo) error {
+	switch {
+	case info.IsDir():
+		err := os.MkdirAll(localPath, 0755)
+		if err != nil {
+			return err
+		}
+
+		c.folders = append(c.folders, folder{path: localPath, info: info})
+		return nil
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := c.client.ReadLink(remotePath)
+		if err != nil {
+			return err
+		}
+
+		_ = os.Remove(localPath)
+		err = os.Symlink(filepath.FromSlash(target), localPath)
+		if err != nil {
+			return fmt.Errorf("create symlink %s: %w", localPath, err)
+		}
+
+		return nil
+	case info.Mode().IsRegular():
+		src, err := c.client.Open(remotePath)
+		if err != nil {
+			return fmt.Errorf("open %s: %w", remotePath, err)
+		}
+		defer src.Close()
+
+		dst, err := os.OpenFile(localPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		defer dst.Close()
+
+		_, err = io.Copy(dst, c.progressReader(src, remotePath, info.Size()))
+		if err != nil {
+			return fmt.Errorf("copy %s: %w", remotePath, err)
+		}
+	default:
+		c.log.Debugf("Skip special file %s", remotePath)
+		return nil
+	}
+
+	err := os.Chmod(localPath, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	return os.Chtimes(localPath, info.ModTime(), info.ModTime())
+}
+
+func (c *copier) progressReader(reader io.Reader, name string, size int64) io.Reader {
+	c.files++
+	c.bytes += size
+	c.log.Debugf("Copy %s (%s)", name, units.HumanSize(float64(size)))
+	return &progressReader{
+		Reader:      reader,
+		name:        name,
+		size:        size,
+		log:         c.log,
+		lastMessage: time.Now(),
+	}
+}
+
+func (c *copier) done() {
+	c.log.Donef("Copied %d files (%s) in %s", c.files, units.HumanSize(float64(c.bytes)), time.Since(c.start).Round(time.Millisecond))
+}
+
+// progressReader logs the progress of large files every second
+type progressReader struct {
+	io.Reader
+
+	name string
+	size int64
+	log  log.Logger
+
+	lastMessage time.Time
+	bytesRead   int64
+}
+
+func (p *progressReader) Read(b []byte) (n int, err error) {
+	n, err = p.Reader.Read(b)
+	p.bytesRead += int64(n)
+	if time.Since(p.lastMessage) > time.Second {
+		p.log.Infof("Copying %s: %s / %s", p.name, units.HumanSize(float64(p.bytesRead)), units.HumanSize(float64(p.size)))
+		p.lastMessage = time.Now()
+	}
+
+	return n, err
+}
+
+// lookupUser returns the uid and gid of the user in the container, or -1 if it can't be found
+func lookupUser(client *sftp.Client, user string, log log.Logger) (int, int) {
+	if user == "" {
+		return -1, -1
+	}
+
+	passwdFile, err := client.Open("/etc/passwd")
+	if err != nil {
+		log.Warnf("Error reading /etc/passwd in the container, keep the owner of the copied files: %v", err)
+		return -1, -1
+	}
+	defer passwdFile.Close()
+
+	uid, gid, ok := passwd.LookupUser(passwdFile, user)
+	if !ok {
+		log.Warnf("User %s not found in the container, keep the owner of the copied files", user)
+		return -1, -1
+	}
+
+	intUid, err := strconv.Atoi(uid)
+	if err != nil {
+		log.Warnf("Invalid uid %s of user %s in the container, keep the owner of the copied files", uid, user)
+		return -1, -1
+	}
+	intGid, err := strconv.Atoi(gid)
+	if err != nil {
+		log.Warnf("Invalid gid %s of user %s in the container, keep the owner of the copied files", gid, user)
+		return -1, -1
+	}
+
+	return intUid, intGid
+}
@@ -0,0 +1,93 @@
+package remotecopy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devpod/pkg/ssh/sftptest"
+	"github.com/loft-sh/log"
+	"gotest.tools/assert"
+)
+
+func TestParsePath(t *testing.T) {
+	testCases := []struct {
+		arg               string
+		expectedWorkspace string
+		expectedPath      string
+		expectedRemote    bool
+	}{
+		{arg: "my-workspace:/tmp/file", expectedWorkspace: "my-workspace", expectedPath: "/tmp/file", expectedRemote: true},
+		{arg: "my-workspace:src", expectedWorkspace: "my-workspace", expectedPath: "src", expectedRemote: true},
+		{arg: "my-workspace:", expectedWorkspace: "my-workspace", expectedPath: "", expectedRemote: true},
+		{arg: "file.txt", expectedPath: "file.txt"},
+		{arg: "./dir:name", expectedPath: "./dir:name"},
+		{arg: `C:\Users\file.txt`, expectedPath: `C:\Users\file.txt`},
+		{arg: ":file", expectedPath: ":file"},
+	}
+
+	for _, testCase := range testCases {
+		workspace, remotePath, isRemote := ParsePath(testCase.arg)
+		assert.Equal(t, workspace, testCase.expectedWorkspace, testCase.arg)
+		assert.Equal(t, remotePath, testCase.expectedPath, testCase.arg)
+		assert.Equal(t, isRemote, testCase.expectedRemote, testCase.arg)
+	}
+}
+
+func TestUpload(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src")
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	writeTree(t, src, modTime)
+
+	dst := t.TempDir()
+	err := Upload(context.Background(), sftptest.NewClient(t), src, dst, Options{Recursive: true}, log.Discard)
+	assert.NilError(t, err)
+	assertTree(t, filepath.Join(dst, "src"), modTime)
+
+	err = Upload(context.Background(), sftptest.NewClient(t), src, filepath.Join(t.TempDir(), "file"), Options{}, log.Discard)
+	assert.ErrorContains(t, err, "please use --recursive")
+}
+
+func TestDownload(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src")
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	writeTree(t, src, modTime)
+
+	dst := filepath.Join(t.TempDir(), "dst")
+	err := Download(context.Background(), sftptest.NewClient(t), src, dst, Options{Recursive: true}, log.Discard)
+	assert.NilError(t, err)
+	assertTree(t, dst, modTime)
+}
+
+// writeTree creates a folder with a read-only sub folder, a file and a symlink
+func writeTree(t *testing.T, root string, modTime time.Time) {
+	assert.NilError(t, os.MkdirAll(filepath.Join(root, "readonly"), 0755))
+	assert.NilError(t, os.WriteFile(filepath.Join(root, "readonly", "script.sh"), []byte("echo hello"), 0755))
+	assert.NilError(t, os.Symlink("readonly/script.sh", filepath.Join(root, "link")))
+	for _, p := range []string{"readonly/script.sh", "readonly", "."} {
+		assert.NilError(t, os.Chtimes(filepath.Join(root, p), modTime, modTime))
+	}
+	assert.NilError(t, os.Chmod(filepath.Join(root, "readonly"), 0555))
+	t.Cleanup(func() { _ = os.Chmod(filepath.Join(root, "readonly"), 0755) })
+}
+
+func assertTree(t *testing.T, root string, modTime time.Time) {
+	t.Cleanup(func() { _ = os.Chmod(filepath.Join(root, "readonly"), 0755) })
+
+	content, err := os.ReadFile(filepath.Join(root, "readonly", "script.sh"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "echo hello")
+
+	target, err := os.Readlink(filepath.Join(root, "link"))
+	assert.NilError(t, err)
+	assert.Equal(t, target, "readonly/script.sh")
+
+	for p, mode := range map[string]os.FileMode{"readonly/script.sh": 0755, "readonly": 0555, ".": 0755} {
+		info, err := os.Stat(filepath.Join(root, p))
+		assert.NilError(t, err)
+		assert.Equal(t, info.Mode().Perm(), mode, p)
+		assert.Assert(t, info.ModTime().Equal(modTime), "%s: %s", p, info.ModTime())
+	}
+}
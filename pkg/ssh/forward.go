@@ -19,26 +19,71 @@ type ForwardingFunction func(
 	log.Logger,
 )
 
+// DialFunc opens a connection to the remote address, e.g. ssh.Client.Dial
+type DialFunc func(network, addr string) (net.Conn, error)
+
 func PortForward(
 	ctx context.Context,
 	client *ssh.Client,
 	localNetwork, localAddr, remoteNetwork, remoteAddr string,
 	exitAfterTimeout time.Duration,
 	log log.Logger,
+) error {
+	return PortForwardWithDialer(ctx, client.Dial, localNetwork, localAddr, remoteNetwork, remoteAddr, exitAfterTimeout, log)
+}
+
+// PortForwardWithDialer forwards all connections to the local address through the given dial function
+func PortForwardWithDialer(
+	ctx context.Context,
+	dial DialFunc,
+	localNetwork, localAddr, remoteNetwork, remoteAddr string,
+	exitAfterTimeout time.Duration,
+	log log.Logger,
 ) error {
 	listener, err := net.Listen(localNetwork, localAddr)
 	if err != nil {
 		return err
 	}
+
+	return PortForwardListenerWithDialer(ctx, dial, listener, remoteNetwork, remoteAddr, exitAfterTimeout, log)
+}
+
+// PortForwardListener forwards all connections of the given listener to the remote address and closes the listener when done
+func PortForwardListener(
+	ctx context.Context,
+	client *ssh.Client,
+	listener net.Listener,
+	remoteNetwork, remoteAddr string,
+	exitAfterTimeout time.Duration,
+	log log.Logger,
+) error {
+	return PortForwardListenerWithDialer(ctx, client.Dial, listener, remoteNetwork, remoteAddr, exitAfterTimeout, log)
+}
+
+// PortForwardListenerWithDialer forwards all connections of the given listener through the dial function and closes the listener when done
+func PortForwardListenerWithDialer(
+	ctx context.Context,
+	dial DialFunc,
+	listener net.Listener,
+	remoteNetwork, remoteAddr string,
+	exitAfterTimeout time.Duration,
+	log log.Logger,
+) error {
 	defer listener.Close()
 
 	return portForwarding(
-		ctx, client, listener,
-		localNetwork, localAddr, remoteNetwork, remoteAddr,
-		exitAfterTimeout, log, forward,
+		ctx, nil, listener,
+		listener.Addr().Network(), listener.Addr().String(), remoteNetwork, remoteAddr,
+		exitAfterTimeout, log, dialForward(dial),
 	)
 }
 
+func dialForward(dial DialFunc) ForwardingFunction {
+	return func(localConn net.Conn, _ *ssh.Client, remoteNetwork, remoteAddr string, log log.Logger) {
+		forward(localConn, dial, remoteNetwork, remoteAddr, log)
+	}
+}
+
 func ReversePortForward(
 	ctx context.Context,
 	client *ssh.Client,
@@ -103,12 +148,12 @@ func portForwarding(
 
 func forward(
 	localConn net.Conn,
-	client *ssh.Client,
+	dial DialFunc,
 	remoteNetwork, remoteAddr string,
 	log log.Logger,
 ) {
 	// Setup sshConn (type net.Conn)
-	sshConn, err := client.Dial(remoteNetwork, remoteAddr)
+	sshConn, err := dial(remoteNetwork, remoteAddr)
 	if err != nil {
 		log.Debugf("error dialing remote: %v", err)
 		return
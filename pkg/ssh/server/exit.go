@@ -2,7 +2,6 @@ package server
 
 import (
 	"errors"
-	"os/exec"
 	"strings"
 
 	"github.com/loft-sh/log"
@@ -10,9 +9,14 @@ import (
 	perrors "github.com/pkg/errors"
 )
 
+// exitCoder is implemented by exec.ExitError as well as the exit errors of the docker api and driver plugins
+type exitCoder interface {
+	ExitCode() int
+}
+
 func exitWithError(sess ssh.Session, err error, log log.Logger) {
 	if err != nil {
-		var exitError *exec.ExitError
+		var exitError exitCoder
 		if !errors.As(perrors.Cause(err), &exitError) {
 			log.Errorf("Exit error: %v", err)
 			msg := strings.TrimPrefix(err.Error(), "exec: ")
@@ -35,7 +39,7 @@ func exitCode(err error) int {
 		return 0
 	}
 
-	var exitErr *exec.ExitError
+	var exitErr exitCoder
 	if !errors.As(err, &exitErr) {
 		return 1
 	}
@@ -0,0 +1,37 @@
+package ssh
+
+import (
+	"context"
+	"io"
+	"net"
+	"time"
+
+	"github.com/loft-sh/log"
+)
+
+// TrackSessions accepts one connection per session on the listener, which is held open as long as the session
+// lives. onTimeout is called once the last session closed and no new session was opened within timeout.
+func TrackSessions(ctx context.Context, listener net.Listener, timeout time.Duration, onTimeout func(), log log.Logger) error {
+	counter := newConnectionCounter(ctx, timeout, onTimeout, listener.Addr().String(), log)
+	for {
+		connection, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		// tell the counter there is a session
+		counter.Add()
+
+		go func() {
+			defer counter.Dec()
+			defer connection.Close()
+
+			// sessions never write, so this returns once the session closed the connection
+			_, _ = io.Copy(io.Discard, connection)
+		}()
+	}
+}
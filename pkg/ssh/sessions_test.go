@@ -0,0 +1,67 @@
+package ssh
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/log"
+	"gotest.tools/assert"
+)
+
+func TestTrackSessions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	socket := filepath.Join(t.TempDir(), "sessions.sock")
+	listener, err := net.Listen("unix", socket)
+	assert.NilError(t, err)
+	defer listener.Close()
+
+	timedOut := make(chan struct{}, 1)
+	go func() {
+		_ = TrackSessions(ctx, listener, 200*time.Millisecond, func() {
+			timedOut <- struct{}{}
+		}, log.Discard)
+	}()
+
+	// no timeout without a session
+	select {
+	case <-timedOut:
+		t.Fatal("timed out without a session")
+	case <-time.After(400 * time.Millisecond):
+	}
+
+	first, err := net.Dial("unix", socket)
+	assert.NilError(t, err)
+	second, err := net.Dial("unix", socket)
+	assert.NilError(t, err)
+
+	// one session is still open
+	assert.NilError(t, first.Close())
+	select {
+	case <-timedOut:
+		t.Fatal("timed out with an open session")
+	case <-time.After(400 * time.Millisecond):
+	}
+
+	// a new session within the timeout resets it
+	assert.NilError(t, second.Close())
+	time.Sleep(100 * time.Millisecond)
+	third, err := net.Dial("unix", socket)
+	assert.NilError(t, err)
+	select {
+	case <-timedOut:
+		t.Fatal("timed out although a new session was opened")
+	case <-time.After(400 * time.Millisecond):
+	}
+
+	assert.NilError(t, third.Close())
+	select {
+	case <-timedOut:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected a timeout after the last session closed")
+	}
+}
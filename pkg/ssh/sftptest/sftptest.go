@@ -0,0 +1,46 @@
+// Package sftptest provides an in-process sftp server for tests.
+package sftptest
+
+import (
+	"io"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"gotest.tools/assert"
+)
+
+type readWriteCloser struct {
+	io.Reader
+	io.WriteCloser
+}
+
+// NewClient returns a client of an sftp server that runs in the test process. Client and server are closed when the
+// test finishes.
+func NewClient(t testing.TB) *sftp.Client {
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+	server, err := sftp.NewServer(readWriteCloser{serverReader, serverWriter})
+	assert.NilError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = server.Serve()
+	}()
+
+	client, err := sftp.NewClientPipe(clientReader, clientWriter)
+	if err != nil {
+		_ = server.Close()
+		_ = clientWriter.Close()
+		<-done
+		t.Fatalf("create sftp client: %v", err)
+	}
+	t.Cleanup(func() {
+		// the client waits for the server to close its side of the connection
+		_ = server.Close()
+		_ = client.Close()
+		<-done
+	})
+
+	return client
+}
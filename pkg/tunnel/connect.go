@@ -0,0 +1,75 @@
+package tunnel
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/loft-sh/devpod/pkg/client"
+	"github.com/loft-sh/devpod/pkg/config"
+	devssh "github.com/loft-sh/devpod/pkg/ssh"
+	"github.com/loft-sh/log"
+	"golang.org/x/crypto/ssh"
+)
+
+// WithContainer connects to the container of a running workspace via ssh and runs handler with the connection
+func WithContainer(ctx context.Context, devPodConfig *config.Config, baseClient client.BaseWorkspaceClient, log log.Logger, handler Handler) error {
+	switch workspaceClient := baseClient.(type) {
+	case client.WorkspaceClient:
+		// lock the workspace as long as we init the connection
+		err := workspaceClient.Lock(ctx)
+		if err != nil {
+			return err
+		}
+		defer workspaceClient.Unlock()
+
+		status, err := workspaceClient.Status(ctx, client.StatusOptions{})
+		if err != nil {
+			return err
+		} else if status != client.StatusRunning {
+			return fmt.Errorf("workspace %s is %s, please run 'devpod up %s' first", workspaceClient.Workspace(), status, workspaceClient.Workspace())
+		}
+
+		return NewContainerTunnel(workspaceClient, log).Run(ctx, func(ctx context.Context, containerClient *ssh.Client) error {
+			// we have a connection to the container, make sure others can connect as well
+			workspaceClient.Unlock()
+
+			return handler(ctx, containerClient)
+		}, devPodConfig, nil)
+	case client.ProxyClient:
+		user, err := devssh.GetUser(workspaceClient.WorkspaceConfig().ID, workspaceClient.WorkspaceConfig().SSHConfigPath)
+		if err != nil {
+			return err
+		}
+
+		return NewTunnel(ctx, func(ctx context.Context, stdin io.Reader, stdout io.Writer) error {
+			return workspaceClient.Ssh(ctx, client.SshOptions{
+				User:   user,
+				Stdin:  stdin,
+				Stdout: stdout,
+			})
+		}, handler)
+	case client.DaemonClient:
+		err := workspaceClient.CheckWorkspaceReachable(ctx)
+		if err != nil {
+			return err
+		}
+
+		user, err := devssh.GetUser(workspaceClient.WorkspaceConfig().ID, workspaceClient.WorkspaceConfig().SSHConfigPath)
+		if err != nil {
+			return err
+		}
+
+		// the tool client is connected as root, same as the container tunnel of a workspace client
+		toolClient, userClient, err := workspaceClient.SSHClients(ctx, user)
+		if err != nil {
+			return err
+		}
+		defer toolClient.Close()
+		defer userClient.Close()
+
+		return handler(ctx, toolClient)
+	}
+
+	return fmt.Errorf("connecting to the container of workspace %s is not supported by its provider", baseClient.Workspace())
+}
@@ -2,20 +2,27 @@ package tunnel
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"strconv"
 	"sync"
 
+	"github.com/loft-sh/devpod/pkg/devcontainer/config"
 	"github.com/loft-sh/devpod/pkg/netstat"
-	devssh "github.com/loft-sh/devpod/pkg/ssh"
+	"github.com/loft-sh/devpod/pkg/open"
+	portpkg "github.com/loft-sh/devpod/pkg/port"
 	"github.com/loft-sh/log"
 	"golang.org/x/crypto/ssh"
 )
 
 // newForwarder returns a new forwarder using an SSH client and list of ports to forward,
 // for each port a new go routine is used to manage the SSH channel
-func newForwarder(sshClient *ssh.Client, forwardedPorts []string, log log.Logger) netstat.Forwarder {
+func newForwarder(sshClient *ssh.Client, forwardedPorts []string, registry *portRegistry, mergedConfig *config.MergedDevContainerConfig, log log.Logger) netstat.Forwarder {
 	return &forwarder{
 		sshClient:      sshClient,
 		forwardedPorts: forwardedPorts,
+		registry:       registry,
+		mergedConfig:   mergedConfig,
 		portMap:        map[string]context.CancelFunc{},
 		log:            log,
 	}
@@ -27,6 +34,8 @@ type forwarder struct {
 
 	sshClient      *ssh.Client
 	forwardedPorts []string
+	registry       *portRegistry
+	mergedConfig   *config.MergedDevContainerConfig
 
 	portMap map[string]context.CancelFunc
 	log     log.Logger
@@ -41,17 +50,63 @@ func (f *forwarder) Forward(port string) error {
 		return nil
 	}
 
-	cancelCtx, cancel := context.WithCancel(context.Background())
-	f.portMap[port] = cancel
-	f.log.Infof("Start port-forwarding on port %s", port)
+	// ports bound to a specific interface are passed as ip:port
+	remoteHost, remotePort := "localhost", port
+	if host, hostPort, err := net.SplitHostPort(port); err == nil {
+		remoteHost, remotePort = host, hostPort
+	}
+	portNumber, err := strconv.Atoi(remotePort)
+	if err != nil {
+		return fmt.Errorf("parse port %s: %w", port, err)
+	}
+
+	attribute := f.getPortAttribute(portNumber)
+	onAutoForward := attribute.GetOnAutoForward()
+	if onAutoForward == config.OnAutoForwardIgnore {
+		f.log.Debugf("Ignore port %s as onAutoForward is set to %s", port, onAutoForward)
+		return nil
+	}
 
-	go func(port string) {
-		// do the forward
-		err := devssh.PortForward(cancelCtx, f.sshClient, "tcp", "localhost:"+port, "tcp", "localhost:"+port, 0, f.log)
+	// use the same local port if possible
+	localPort := portNumber
+	if available, _ := portpkg.IsAvailable("localhost:" + remotePort); !available {
+		if attribute.RequireLocalPort {
+			return fmt.Errorf("local port %s is already in use and requireLocalPort is set", port)
+		}
+
+		localPort, err = portpkg.FindAvailablePort(portNumber + 1)
 		if err != nil {
-			f.log.Errorf("Error port forwarding %s: %v", port, err)
+			return fmt.Errorf("find available local port for %s: %w", port, err)
 		}
-	}(port)
+	}
+
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	err = f.registry.Forward(cancelCtx, f.sshClient, "localhost:"+strconv.Itoa(localPort), net.JoinHostPort(remoteHost, remotePort), attribute.Label, PortOriginAuto, 0, f.log)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("forward port %s: %w", port, err)
+	}
+	f.portMap[port] = cancel
+
+	message := fmt.Sprintf("Start port-forwarding on port %s", port)
+	if attribute.Label != "" {
+		message += fmt.Sprintf(" (%s)", attribute.Label)
+	}
+	if localPort != portNumber {
+		message += fmt.Sprintf(" to local port %d", localPort)
+	}
+	if onAutoForward == config.OnAutoForwardSilent {
+		f.log.Debug(message)
+	} else {
+		f.log.Info(message)
+	}
+
+	// there is no preview within the terminal, so open the browser in both cases
+	if onAutoForward == config.OnAutoForwardOpenBrowser || onAutoForward == config.OnAutoForwardOpenPreview {
+		go func() {
+			_ = open.Open(cancelCtx, fmt.Sprintf("http://localhost:%d", localPort), f.log)
+		}()
+	}
 
 	return nil
 }
@@ -72,12 +127,30 @@ func (f *forwarder) StopForward(port string) error {
 	return nil
 }
 
+// isExcluded checks if the port is already forwarded from the devcontainer.json, ports are compared
+// without their host as both port and ip:port would be forwarded to the same local port
 func (f *forwarder) isExcluded(port string) bool {
 	for _, p := range f.forwardedPorts {
-		if p == port {
+		if portWithoutHost(p) == portWithoutHost(port) {
 			return true
 		}
 	}
 
 	return false
 }
+
+func portWithoutHost(port string) string {
+	if _, hostPort, err := net.SplitHostPort(port); err == nil {
+		return hostPort
+	}
+
+	return port
+}
+
+func (f *forwarder) getPortAttribute(port int) config.PortAttribute {
+	if f.mergedConfig == nil {
+		return config.PortAttribute{}
+	}
+
+	return config.GetPortAttribute(f.mergedConfig.PortsAttributes, f.mergedConfig.OtherPortsAttributes, port)
+}
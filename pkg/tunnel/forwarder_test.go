@@ -0,0 +1,17 @@
+package tunnel
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestForwarderIsExcluded(t *testing.T) {
+	f := &forwarder{forwardedPorts: []string{"3000", "db:5432", "[::1]:8080"}}
+	assert.Assert(t, f.isExcluded("3000"))
+	assert.Assert(t, f.isExcluded("127.0.0.1:3000"))
+	assert.Assert(t, f.isExcluded("5432"))
+	assert.Assert(t, f.isExcluded("0.0.0.0:8080"))
+	assert.Assert(t, !f.isExcluded("3001"))
+	assert.Assert(t, !f.isExcluded("127.0.0.1:30000"))
+}
@@ -0,0 +1,42 @@
+package tunnel
+
+import (
+	"context"
+
+	"github.com/loft-sh/devpod/pkg/client"
+	"github.com/loft-sh/devpod/pkg/devcontainer"
+	"github.com/loft-sh/devpod/pkg/driver/kubernetes"
+	"github.com/loft-sh/devpod/pkg/provider"
+	devssh "github.com/loft-sh/devpod/pkg/ssh"
+	"github.com/loft-sh/log"
+)
+
+// NewKubernetesDialer returns a function that connects to the ports of the devcontainer through the kubernetes port-forward api.
+// It returns nil if the workspace doesn't use the kubernetes driver with nativeConnect enabled or the cluster can't be reached.
+func NewKubernetesDialer(ctx context.Context, baseClient client.BaseWorkspaceClient, log log.Logger) devssh.DialFunc {
+	workspaceClient, ok := baseClient.(client.WorkspaceClient)
+	if !ok {
+		return nil
+	}
+
+	_, agentInfo, err := workspaceClient.AgentInfo(provider.CLIOptions{})
+	if err != nil {
+		log.Debugf("Error getting agent info: %v", err)
+		return nil
+	} else if !kubernetes.NativeConnectEnabled(&agentInfo.Agent) {
+		return nil
+	}
+
+	portForwarder, err := kubernetes.NewPortForwarder(ctx, agentInfo, devcontainer.GetRunnerIDFromWorkspace(agentInfo.Workspace), log)
+	if err != nil {
+		log.Warnf("Error connecting to the kubernetes api, falling back to ssh: %v", err)
+		return nil
+	}
+	go func() {
+		<-ctx.Done()
+		_ = portForwarder.Close()
+	}()
+
+	log.Debugf("Forwarding ports through the kubernetes api")
+	return portForwarder.Dial
+}
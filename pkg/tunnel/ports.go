@@ -0,0 +1,199 @@
+package tunnel
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	devssh "github.com/loft-sh/devpod/pkg/ssh"
+	"github.com/loft-sh/log"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// PortOriginConfig are ports forwarded because of the devcontainer.json or the IDE
+	PortOriginConfig = "config"
+	// PortOriginAuto are ports that were found open within the container
+	PortOriginAuto = "auto"
+	// PortOriginManual are ports forwarded via devpod ports forward
+	PortOriginManual = "manual"
+)
+
+// ForwardedPort describes an active port forward of a session
+type ForwardedPort struct {
+	LocalAddress     string `json:"localAddress"`
+	RemoteAddress    string `json:"remoteAddress"`
+	Label            string `json:"label,omitempty"`
+	Origin           string `json:"origin"`
+	BytesTransferred int64  `json:"bytesTransferred"`
+}
+
+type registeredPort struct {
+	ForwardedPort
+
+	bytes  atomic.Int64
+	cancel context.CancelFunc
+}
+
+// portRegistry keeps track of the port forwards of a session
+type portRegistry struct {
+	m     sync.Mutex
+	ports map[string]*registeredPort
+
+	// dial is tried before the ssh client if set
+	dial devssh.DialFunc
+}
+
+func newPortRegistry(dial devssh.DialFunc) *portRegistry {
+	return &portRegistry{
+		ports: map[string]*registeredPort{},
+		dial:  dial,
+	}
+}
+
+// Forward listens on the local address and forwards all connections to the remote address until the context is done
+func (r *portRegistry) Forward(ctx context.Context, client *ssh.Client, localAddr, remoteAddr, label, origin string, exitAfterTimeout time.Duration, log log.Logger) error {
+	listener, err := net.Listen("tcp", localAddr)
+	if err != nil {
+		return err
+	}
+
+	cancelCtx, cancel := context.WithCancel(ctx)
+	port := &registeredPort{
+		ForwardedPort: ForwardedPort{
+			LocalAddress:  localAddr,
+			RemoteAddress: remoteAddr,
+			Label:         label,
+			Origin:        origin,
+		},
+		cancel: cancel,
+	}
+
+	r.m.Lock()
+	r.ports[localAddr] = port
+	r.m.Unlock()
+
+	go func() {
+		defer cancel()
+		defer r.remove(localAddr, port)
+
+		err := devssh.PortForwardListenerWithDialer(cancelCtx, r.dialer(client, log), &countingListener{Listener: listener, bytes: &port.bytes}, "tcp", remoteAddr, exitAfterTimeout, log)
+		if err != nil && cancelCtx.Err() == nil {
+			log.Errorf("Error port forwarding %s: %v", remoteAddr, err)
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops the forwards that match the given local address, remote address or port and returns how many were stopped
+func (r *portRegistry) Stop(address string) int {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	stopped := 0
+	for localAddr, port := range r.ports {
+		if !matchesAddress(port.LocalAddress, address) && !matchesAddress(port.RemoteAddress, address) {
+			continue
+		}
+
+		port.cancel()
+		delete(r.ports, localAddr)
+		stopped++
+	}
+
+	return stopped
+}
+
+// List returns the active forwards sorted by local address
+func (r *portRegistry) List() []ForwardedPort {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	ports := []ForwardedPort{}
+	for _, port := range r.ports {
+		forwardedPort := port.ForwardedPort
+		forwardedPort.BytesTransferred = port.bytes.Load()
+		ports = append(ports, forwardedPort)
+	}
+	sort.Slice(ports, func(i, j int) bool {
+		return ports[i].LocalAddress < ports[j].LocalAddress
+	})
+
+	return ports
+}
+
+// dialer uses the dial function of the registry and falls back to the ssh client
+func (r *portRegistry) dialer(client *ssh.Client, log log.Logger) devssh.DialFunc {
+	if r.dial == nil {
+		return client.Dial
+	}
+
+	return func(network, addr string) (net.Conn, error) {
+		conn, err := r.dial(network, addr)
+		if err == nil {
+			return conn, nil
+		}
+
+		log.Debugf("Error dialing %s, falling back to ssh: %v", addr, err)
+		return client.Dial(network, addr)
+	}
+}
+
+func (r *portRegistry) remove(localAddr string, port *registeredPort) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	// the address might have been reused by a newer forward
+	if r.ports[localAddr] == port {
+		delete(r.ports, localAddr)
+	}
+}
+
+// matchesAddress checks if the address is either the same or has the given port
+func matchesAddress(address, addressOrPort string) bool {
+	if address == addressOrPort {
+		return true
+	}
+
+	_, port, err := net.SplitHostPort(address)
+	return err == nil && !strings.Contains(addressOrPort, ":") && port == addressOrPort
+}
+
+// countingListener counts the bytes transferred over all accepted connections
+type countingListener struct {
+	net.Listener
+
+	bytes *atomic.Int64
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	return &countingConn{Conn: conn, bytes: l.bytes}, nil
+}
+
+type countingConn struct {
+	net.Conn
+
+	bytes *atomic.Int64
+}
+
+func (c *countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.bytes.Add(int64(n))
+	return n, err
+}
+
+func (c *countingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.bytes.Add(int64(n))
+	return n, err
+}
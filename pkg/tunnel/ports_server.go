@@ -0,0 +1,310 @@
+package tunnel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/loft-sh/devpod/pkg/provider"
+	"github.com/loft-sh/log"
+	"github.com/loft-sh/log/hash"
+	"golang.org/x/crypto/ssh"
+)
+
+// ForwardPortRequest is sent to a session to forward a port manually
+type ForwardPortRequest struct {
+	// Port is either the remote port or local-port:remote-port
+	Port string `json:"port"`
+}
+
+// StopPortRequest is sent to a session to stop forwarding a port
+type StopPortRequest struct {
+	// Port is either a port or an address of a forward
+	Port string `json:"port"`
+}
+
+// GetPortsSocketDir returns the folder that holds one control socket per active session of the workspace.
+// The folder is kept short as unix socket paths are limited to 104 bytes on macOS, so it's named after a
+// hash of the workspace folder instead of living within it.
+func GetPortsSocketDir(workspace *provider.Workspace) (string, error) {
+	workspaceDir, err := provider.GetWorkspaceDir(workspace.Context, workspace.ID)
+	if err != nil {
+		return "", err
+	}
+
+	tempDir := "/tmp"
+	if runtime.GOOS == "windows" {
+		tempDir = os.TempDir()
+	}
+
+	return filepath.Join(tempDir, "devpod-ports-"+hash.String(workspaceDir)[:16]), nil
+}
+
+// checkPortsSocketDir makes sure that no other user is able to place sockets in the shared temp folder
+func checkPortsSocketDir(socketDir string) error {
+	info, err := os.Lstat(socketDir)
+	if err != nil {
+		return err
+	} else if !info.IsDir() {
+		return fmt.Errorf("ports socket folder %s is not a folder", socketDir)
+	} else if !isOwnedByCurrentUser(info) {
+		return fmt.Errorf("ports socket folder %s is owned by another user", socketDir)
+	} else if runtime.GOOS != "windows" && info.Mode().Perm() != 0o700 {
+		return fmt.Errorf("ports socket folder %s must only be accessible by its owner, but has mode %s", socketDir, info.Mode().Perm())
+	}
+
+	return nil
+}
+
+// runPortsServer serves the port forwards of this session on a control socket until the context is done
+func runPortsServer(ctx context.Context, workspace *provider.Workspace, registry *portRegistry, containerClient *ssh.Client, log log.Logger) error {
+	socketDir, err := GetPortsSocketDir(workspace)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(socketDir, 0o700)
+	if err != nil {
+		return fmt.Errorf("create ports socket folder: %w", err)
+	}
+	err = checkPortsSocketDir(socketDir)
+	if err != nil {
+		return err
+	}
+
+	socketPath := filepath.Join(socketDir, strconv.Itoa(os.Getpid())+".sock")
+	_ = os.Remove(socketPath)
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", socketPath, err)
+	}
+	defer os.Remove(socketPath)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ports", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, registry.List())
+	})
+	mux.HandleFunc("/forward", func(w http.ResponseWriter, r *http.Request) {
+		request := &ForwardPortRequest{}
+		err := json.NewDecoder(r.Body).Decode(request)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		localPort, remotePort, err := parseManualPort(request.Port)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		err = registry.Forward(ctx, containerClient, "localhost:"+localPort, "localhost:"+remotePort, "", PortOriginManual, 0, log)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+
+		log.Infof("Start port-forwarding on port %s to local port %s", remotePort, localPort)
+		writeJSON(w, registry.List())
+	})
+	mux.HandleFunc("/stop", func(w http.ResponseWriter, r *http.Request) {
+		request := &StopPortRequest{}
+		err := json.NewDecoder(r.Body).Decode(request)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if registry.Stop(request.Port) == 0 {
+			http.Error(w, fmt.Sprintf("port %s is not forwarded", request.Port), http.StatusNotFound)
+			return
+		}
+
+		log.Infof("Stop port-forwarding on port %s", request.Port)
+		writeJSON(w, registry.List())
+	})
+
+	server := &http.Server{Handler: mux}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	err = server.Serve(listener)
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+func parseManualPort(port string) (string, string, error) {
+	localPort, remotePort, found := strings.Cut(port, ":")
+	if !found {
+		remotePort = localPort
+	}
+
+	for _, p := range []string{localPort, remotePort} {
+		if _, err := strconv.Atoi(p); err != nil {
+			return "", "", fmt.Errorf("invalid port %s, expected port or local-port:remote-port", port)
+		}
+	}
+
+	return localPort, remotePort, nil
+}
+
+func writeJSON(w http.ResponseWriter, obj interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(obj)
+}
+
+// ListForwardedPorts returns the port forwards of all active sessions of the workspace
+func ListForwardedPorts(ctx context.Context, workspace *provider.Workspace) ([]ForwardedPort, error) {
+	sockets, err := getPortsSockets(workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	ports := []ForwardedPort{}
+	for _, socket := range sockets {
+		sessionPorts := []ForwardedPort{}
+		err = requestPortsServer(ctx, socket, "/ports", nil, &sessionPorts)
+		if err != nil {
+			return nil, err
+		}
+
+		ports = append(ports, sessionPorts...)
+	}
+
+	return ports, nil
+}
+
+// ForwardPort forwards the port within the first active session of the workspace
+func ForwardPort(ctx context.Context, workspace *provider.Workspace, port string) error {
+	sockets, err := getPortsSockets(workspace)
+	if err != nil {
+		return err
+	} else if len(sockets) == 0 {
+		return fmt.Errorf("no active session found for workspace %s, please run 'devpod ssh %s' or 'devpod up %s' first", workspace.ID, workspace.ID, workspace.ID)
+	}
+
+	return requestPortsServer(ctx, sockets[0], "/forward", &ForwardPortRequest{Port: port}, nil)
+}
+
+// StopForwardedPort stops forwarding the port in all active sessions of the workspace
+func StopForwardedPort(ctx context.Context, workspace *provider.Workspace, port string) error {
+	sockets, err := getPortsSockets(workspace)
+	if err != nil {
+		return err
+	}
+
+	stopped := false
+	for _, socket := range sockets {
+		err = requestPortsServer(ctx, socket, "/stop", &StopPortRequest{Port: port}, nil)
+		if err == nil {
+			stopped = true
+		}
+	}
+	if !stopped {
+		return fmt.Errorf("port %s is not forwarded", port)
+	}
+
+	return nil
+}
+
+// getPortsSockets returns the control sockets of the reachable sessions and removes the ones without a session
+func getPortsSockets(workspace *provider.Workspace) ([]string, error) {
+	socketDir, err := GetPortsSocketDir(workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkPortsSocketDir(socketDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(socketDir)
+	if err != nil {
+		return nil, err
+	}
+
+	sockets := []string{}
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".sock") {
+			continue
+		}
+
+		socket := filepath.Join(socketDir, entry.Name())
+		conn, err := net.DialTimeout("unix", socket, time.Second)
+		if err != nil {
+			// busy sessions keep their socket and are only skipped
+			if isStaleSocketError(err) {
+				_ = os.Remove(socket)
+			}
+			continue
+		}
+		_ = conn.Close()
+
+		sockets = append(sockets, socket)
+	}
+
+	return sockets, nil
+}
+
+func requestPortsServer(ctx context.Context, socket, path string, body interface{}, out interface{}) error {
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+			},
+		},
+		Timeout: 10 * time.Second,
+	}
+
+	method := http.MethodGet
+	var reader io.Reader
+	if body != nil {
+		method = http.MethodPost
+		rawBody, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(rawBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, "http://ports"+path, reader)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(resp.Body)
+		return errors.New(strings.TrimSpace(string(message)))
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+
+	return nil
+}
@@ -0,0 +1,20 @@
+//go:build linux || darwin || unix
+
+package tunnel
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// isStaleSocketError returns true if nobody listens on the socket anymore
+func isStaleSocketError(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ENOENT)
+}
+
+// isOwnedByCurrentUser returns true if the file belongs to the user running this process
+func isOwnedByCurrentUser(info os.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int(stat.Uid) == os.Getuid()
+}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package tunnel
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// isStaleSocketError returns true if nobody listens on the socket anymore
+func isStaleSocketError(err error) bool {
+	return errors.Is(err, windows.WSAECONNREFUSED) || errors.Is(err, os.ErrNotExist)
+}
+
+// isOwnedByCurrentUser returns true as the temp folder is already per user on windows
+func isOwnedByCurrentUser(info os.FileInfo) bool {
+	return true
+}
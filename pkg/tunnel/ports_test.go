@@ -0,0 +1,111 @@
+package tunnel
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devpod/pkg/config"
+	"github.com/loft-sh/devpod/pkg/provider"
+	"github.com/loft-sh/log"
+	"gotest.tools/assert"
+)
+
+func TestMatchesAddress(t *testing.T) {
+	assert.Assert(t, matchesAddress("localhost:8080", "localhost:8080"))
+	assert.Assert(t, matchesAddress("localhost:8080", "8080"))
+	assert.Assert(t, !matchesAddress("localhost:8080", "80"))
+	assert.Assert(t, !matchesAddress("localhost:8080", "127.0.0.1:8080"))
+}
+
+func TestParseManualPort(t *testing.T) {
+	localPort, remotePort, err := parseManualPort("8080")
+	assert.NilError(t, err)
+	assert.Equal(t, localPort, "8080")
+	assert.Equal(t, remotePort, "8080")
+
+	localPort, remotePort, err = parseManualPort("9090:8080")
+	assert.NilError(t, err)
+	assert.Equal(t, localPort, "9090")
+	assert.Equal(t, remotePort, "8080")
+
+	_, _, err = parseManualPort("localhost:8080")
+	assert.ErrorContains(t, err, "invalid port")
+}
+
+func TestPortsServerLongHome(t *testing.T) {
+	t.Setenv(config.DEVPOD_HOME, filepath.Join(t.TempDir(), strings.Repeat("a", 100)))
+	workspace := &provider.Workspace{ID: "my-workspace", Context: "default"}
+	removePortsSocketDir(t, workspace)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- runPortsServer(ctx, workspace, newPortRegistry(nil), nil, log.Discard)
+	}()
+
+	for i := 0; ; i++ {
+		select {
+		case err := <-errChan:
+			t.Fatalf("ports server stopped: %v", err)
+		default:
+		}
+
+		sockets, err := getPortsSockets(workspace)
+		assert.NilError(t, err)
+		if len(sockets) == 1 {
+			ports, err := ListForwardedPorts(ctx, workspace)
+			assert.NilError(t, err)
+			assert.Equal(t, len(ports), 0)
+			break
+		}
+		assert.Assert(t, i < 50, "ports server didn't start")
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	cancel()
+	assert.NilError(t, <-errChan)
+}
+
+func TestGetPortsSockets(t *testing.T) {
+	t.Setenv(config.DEVPOD_HOME, t.TempDir())
+	workspace := &provider.Workspace{ID: "my-workspace", Context: "default"}
+	socketDir := removePortsSocketDir(t, workspace)
+
+	// other users must not be able to place sockets
+	assert.NilError(t, os.Mkdir(socketDir, 0o755))
+	_, err := getPortsSockets(workspace)
+	assert.ErrorContains(t, err, "must only be accessible by its owner")
+	assert.NilError(t, os.Chmod(socketDir, 0o700))
+
+	live, err := net.Listen("unix", filepath.Join(socketDir, "1.sock"))
+	assert.NilError(t, err)
+	defer live.Close()
+	stale, err := net.Listen("unix", filepath.Join(socketDir, "2.sock"))
+	assert.NilError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	assert.NilError(t, stale.Close())
+
+	sockets, err := getPortsSockets(workspace)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, sockets, []string{filepath.Join(socketDir, "1.sock")})
+	_, err = os.Stat(filepath.Join(socketDir, "2.sock"))
+	assert.Assert(t, os.IsNotExist(err))
+}
+
+// removePortsSocketDir removes the shared socket folder of the workspace before and after the test
+func removePortsSocketDir(t *testing.T, workspace *provider.Workspace) string {
+	socketDir, err := GetPortsSocketDir(workspace)
+	assert.NilError(t, err)
+	assert.NilError(t, os.RemoveAll(socketDir))
+	t.Cleanup(func() {
+		_ = os.RemoveAll(socketDir)
+	})
+
+	return socketDir
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/loft-sh/devpod/pkg/gitsshsigning"
 	"github.com/loft-sh/devpod/pkg/ide/openvscode"
 	"github.com/loft-sh/devpod/pkg/netstat"
+	portpkg "github.com/loft-sh/devpod/pkg/port"
 	"github.com/loft-sh/devpod/pkg/provider"
 	devssh "github.com/loft-sh/devpod/pkg/ssh"
 	"github.com/loft-sh/log"
@@ -32,11 +33,13 @@ import (
 	"k8s.io/client-go/util/retry"
 )
 
-// RunServices forwards the ports for a given workspace and uses it's SSH client to run the credentials server remotely and the services server locally to communicate with the container
+// RunServices forwards the ports for a given workspace and uses it's SSH client to run the credentials server remotely and the services server locally to communicate with the container.
+// If dial is set, forwarded ports connect through it and only fall back to the SSH client.
 func RunServices(
 	ctx context.Context,
 	devPodConfig *config.Config,
 	containerClient *ssh.Client,
+	dial devssh.DialFunc,
 	user string,
 	forwardPorts bool,
 	extraPorts []string,
@@ -52,11 +55,22 @@ func RunServices(
 	}
 
 	// forward ports
-	forwardedPorts, err := forwardDevContainerPorts(ctx, containerClient, extraPorts, exitAfterTimeout, log)
+	registry := newPortRegistry(dial)
+	forwardedPorts, mergedConfig, err := forwardDevContainerPorts(ctx, containerClient, registry, extraPorts, exitAfterTimeout, log)
 	if err != nil {
 		return errors.Wrap(err, "forward ports")
 	}
 
+	// allow devpod ports to inspect and manage the forwards of this session
+	if workspace != nil {
+		go func() {
+			err := runPortsServer(ctx, workspace, registry, containerClient, log)
+			if err != nil {
+				log.Warnf("Error running ports server, devpod ports won't be able to manage the forwards of this session: %v", err)
+			}
+		}()
+	}
+
 	return retry.OnError(wait.Backoff{
 		Steps:    math.MaxInt,
 		Duration: 500 * time.Millisecond,
@@ -84,7 +98,7 @@ func RunServices(
 		// create a port forwarder
 		var forwarder netstat.Forwarder
 		if forwardPorts {
-			forwarder = newForwarder(containerClient, append(forwardedPorts, fmt.Sprintf("%d", openvscode.DefaultVSCodePort)), log)
+			forwarder = newForwarder(containerClient, append(forwardedPorts, fmt.Sprintf("%d", openvscode.DefaultVSCodePort)), registry, mergedConfig, log)
 		}
 
 		errChan := make(chan error, 1)
@@ -128,7 +142,7 @@ func RunServices(
 			command += " --configure-docker-helper"
 		}
 		if forwardPorts {
-			command += " --forward-ports"
+			command += " --forward-ports" + autoForwardPortsFlags(devPodConfig, mergedConfig, log)
 		}
 		if log.GetLevel() == logrus.DebugLevel {
 			command += " --debug"
@@ -147,20 +161,70 @@ func RunServices(
 	})
 }
 
+// autoForwardPortsFlags returns the port filter flags for the credentials server, the devcontainer.json
+// customizations take precedence over the context options
+func autoForwardPortsFlags(devPodConfig *config.Config, mergedConfig *config2.MergedDevContainerConfig, log log.Logger) string {
+	portsRange := []string{devPodConfig.ContextOption(config.ContextOptionAutoForwardPortsRange)}
+	exclude := []string{devPodConfig.ContextOption(config.ContextOptionAutoForwardPortsExclude)}
+	allow := []string{devPodConfig.ContextOption(config.ContextOptionAutoForwardPortsAllow)}
+	if mergedConfig != nil {
+		customizations := config2.GetMergedDevPodCustomizations(mergedConfig)
+		if customizations.AutoForwardPortsRange != "" {
+			portsRange = []string{customizations.AutoForwardPortsRange}
+		}
+		if len(customizations.AutoForwardPortsExclude) > 0 {
+			exclude = customizations.AutoForwardPortsExclude
+		}
+		if len(customizations.AutoForwardPortsAllow) > 0 {
+			allow = customizations.AutoForwardPortsAllow
+		}
+	}
+
+	flags := ""
+	for _, option := range []struct {
+		flag   string
+		value  []string
+		single bool
+	}{
+		{flag: "--forward-ports-range", value: portsRange, single: true},
+		{flag: "--forward-ports-exclude", value: exclude},
+		{flag: "--forward-ports-allow", value: allow},
+	} {
+		ranges, err := portpkg.ParseRanges(option.value)
+		if err != nil {
+			log.Warnf("Ignoring %s: %v", option.flag, err)
+			continue
+		} else if len(ranges) == 0 {
+			continue
+		} else if option.single && len(ranges) > 1 {
+			log.Warnf("Ignoring %s: expected a single port range", option.flag)
+			continue
+		}
+
+		rangeStrings := []string{}
+		for _, portRange := range ranges {
+			rangeStrings = append(rangeStrings, portRange.String())
+		}
+		flags += fmt.Sprintf(" %s %s", option.flag, strings.Join(rangeStrings, ","))
+	}
+
+	return flags
+}
+
 // forwardDevContainerPorts forwards all the ports defined in the devcontainer.json
-func forwardDevContainerPorts(ctx context.Context, containerClient *ssh.Client, extraPorts []string, exitAfterTimeout time.Duration, log log.Logger) ([]string, error) {
+func forwardDevContainerPorts(ctx context.Context, containerClient *ssh.Client, registry *portRegistry, extraPorts []string, exitAfterTimeout time.Duration, log log.Logger) ([]string, *config2.MergedDevContainerConfig, error) {
 	stdout := &bytes.Buffer{}
 	stderr := &bytes.Buffer{}
 	err := devssh.Run(ctx, containerClient, "cat "+setup.ResultLocation, nil, stdout, stderr, nil)
 	if err != nil {
-		return nil, fmt.Errorf("retrieve container result: %s\n%s%w", stdout.String(), stderr.String(), err)
+		return nil, nil, fmt.Errorf("retrieve container result: %s\n%s%w", stdout.String(), stderr.String(), err)
 	}
 
 	// parse result
 	result := &config2.Result{}
 	err = json.Unmarshal(stdout.Bytes(), result)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing container result %s: %w", stdout.String(), err)
+		return nil, nil, fmt.Errorf("error parsing container result %s: %w", stdout.String(), err)
 	}
 	log.Debugf("Successfully parsed result at %s", setup.ResultLocation)
 
@@ -169,12 +233,12 @@ func forwardDevContainerPorts(ctx context.Context, containerClient *ssh.Client,
 
 	// extra ports
 	for _, port := range extraPorts {
-		forwardedPorts = append(forwardedPorts, forwardPort(ctx, containerClient, port, exitAfterTimeout, log)...)
+		forwardedPorts = append(forwardedPorts, forwardPort(ctx, containerClient, registry, result.MergedConfig, port, exitAfterTimeout, log)...)
 	}
 
 	// app ports
 	for _, port := range result.MergedConfig.AppPort {
-		forwardedPorts = append(forwardedPorts, forwardPort(ctx, containerClient, port, 0, log)...)
+		forwardedPorts = append(forwardedPorts, forwardPort(ctx, containerClient, registry, result.MergedConfig, port, 0, log)...)
 	}
 
 	// forward ports
@@ -185,31 +249,35 @@ func forwardDevContainerPorts(ctx context.Context, containerClient *ssh.Client,
 			log.Debugf("Error parsing forwardPort %s: %v", port, err)
 		}
 
+		// show the label of the port if there is one
+		label := config2.GetPortAttribute(result.MergedConfig.PortsAttributes, result.MergedConfig.OtherPortsAttributes, int(portNumber)).Label
+		if label != "" {
+			log.Infof("Forward port %s (%s)", port, label)
+		}
+
 		// try to forward
-		go func(port string) {
-			log.Debugf("Forward port %s", port)
-			err = devssh.PortForward(
-				ctx,
-				containerClient,
-				"tcp",
-				fmt.Sprintf("localhost:%d", portNumber),
-				"tcp",
-				fmt.Sprintf("%s:%d", host, portNumber),
-				0,
-				log,
-			)
-			if err != nil {
-				log.Errorf("Error port forwarding %s: %v", port, err)
-			}
-		}(port)
+		log.Debugf("Forward port %s", port)
+		err = registry.Forward(
+			ctx,
+			containerClient,
+			fmt.Sprintf("localhost:%d", portNumber),
+			fmt.Sprintf("%s:%d", host, portNumber),
+			label,
+			PortOriginConfig,
+			0,
+			log,
+		)
+		if err != nil {
+			log.Errorf("Error port forwarding %s: %v", port, err)
+		}
 
 		forwardedPorts = append(forwardedPorts, port)
 	}
 
-	return forwardedPorts, nil
+	return forwardedPorts, result.MergedConfig, nil
 }
 
-func forwardPort(ctx context.Context, containerClient *ssh.Client, port string, exitAfterTimeout time.Duration, log log.Logger) []string {
+func forwardPort(ctx context.Context, containerClient *ssh.Client, registry *portRegistry, mergedConfig *config2.MergedDevContainerConfig, port string, exitAfterTimeout time.Duration, log log.Logger) []string {
 	parsed, err := nat.ParsePortSpec(port)
 	if err != nil {
 		log.Debugf("Error parsing appPort %s: %v", port, err)
@@ -225,14 +293,13 @@ func forwardPort(ctx context.Context, containerClient *ssh.Client, port string,
 		if parsedPort.Binding.HostPort == "" {
 			parsedPort.Binding.HostPort = parsedPort.Port.Port()
 		}
-		go func(parsedPort nat.PortMapping) {
-			// do the forward
-			log.Debugf("Forward port %s:%s", parsedPort.Binding.HostIP+":"+parsedPort.Binding.HostPort, "localhost:"+parsedPort.Port.Port())
-			err = devssh.PortForward(ctx, containerClient, "tcp", parsedPort.Binding.HostIP+":"+parsedPort.Binding.HostPort, "tcp", "localhost:"+parsedPort.Port.Port(), exitAfterTimeout, log)
-			if err != nil {
-				log.Errorf("Error port forwarding %s:%s:%s: %v", parsedPort.Binding.HostIP, parsedPort.Binding.HostPort, parsedPort.Port.Port(), err)
-			}
-		}(parsedPort)
+		// do the forward
+		label := config2.GetPortAttribute(mergedConfig.PortsAttributes, mergedConfig.OtherPortsAttributes, parsedPort.Port.Int()).Label
+		log.Debugf("Forward port %s:%s", parsedPort.Binding.HostIP+":"+parsedPort.Binding.HostPort, "localhost:"+parsedPort.Port.Port())
+		err = registry.Forward(ctx, containerClient, parsedPort.Binding.HostIP+":"+parsedPort.Binding.HostPort, "localhost:"+parsedPort.Port.Port(), label, PortOriginConfig, exitAfterTimeout, log)
+		if err != nil {
+			log.Errorf("Error port forwarding %s:%s:%s: %v", parsedPort.Binding.HostIP, parsedPort.Binding.HostPort, parsedPort.Port.Port(), err)
+		}
 
 		forwardedPorts = append(forwardedPorts, parsedPort.Binding.HostPort)
 	}
@@ -83,6 +83,7 @@ func Delete(ctx context.Context, devPodConfig *config.Config, args []string, ign
 	if err != nil {
 		return "", err
 	} else if wasDeleted {
+		deleteWorktree(ctx, workspaceConfig, force, log)
 		return client.Workspace(), nil
 	}
 
@@ -92,6 +93,7 @@ func Delete(ctx context.Context, devPodConfig *config.Config, args []string, ign
 		return "", err
 	}
 
+	deleteWorktree(ctx, workspaceConfig, force, log)
 	return client.Workspace(), nil
 }
 
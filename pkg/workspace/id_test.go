@@ -76,3 +76,34 @@ func TestToID(t *testing.T) {
 		})
 	}
 }
+
+func TestWorktreeToID(t *testing.T) {
+	tests := []struct {
+		name       string
+		repository string
+		branch     string
+		want       string
+	}{
+		{
+			name:       "Simple branch",
+			repository: "/home/user/devpod",
+			branch:     "main",
+			want:       "devpod-main",
+		},
+		{
+			name:       "Branch with /",
+			repository: "/home/user/devpod",
+			branch:     "feat/Feature_1",
+			want:       "devpod-feat-feature1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WorktreeToID(tt.repository, tt.branch)
+			if got != tt.want {
+				t.Errorf("WorktreeToID(%q, %q) = %q, want %q", tt.repository, tt.branch, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/loft-sh/devpod/pkg/config"
+	"github.com/loft-sh/devpod/pkg/file"
+	"github.com/loft-sh/devpod/pkg/git"
+	providerpkg "github.com/loft-sh/devpod/pkg/provider"
+	"github.com/loft-sh/log"
+)
+
+// ResolveWorktree creates or reuses a git worktree of branch for the local repository at repoPath and returns the id
+// and source of its workspace. The id is derived from the repository and the branch, unless desiredID is set. The
+// returned bool reports whether the worktree was created and has to be removed again if the workspace can't be created.
+func ResolveWorktree(ctx context.Context, devPodConfig *config.Config, repoPath, branch, desiredID string, log log.Logger) (string, *providerpkg.WorkspaceSource, bool, error) {
+	isLocalPath, localPath := file.IsLocalDir(repoPath)
+	if !isLocalPath {
+		return "", nil, false, fmt.Errorf("--branch is only supported for local git repositories, use %s@%s for remote ones", repoPath, branch)
+	}
+
+	worktrees, err := git.ListWorktrees(ctx, localPath)
+	if err != nil {
+		return "", nil, false, fmt.Errorf("%s is not a git repository: %w", localPath, err)
+	} else if len(worktrees) == 0 {
+		return "", nil, false, fmt.Errorf("%s is not a git repository", localPath)
+	}
+
+	repository := worktrees[0].Path
+	workspaceID := desiredID
+	if workspaceID == "" {
+		workspaceID = WorktreeToID(repository, branch)
+	}
+	if providerpkg.WorkspaceExists(devPodConfig.DefaultContext, workspaceID) {
+		workspace, err := providerpkg.LoadWorkspaceConfig(devPodConfig.DefaultContext, workspaceID)
+		if err != nil {
+			return "", nil, false, err
+		} else if workspace.Source.GitWorktreeRepository != repository {
+			return "", nil, false, fmt.Errorf("workspace %s already exists for %s, use --id to choose another name", workspaceID, workspace.Source.String())
+		}
+	}
+
+	source := &providerpkg.WorkspaceSource{
+		GitWorktreeRepository: repository,
+	}
+
+	// reuse the worktree that has the branch checked out already
+	for _, worktree := range worktrees {
+		if worktree.Branch == branch {
+			log.Debugf("Use existing worktree %s of branch %s", worktree.Path, branch)
+			source.LocalFolder = worktree.Path
+			return workspaceID, source, false, nil
+		}
+	}
+
+	worktreesDir, err := providerpkg.GetWorktreesDir(devPodConfig.DefaultContext)
+	if err != nil {
+		return "", nil, false, err
+	}
+
+	source.LocalFolder = filepath.Join(worktreesDir, workspaceID)
+	_, err = os.Stat(source.LocalFolder)
+	if err == nil {
+		return "", nil, false, fmt.Errorf("worktree folder %s already exists with another branch", source.LocalFolder)
+	}
+
+	log.Infof("Create worktree of branch %s in %s", branch, source.LocalFolder)
+	err = os.MkdirAll(worktreesDir, 0755)
+	if err != nil {
+		return "", nil, false, err
+	}
+	err = git.AddWorktree(ctx, repository, source.LocalFolder, branch, log)
+	if err != nil {
+		return "", nil, false, err
+	}
+
+	return workspaceID, source, true, nil
+}
+
+// WorktreeToID returns the workspace id of the worktree of branch in repository
+func WorktreeToID(repository, branch string) string {
+	return ToID(filepath.Base(repository) + "-" + strings.ReplaceAll(branch, "/", "-"))
+}
+
+// deleteWorktree removes the worktree of the workspace if DevPod created it
+func deleteWorktree(ctx context.Context, workspace *providerpkg.Workspace, force bool, log log.Logger) {
+	if workspace == nil || workspace.Source.GitWorktreeRepository == "" || workspace.Source.LocalFolder == "" {
+		return
+	}
+
+	worktreesDir, err := providerpkg.GetWorktreesDir(workspace.Context)
+	if err != nil || filepath.Dir(workspace.Source.LocalFolder) != worktreesDir {
+		return
+	}
+
+	err = git.RemoveWorktree(ctx, workspace.Source.GitWorktreeRepository, workspace.Source.LocalFolder, force)
+	if err != nil {
+		log.Warnf("Error removing worktree, run with --force to remove it anyway: %v", err)
+		return
+	}
+
+	log.Infof("Removed worktree %s", workspace.Source.LocalFolder)
+}